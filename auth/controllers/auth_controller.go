@@ -150,10 +150,12 @@ func GetProfile(c *gin.Context) {
 
 	// Devolver solo los campos necesarios, incluyendo el ID
 	c.JSON(http.StatusOK, gin.H{
-		"id":    user.ID,
-		"name":  user.Name,
-		"email": user.Email,
-		"role":  user.Role,
+		"id":               user.ID,
+		"name":             user.Name,
+		"email":            user.Email,
+		"role":             user.Role,
+		"birth_date":       user.BirthDate,
+		"parental_control": user.ParentalControl,
 	})
 }
 
@@ -0,0 +1,51 @@
+package controllers
+
+import (
+	"cine_conecta_backend/auth/services"
+	"cine_conecta_backend/auth/utils"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Obtener la fecha de nacimiento, el país y el control parental del usuario
+func GetContentSettings(c *gin.Context) {
+	claims, _ := c.Get("claims")
+	userClaims := claims.(*utils.Claims)
+
+	settings, err := services.GetContentSettings(userClaims.UserID)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Usuario no encontrado")
+		return
+	}
+
+	c.JSON(http.StatusOK, settings)
+}
+
+// Modificar los ajustes de contenido (con el control parental activo se requiere el PIN)
+func UpdateContentSettings(c *gin.Context) {
+	claims, _ := c.Get("claims")
+	userClaims := claims.(*utils.Claims)
+
+	var input services.ContentSettingsInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Datos inválidos")
+		return
+	}
+
+	settings, err := services.UpdateContentSettings(userClaims.UserID, input)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidParentalPin) {
+			utils.ErrorResponse(c, http.StatusForbidden, "PIN de control parental incorrecto")
+			return
+		}
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Ajustes de contenido actualizados correctamente",
+		"settings": settings,
+	})
+}
@@ -65,3 +65,18 @@ func AdminRequired() gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// OptionalAuth guarda los datos del token JWT si la petición trae uno válido, sin exigirlo.
+// Se usa en las rutas públicas que se adaptan al usuario cuando hay sesión.
+func OptionalAuth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tokenString, err := c.Cookie("cine_token")
+		if err == nil && tokenString != "" {
+			if claims, err := utils.ValidateToken(tokenString); err == nil {
+				c.Set("claims", claims)
+			}
+		}
+
+		c.Next()
+	}
+}
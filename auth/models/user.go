@@ -1,9 +1,19 @@
 package models
 
+import "time"
+
 type User struct {
 	ID       uint   `gorm:"primaryKey" json:"id"`
 	Name     string `json:"name"`
 	Email    string `gorm:"unique" json:"email"`
 	Password string `json:"password"`
 	Role     string `json:"role"`
+
+	// Ajustes para ocultar títulos no aptos: edad del usuario y control parental protegido con PIN
+	BirthDate         *time.Time `json:"birth_date,omitempty"`
+	Country           string     `gorm:"type:varchar(2)" json:"country,omitempty"` // País para elegir la clasificación por edades
+	ParentalControl   bool       `gorm:"default:false" json:"parental_control"`
+	ParentalMaxAge    int        `gorm:"default:0" json:"parental_max_age"`
+	BlockedAdvisories []string   `gorm:"type:jsonb;serializer:json" json:"blocked_advisories,omitempty"`
+	ParentalPin       string     `json:"-"`
 }
@@ -14,6 +14,8 @@ func RegisterAuthRoutes(r *gin.Engine) {
 		api.POST("/login", controllers.Login)
 		api.POST("/logout", middlewares.AuthRequired(), controllers.Logout)
 		api.GET("/profile", middlewares.AuthRequired(), controllers.GetProfile)
+		api.GET("/profile/content-settings", middlewares.AuthRequired(), controllers.GetContentSettings)
+		api.PUT("/profile/content-settings", middlewares.AuthRequired(), controllers.UpdateContentSettings)
 		api.POST("/forgot-password", controllers.ForgotPassword) // Solicitar restablecimiento
 		api.POST("/reset-password", controllers.ResetPassword)   // Restablecer contraseña
 		// Solo accesible para admin
@@ -0,0 +1,167 @@
+package services
+
+import (
+	"cine_conecta_backend/auth/models"
+	"cine_conecta_backend/config"
+	movieModels "cine_conecta_backend/movies/models"
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// ErrInvalidParentalPin indica que falta el PIN del control parental o no es correcto
+var ErrInvalidParentalPin = errors.New("PIN de control parental incorrecto")
+
+// Formato del PIN del control parental y del código de país
+var (
+	parentalPinPattern = regexp.MustCompile(`^\d{4,8}$`)
+	countryPattern     = regexp.MustCompile(`^[A-Z]{2}$`)
+)
+
+// ContentSettingsInput contiene los cambios en los ajustes de contenido. Los campos nulos no se modifican.
+type ContentSettingsInput struct {
+	BirthDate         *string   `json:"birth_date"` // YYYY-MM-DD, cadena vacía para borrarla
+	Country           *string   `json:"country"`
+	ParentalControl   *bool     `json:"parental_control"`
+	ParentalMaxAge    *int      `json:"parental_max_age"`
+	BlockedAdvisories *[]string `json:"blocked_advisories"`
+	Pin               string    `json:"pin"`         // Nuevo PIN al activar el control parental
+	CurrentPin        string    `json:"current_pin"` // Necesario para cualquier cambio con el control parental activo
+}
+
+// ContentSettings son los ajustes de contenido de un usuario tal como se devuelven al cliente
+type ContentSettings struct {
+	BirthDate         *string  `json:"birth_date"`
+	Age               *int     `json:"age"`
+	Country           string   `json:"country"`
+	ParentalControl   bool     `json:"parental_control"`
+	ParentalMaxAge    int      `json:"parental_max_age"`
+	BlockedAdvisories []string `json:"blocked_advisories"`
+}
+
+// GetContentSettings obtiene los ajustes de contenido de un usuario
+func GetContentSettings(userID uint) (*ContentSettings, error) {
+	var user models.User
+	if err := config.DB.First(&user, userID).Error; err != nil {
+		return nil, err
+	}
+	return contentSettingsOf(user), nil
+}
+
+// UpdateContentSettings modifica la fecha de nacimiento, el país y el control parental de un usuario
+func UpdateContentSettings(userID uint, input ContentSettingsInput) (*ContentSettings, error) {
+	var user models.User
+	if err := config.DB.First(&user, userID).Error; err != nil {
+		return nil, err
+	}
+
+	// Con el control parental activo cualquier cambio requiere el PIN actual
+	if user.ParentalControl {
+		if bcrypt.CompareHashAndPassword([]byte(user.ParentalPin), []byte(input.CurrentPin)) != nil {
+			return nil, ErrInvalidParentalPin
+		}
+	}
+
+	if input.BirthDate != nil {
+		if date := strings.TrimSpace(*input.BirthDate); date == "" {
+			user.BirthDate = nil
+		} else {
+			birthDate, err := time.Parse("2006-01-02", date)
+			if err != nil {
+				return nil, errors.New("fecha de nacimiento inválida, use el formato YYYY-MM-DD")
+			}
+			if birthDate.After(time.Now()) {
+				return nil, errors.New("la fecha de nacimiento no puede ser futura")
+			}
+			user.BirthDate = &birthDate
+		}
+	}
+
+	if input.Country != nil {
+		country := strings.ToUpper(strings.TrimSpace(*input.Country))
+		if country != "" && !countryPattern.MatchString(country) {
+			return nil, errors.New("país inválido (use el código de dos letras, por ejemplo CO)")
+		}
+		user.Country = country
+	}
+
+	if input.ParentalMaxAge != nil {
+		if *input.ParentalMaxAge < 0 || *input.ParentalMaxAge > 21 {
+			return nil, errors.New("la edad máxima del control parental debe estar entre 0 y 21")
+		}
+		user.ParentalMaxAge = *input.ParentalMaxAge
+	}
+
+	if input.BlockedAdvisories != nil {
+		blocked := make([]string, 0, len(*input.BlockedAdvisories))
+		for _, tag := range *input.BlockedAdvisories {
+			tag = strings.ToLower(strings.TrimSpace(tag))
+			if !movieModels.IsValidAdvisory(tag) {
+				return nil, errors.New("aviso de contenido no válido: " + tag)
+			}
+			blocked = append(blocked, tag)
+		}
+		user.BlockedAdvisories = blocked
+	}
+
+	if input.ParentalControl != nil {
+		if *input.ParentalControl && !user.ParentalControl {
+			if !parentalPinPattern.MatchString(input.Pin) {
+				return nil, errors.New("para activar el control parental indique un PIN de 4 a 8 dígitos")
+			}
+		}
+		user.ParentalControl = *input.ParentalControl
+	}
+
+	// Un PIN nuevo reemplaza al anterior mientras el control parental siga activo
+	if user.ParentalControl && input.Pin != "" {
+		if !parentalPinPattern.MatchString(input.Pin) {
+			return nil, errors.New("el PIN debe tener de 4 a 8 dígitos")
+		}
+		hashedPin, err := bcrypt.GenerateFromPassword([]byte(input.Pin), 10)
+		if err != nil {
+			return nil, err
+		}
+		user.ParentalPin = string(hashedPin)
+	}
+	if !user.ParentalControl {
+		user.ParentalPin = ""
+	}
+
+	if err := config.DB.Save(&user).Error; err != nil {
+		return nil, err
+	}
+	return contentSettingsOf(user), nil
+}
+
+// UserAge calcula la edad de un usuario a partir de su fecha de nacimiento
+func UserAge(birthDate time.Time, now time.Time) int {
+	age := now.Year() - birthDate.Year()
+	if now.Month() < birthDate.Month() || (now.Month() == birthDate.Month() && now.Day() < birthDate.Day()) {
+		age--
+	}
+	return age
+}
+
+// contentSettingsOf extrae los ajustes de contenido de un usuario
+func contentSettingsOf(user models.User) *ContentSettings {
+	settings := ContentSettings{
+		Country:           user.Country,
+		ParentalControl:   user.ParentalControl,
+		ParentalMaxAge:    user.ParentalMaxAge,
+		BlockedAdvisories: user.BlockedAdvisories,
+	}
+	if settings.BlockedAdvisories == nil {
+		settings.BlockedAdvisories = []string{}
+	}
+	if user.BirthDate != nil {
+		date := user.BirthDate.Format("2006-01-02")
+		age := UserAge(*user.BirthDate, time.Now())
+		settings.BirthDate = &date
+		settings.Age = &age
+	}
+	return &settings
+}
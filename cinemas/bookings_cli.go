@@ -0,0 +1,24 @@
+package cinemas
+
+import (
+	"cine_conecta_backend/cinemas/services"
+	"encoding/json"
+	"fmt"
+)
+
+// SweepPendingBookings libera las reservas pendientes de pago vencidas, reembolsa los cobros que la
+// pasarela haya registrado y muestra el reporte
+func SweepPendingBookings(dryRun bool) error {
+	report, err := services.SweepPendingBookings(dryRun)
+	if err != nil {
+		return err
+	}
+
+	output, _ := json.MarshalIndent(report, "", "  ")
+	fmt.Println(string(output))
+
+	if len(report.Errors) > 0 {
+		return fmt.Errorf("%d reservas no se pudieron liberar", len(report.Errors))
+	}
+	return nil
+}
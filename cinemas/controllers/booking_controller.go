@@ -0,0 +1,295 @@
+package controllers
+
+import (
+	"cine_conecta_backend/auth/utils"
+	"cine_conecta_backend/cinemas/models"
+	"cine_conecta_backend/cinemas/payments"
+	"cine_conecta_backend/cinemas/services"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Estructura para recibir el mapa de butacas de una sala
+type SeatMapInput struct {
+	Rows []services.SeatRowInput `json:"rows"`
+}
+
+// Estructura para recibir las butacas a retener
+type HoldInput struct {
+	SeatIDs []uint `json:"seat_ids"`
+}
+
+// Estructura para recibir los datos de una compra
+type BookingInput struct {
+	ShowtimeID   uint   `json:"showtime_id"`
+	PaymentToken string `json:"payment_token"`
+}
+
+// GetScreenSeats devuelve el mapa de butacas de una sala
+// GET /api/cinemas/:cinemaId/screens/:screenId/seats
+func GetScreenSeats(c *gin.Context) {
+	cinemaID, screenID, ok := parseScreenParams(c)
+	if !ok {
+		return
+	}
+
+	screen, seats, err := services.GetScreenSeats(cinemaID, screenID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.ErrorResponse(c, http.StatusNotFound, "Sala no encontrada")
+			return
+		}
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Error al obtener las butacas")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"screen": screen,
+		"seats":  seats,
+		"count":  len(seats),
+	})
+}
+
+// UpdateScreenSeats reemplaza el mapa de butacas de una sala
+// PUT /api/cinemas/:cinemaId/screens/:screenId/seats (restringido a admin)
+func UpdateScreenSeats(c *gin.Context) {
+	cinemaID, screenID, ok := parseScreenParams(c)
+	if !ok {
+		return
+	}
+
+	var input SeatMapInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Datos inválidos")
+		return
+	}
+
+	screen, seats, err := services.SetScreenSeats(cinemaID, screenID, input.Rows)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.ErrorResponse(c, http.StatusNotFound, "Sala no encontrada")
+			return
+		}
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"screen": screen,
+		"seats":  seats,
+		"count":  len(seats),
+	})
+}
+
+// GetShowtimeSeats devuelve las butacas de una función indicando si están libres, retenidas o vendidas
+// GET /api/showtimes/:showtimeId/seats
+func GetShowtimeSeats(c *gin.Context) {
+	showtimeID, ok := parseShowtimeParam(c)
+	if !ok {
+		return
+	}
+
+	claims, _ := c.Get("claims")
+	userID := claims.(*utils.Claims).UserID
+
+	seats, err := services.GetShowtimeSeats(showtimeID, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.ErrorResponse(c, http.StatusNotFound, "Función no encontrada")
+			return
+		}
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Error al obtener las butacas")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"showtime_id": showtimeID,
+		"seats":       seats,
+	})
+}
+
+// HoldSeats retiene butacas de una función durante unos minutos para el usuario
+// POST /api/showtimes/:showtimeId/holds
+func HoldSeats(c *gin.Context) {
+	showtimeID, ok := parseShowtimeParam(c)
+	if !ok {
+		return
+	}
+
+	var input HoldInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Datos inválidos")
+		return
+	}
+
+	claims, _ := c.Get("claims")
+	userID := claims.(*utils.Claims).UserID
+
+	holds, err := services.HoldSeats(showtimeID, userID, input.SeatIDs)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.ErrorResponse(c, http.StatusNotFound, "Función no encontrada")
+			return
+		}
+		if err.Error() == "alguna de las butacas ya no está disponible" {
+			utils.ErrorResponse(c, http.StatusConflict, "Alguna de las butacas ya no está disponible")
+			return
+		}
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"holds":      holds,
+		"expires_at": holds[0].ExpiresAt,
+	})
+}
+
+// ReleaseHolds libera las butacas retenidas por el usuario en una función
+// DELETE /api/showtimes/:showtimeId/holds
+func ReleaseHolds(c *gin.Context) {
+	showtimeID, ok := parseShowtimeParam(c)
+	if !ok {
+		return
+	}
+
+	claims, _ := c.Get("claims")
+	userID := claims.(*utils.Claims).UserID
+
+	if err := services.ReleaseHolds(showtimeID, userID); err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "No se pudieron liberar las butacas")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Butacas liberadas correctamente"})
+}
+
+// CreateBooking confirma la compra de las butacas retenidas y realiza el cobro
+// POST /api/bookings
+func CreateBooking(c *gin.Context) {
+	var input BookingInput
+	if err := c.ShouldBindJSON(&input); err != nil || input.ShowtimeID == 0 {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Datos inválidos")
+		return
+	}
+
+	claims, _ := c.Get("claims")
+	userID := claims.(*utils.Claims).UserID
+
+	booking, err := services.CreateBooking(userID, input.ShowtimeID, input.PaymentToken)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.ErrorResponse(c, http.StatusNotFound, "Función no encontrada")
+			return
+		}
+		if errors.Is(err, payments.ErrPaymentDeclined) {
+			utils.ErrorResponse(c, http.StatusPaymentRequired, "El pago fue rechazado")
+			return
+		}
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusCreated, booking)
+}
+
+// GetMyBookings devuelve las reservas del usuario autenticado
+// GET /api/bookings
+func GetMyBookings(c *gin.Context) {
+	claims, _ := c.Get("claims")
+	userID := claims.(*utils.Claims).UserID
+
+	bookings, err := services.GetUserBookings(userID)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Error al obtener las reservas")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"bookings": bookings,
+		"count":    len(bookings),
+	})
+}
+
+// GetBooking devuelve una reserva del usuario (o cualquiera si es admin)
+// GET /api/bookings/:bookingId
+func GetBooking(c *gin.Context) {
+	bookingID, err := strconv.ParseUint(c.Param("bookingId"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "ID de reserva inválido")
+		return
+	}
+
+	claims, _ := c.Get("claims")
+	userClaims := claims.(*utils.Claims)
+
+	booking, err := services.GetBookingByID(uint(bookingID))
+	if err != nil || (booking.UserID != userClaims.UserID && userClaims.Role != "admin") {
+		utils.ErrorResponse(c, http.StatusNotFound, "Reserva no encontrada")
+		return
+	}
+
+	c.JSON(http.StatusOK, booking)
+}
+
+// CancelBooking cancela una reserva y reembolsa el pago
+// POST /api/bookings/:bookingId/cancel
+func CancelBooking(c *gin.Context) {
+	bookingID, err := strconv.ParseUint(c.Param("bookingId"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "ID de reserva inválido")
+		return
+	}
+
+	claims, _ := c.Get("claims")
+	userClaims := claims.(*utils.Claims)
+
+	booking, err := services.CancelBooking(uint(bookingID), userClaims.UserID, userClaims.Role == "admin")
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.ErrorResponse(c, http.StatusNotFound, "Reserva no encontrada")
+			return
+		}
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	message := "Reserva cancelada correctamente"
+	if booking.Status == models.BookingRefundPending {
+		message = "Reserva cancelada; el reembolso está pendiente y se volverá a intentar"
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": message,
+		"booking": booking,
+	})
+}
+
+// RetryPendingRefunds vuelve a intentar los reembolsos de las reservas canceladas que fallaron
+// POST /api/bookings/refunds/retry (restringido a admin)
+func RetryPendingRefunds(c *gin.Context) {
+	refunded, failed, err := services.RetryPendingRefunds()
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Error al reintentar los reembolsos: "+err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"refunded": refunded,
+		"failed":   failed,
+	})
+}
+
+// parseShowtimeParam obtiene el identificador de la función de la ruta
+func parseShowtimeParam(c *gin.Context) (uint, bool) {
+	showtimeID, err := strconv.ParseUint(c.Param("showtimeId"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "ID de función inválido")
+		return 0, false
+	}
+	return uint(showtimeID), true
+}
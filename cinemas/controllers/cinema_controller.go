@@ -0,0 +1,281 @@
+package controllers
+
+import (
+	"cine_conecta_backend/auth/utils"
+	"cine_conecta_backend/cinemas/models"
+	"cine_conecta_backend/cinemas/services"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Estructura para recibir los datos de creación/actualización de cines
+type CinemaInput struct {
+	Name     string `json:"name"`
+	City     string `json:"city"`
+	Address  string `json:"address"`
+	Phone    string `json:"phone"`
+	Timezone string `json:"timezone"`
+}
+
+// Estructura para recibir los datos de creación/actualización de salas
+type ScreenInput struct {
+	Name     string `json:"name"`
+	Capacity int    `json:"capacity"`
+}
+
+// GetCinemas lista los cines con sus salas
+// GET /api/cinemas?city=
+func GetCinemas(c *gin.Context) {
+	cinemas, err := services.GetCinemas(c.Query("city"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Error al obtener cines")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"cinemas": cinemas,
+		"count":   len(cinemas),
+	})
+}
+
+// GetCinema devuelve un cine con sus salas
+// GET /api/cinemas/:cinemaId
+func GetCinema(c *gin.Context) {
+	cinemaID, err := strconv.ParseUint(c.Param("cinemaId"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "ID de cine inválido")
+		return
+	}
+
+	cinema, err := services.GetCinemaByID(uint(cinemaID))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Cine no encontrado")
+		return
+	}
+
+	c.JSON(http.StatusOK, cinema)
+}
+
+// GetCinemaSchedule devuelve la cartelera de un cine agrupada por día y película
+// GET /api/cinemas/:cinemaId/showtimes?from=YYYY-MM-DD&days=7
+func GetCinemaSchedule(c *gin.Context) {
+	cinemaID, err := strconv.ParseUint(c.Param("cinemaId"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "ID de cine inválido")
+		return
+	}
+
+	days, _ := strconv.Atoi(c.DefaultQuery("days", "7"))
+	cinema, schedule, err := services.GetCinemaSchedule(uint(cinemaID), c.Query("from"), days)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.ErrorResponse(c, http.StatusNotFound, "Cine no encontrado")
+			return
+		}
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"cinema":   cinema,
+		"schedule": schedule,
+	})
+}
+
+// CreateCinema registra un nuevo cine
+// POST /api/cinemas (restringido a admin)
+func CreateCinema(c *gin.Context) {
+	var input CinemaInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Datos inválidos")
+		return
+	}
+
+	cinema := models.Cinema{
+		Name:     input.Name,
+		City:     input.City,
+		Address:  input.Address,
+		Phone:    input.Phone,
+		Timezone: input.Timezone,
+	}
+	if err := services.CreateCinema(&cinema); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusCreated, cinema)
+}
+
+// UpdateCinema actualiza los datos de un cine
+// PUT /api/cinemas/:cinemaId (restringido a admin)
+func UpdateCinema(c *gin.Context) {
+	cinemaID, err := strconv.ParseUint(c.Param("cinemaId"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "ID de cine inválido")
+		return
+	}
+
+	cinema, err := services.GetCinemaByID(uint(cinemaID))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Cine no encontrado")
+		return
+	}
+
+	var input CinemaInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Datos inválidos")
+		return
+	}
+
+	if input.Name != "" {
+		cinema.Name = input.Name
+	}
+	if input.City != "" {
+		cinema.City = input.City
+	}
+	if input.Address != "" {
+		cinema.Address = input.Address
+	}
+	if input.Phone != "" {
+		cinema.Phone = input.Phone
+	}
+	if input.Timezone != "" {
+		cinema.Timezone = input.Timezone
+	}
+
+	if err := services.UpdateCinema(&cinema); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, cinema)
+}
+
+// DeleteCinema elimina un cine con sus salas, butacas y funciones
+// DELETE /api/cinemas/:cinemaId (restringido a admin)
+func DeleteCinema(c *gin.Context) {
+	cinemaID, err := strconv.ParseUint(c.Param("cinemaId"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "ID de cine inválido")
+		return
+	}
+
+	if err := services.DeleteCinema(uint(cinemaID)); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.ErrorResponse(c, http.StatusNotFound, "Cine no encontrado")
+			return
+		}
+		if errors.Is(err, services.ErrHasBookings) {
+			utils.ErrorResponse(c, http.StatusConflict, "El cine tiene funciones con entradas vendidas")
+			return
+		}
+		utils.ErrorResponse(c, http.StatusInternalServerError, "No se pudo eliminar el cine")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Cine eliminado correctamente"})
+}
+
+// CreateScreen añade una sala a un cine
+// POST /api/cinemas/:cinemaId/screens (restringido a admin)
+func CreateScreen(c *gin.Context) {
+	cinemaID, err := strconv.ParseUint(c.Param("cinemaId"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "ID de cine inválido")
+		return
+	}
+
+	var input ScreenInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Datos inválidos")
+		return
+	}
+
+	screen := models.Screen{
+		CinemaID: uint(cinemaID),
+		Name:     input.Name,
+		Capacity: input.Capacity,
+	}
+	if err := services.CreateScreen(&screen); err != nil {
+		if err.Error() == "cine no encontrado" {
+			utils.ErrorResponse(c, http.StatusNotFound, "Cine no encontrado")
+			return
+		}
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusCreated, screen)
+}
+
+// UpdateScreen actualiza una sala
+// PUT /api/cinemas/:cinemaId/screens/:screenId (restringido a admin)
+func UpdateScreen(c *gin.Context) {
+	cinemaID, screenID, ok := parseScreenParams(c)
+	if !ok {
+		return
+	}
+
+	var input ScreenInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Datos inválidos")
+		return
+	}
+
+	screen, err := services.UpdateScreen(cinemaID, screenID, input.Name, input.Capacity)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.ErrorResponse(c, http.StatusNotFound, "Sala no encontrada")
+			return
+		}
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, screen)
+}
+
+// DeleteScreen elimina una sala con sus butacas y funciones
+// DELETE /api/cinemas/:cinemaId/screens/:screenId (restringido a admin)
+func DeleteScreen(c *gin.Context) {
+	cinemaID, screenID, ok := parseScreenParams(c)
+	if !ok {
+		return
+	}
+
+	if err := services.DeleteScreen(cinemaID, screenID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.ErrorResponse(c, http.StatusNotFound, "Sala no encontrada")
+			return
+		}
+		if errors.Is(err, services.ErrHasBookings) {
+			utils.ErrorResponse(c, http.StatusConflict, "La sala tiene funciones con entradas vendidas")
+			return
+		}
+		utils.ErrorResponse(c, http.StatusInternalServerError, "No se pudo eliminar la sala")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Sala eliminada correctamente"})
+}
+
+// parseScreenParams obtiene los identificadores de cine y sala de la ruta
+func parseScreenParams(c *gin.Context) (uint, uint, bool) {
+	cinemaID, err := strconv.ParseUint(c.Param("cinemaId"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "ID de cine inválido")
+		return 0, 0, false
+	}
+
+	screenID, err := strconv.ParseUint(c.Param("screenId"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "ID de sala inválido")
+		return 0, 0, false
+	}
+
+	return uint(cinemaID), uint(screenID), true
+}
@@ -0,0 +1,231 @@
+package controllers
+
+import (
+	"cine_conecta_backend/auth/utils"
+	"cine_conecta_backend/cinemas/models"
+	"cine_conecta_backend/cinemas/services"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Estructura para recibir los datos de creación/actualización de funciones.
+// Las horas se envían en formato RFC3339 (por ejemplo 2025-06-01T19:30:00-05:00).
+type ShowtimeInput struct {
+	MovieID   uint      `json:"movie_id"`
+	ScreenID  uint      `json:"screen_id"`
+	StartsAt  time.Time `json:"starts_at"`
+	EndsAt    time.Time `json:"ends_at"` // Opcional: se calcula con la duración de la película
+	Language  string    `json:"language"`
+	Subtitles string    `json:"subtitles"`
+	Format    string    `json:"format"`
+	Price     *float64  `json:"price"`
+	Currency  string    `json:"currency"`
+}
+
+// GetShowtimes busca funciones por película, cine, ciudad y día
+// GET /api/showtimes?movie_id=&cinema_id=&city=&date=YYYY-MM-DD
+func GetShowtimes(c *gin.Context) {
+	var filter services.ShowtimeFilter
+	if movieID, err := strconv.ParseUint(c.Query("movie_id"), 10, 32); err == nil {
+		filter.MovieID = uint(movieID)
+	}
+	if cinemaID, err := strconv.ParseUint(c.Query("cinema_id"), 10, 32); err == nil {
+		filter.CinemaID = uint(cinemaID)
+	}
+	filter.City = c.Query("city")
+
+	if date := c.Query("date"); date != "" {
+		if _, err := time.Parse("2006-01-02", date); err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Fecha inválida, use el formato YYYY-MM-DD")
+			return
+		}
+		filter.Date = date
+	} else {
+		// Sin fecha solo se muestran las funciones futuras
+		filter.From = time.Now()
+	}
+
+	showtimes, err := services.FindShowtimes(filter)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Error al obtener funciones")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"showtimes": showtimes,
+		"count":     len(showtimes),
+	})
+}
+
+// GetMovieShowtimes devuelve las funciones de una película agrupadas por cine
+// GET /api/movies/:movieId/showtimes?city=&date=YYYY-MM-DD
+func GetMovieShowtimes(c *gin.Context) {
+	movieID, err := strconv.ParseUint(c.Param("movieId"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "ID de película inválido")
+		return
+	}
+
+	date := c.Query("date")
+	if date != "" {
+		if _, err := time.Parse("2006-01-02", date); err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Fecha inválida, use el formato YYYY-MM-DD")
+			return
+		}
+	}
+
+	cinemas, err := services.GetMovieShowtimesByCinema(uint(movieID), c.Query("city"), date)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Error al obtener funciones")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"movie_id": movieID,
+		"city":     c.Query("city"),
+		"date":     date,
+		"cinemas":  cinemas,
+	})
+}
+
+// GetShowtime devuelve una función con su película, sala y cine
+// GET /api/showtimes/:showtimeId
+func GetShowtime(c *gin.Context) {
+	showtimeID, err := strconv.ParseUint(c.Param("showtimeId"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "ID de función inválido")
+		return
+	}
+
+	showtime, err := services.GetShowtimeByID(uint(showtimeID))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Función no encontrada")
+		return
+	}
+
+	c.JSON(http.StatusOK, showtime)
+}
+
+// CreateShowtime programa una nueva función
+// POST /api/showtimes (restringido a admin)
+func CreateShowtime(c *gin.Context) {
+	var input ShowtimeInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Datos inválidos")
+		return
+	}
+	if input.Price == nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "El precio es obligatorio")
+		return
+	}
+
+	showtime := models.Showtime{
+		MovieID:   input.MovieID,
+		ScreenID:  input.ScreenID,
+		StartsAt:  input.StartsAt,
+		EndsAt:    input.EndsAt,
+		Language:  input.Language,
+		Subtitles: input.Subtitles,
+		Format:    models.ShowtimeFormat(input.Format),
+		Price:     *input.Price,
+		Currency:  input.Currency,
+	}
+	if err := services.CreateShowtime(&showtime); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusCreated, showtime)
+}
+
+// UpdateShowtime modifica una función existente
+// PUT /api/showtimes/:showtimeId (restringido a admin)
+func UpdateShowtime(c *gin.Context) {
+	showtimeID, err := strconv.ParseUint(c.Param("showtimeId"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "ID de función inválido")
+		return
+	}
+
+	showtime, err := services.GetShowtimeByID(uint(showtimeID))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Función no encontrada")
+		return
+	}
+
+	var input ShowtimeInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Datos inválidos")
+		return
+	}
+
+	if input.MovieID != 0 {
+		showtime.MovieID = input.MovieID
+	}
+	if input.ScreenID != 0 {
+		showtime.ScreenID = input.ScreenID
+	}
+	if !input.StartsAt.IsZero() {
+		// Recalcular el fin si cambia el inicio y no se indica uno nuevo
+		showtime.StartsAt = input.StartsAt
+		showtime.EndsAt = input.EndsAt
+	} else if !input.EndsAt.IsZero() {
+		showtime.EndsAt = input.EndsAt
+	}
+	if input.Language != "" {
+		showtime.Language = input.Language
+	}
+	if input.Subtitles != "" {
+		showtime.Subtitles = input.Subtitles
+	}
+	if input.Format != "" {
+		showtime.Format = models.ShowtimeFormat(input.Format)
+	}
+	if input.Price != nil {
+		showtime.Price = *input.Price
+	}
+	if input.Currency != "" {
+		showtime.Currency = input.Currency
+	}
+
+	if err := services.UpdateShowtime(&showtime); err != nil {
+		if errors.Is(err, services.ErrHasBookings) {
+			utils.ErrorResponse(c, http.StatusConflict, "La función tiene butacas retenidas o vendidas; no se puede cambiar la sala, la película ni el horario")
+			return
+		}
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, showtime)
+}
+
+// DeleteShowtime elimina una función
+// DELETE /api/showtimes/:showtimeId (restringido a admin)
+func DeleteShowtime(c *gin.Context) {
+	showtimeID, err := strconv.ParseUint(c.Param("showtimeId"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "ID de función inválido")
+		return
+	}
+
+	if err := services.DeleteShowtime(uint(showtimeID)); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.ErrorResponse(c, http.StatusNotFound, "Función no encontrada")
+			return
+		}
+		if errors.Is(err, services.ErrHasBookings) {
+			utils.ErrorResponse(c, http.StatusConflict, "La función tiene entradas vendidas")
+			return
+		}
+		utils.ErrorResponse(c, http.StatusInternalServerError, "No se pudo eliminar la función")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Función eliminada correctamente"})
+}
@@ -0,0 +1,105 @@
+package models
+
+import (
+	"strconv"
+	"time"
+)
+
+// SeatType representa el tipo de butaca
+type SeatType string
+
+const (
+	SeatStandard   SeatType = "standard"
+	SeatVIP        SeatType = "vip"
+	SeatWheelchair SeatType = "wheelchair"
+)
+
+// IsValidSeatType verifica si un tipo de butaca está entre los permitidos
+func IsValidSeatType(seatType SeatType) bool {
+	switch seatType {
+	case SeatStandard, SeatVIP, SeatWheelchair:
+		return true
+	default:
+		return false
+	}
+}
+
+// Seat representa una butaca del mapa de una sala
+type Seat struct {
+	ID       uint     `gorm:"primaryKey" json:"id"`
+	ScreenID uint     `gorm:"not null;index" json:"screen_id"`
+	Row      string   `gorm:"column:seat_row;type:varchar(5);not null" json:"row"`
+	Number   int      `gorm:"not null" json:"number"`
+	Type     SeatType `gorm:"type:varchar(20);not null;default:'standard'" json:"type"`
+}
+
+// Label devuelve la etiqueta visible de la butaca (por ejemplo "F12")
+func (s Seat) Label() string {
+	return s.Row + strconv.Itoa(s.Number)
+}
+
+// Estados de una butaca en una función
+const (
+	ReservationHeld      = "held"      // Retenida temporalmente antes de pagar
+	ReservationPaying    = "paying"    // Pendiente del cobro de la compra
+	ReservationBooked    = "booked"    // Vendida en una reserva confirmada
+	ReservationCancelled = "cancelled" // Liberada al cancelar la reserva
+)
+
+// SeatReservation representa la retención o venta de una butaca para una función
+type SeatReservation struct {
+	ID         uint       `gorm:"primaryKey" json:"id"`
+	ShowtimeID uint       `gorm:"not null;index" json:"showtime_id"`
+	SeatID     uint       `gorm:"not null" json:"seat_id"`
+	UserID     uint       `gorm:"not null;index" json:"user_id"`
+	BookingID  *uint      `gorm:"index" json:"booking_id,omitempty"`
+	Status     string     `gorm:"type:varchar(20);not null" json:"status"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"` // Solo para retenciones y butacas en pago
+
+	Seat *Seat `gorm:"foreignKey:SeatID" json:"seat,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Estados de una reserva
+const (
+	BookingPending       = "pending"        // Butacas apartadas mientras se realiza el cobro
+	BookingConfirmed     = "confirmed"      // Pagada
+	BookingRefundPending = "refund_pending" // Cancelada con el reembolso pendiente o fallido
+	BookingCancelled     = "cancelled"      // Cancelada y reembolsada
+)
+
+// Booking representa una compra de entradas para una función
+type Booking struct {
+	ID         uint   `gorm:"primaryKey" json:"id"`
+	UserID     uint   `gorm:"not null;index" json:"user_id"`
+	ShowtimeID uint   `gorm:"not null;index" json:"showtime_id"`
+	Status     string `gorm:"type:varchar(20);not null" json:"status"`
+
+	// Código de la entrada y contenido firmado para generar el QR
+	TicketCode string `gorm:"type:varchar(20)" json:"ticket_code"`
+	QRPayload  string `json:"qr_payload"`
+
+	SeatCount int     `json:"seat_count"`
+	Total     float64 `gorm:"type:numeric(10,2);not null" json:"total"`
+	Currency  string  `gorm:"type:varchar(3);not null" json:"currency"`
+
+	PaymentProvider  string `json:"payment_provider"`
+	PaymentReference string `json:"payment_reference"`
+
+	// Límite para completar el cobro; después el barrido libera la reserva pendiente
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+
+	// Resultado del reembolso al cancelar
+	RefundedAt        *time.Time `json:"refunded_at,omitempty"`
+	RefundError       string     `json:"refund_error,omitempty"`
+	RefundAttemptedAt *time.Time `json:"-"`
+
+	Seats    []SeatReservation `gorm:"foreignKey:BookingID" json:"seats,omitempty"`
+	Showtime *Showtime         `gorm:"foreignKey:ShowtimeID" json:"showtime,omitempty"`
+
+	CancelledAt *time.Time `json:"cancelled_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
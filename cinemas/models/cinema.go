@@ -0,0 +1,36 @@
+package models
+
+import "time"
+
+// Zona horaria por defecto de los cines
+const DefaultTimezone = "America/Bogota"
+
+// Cinema representa un cine con una o varias salas
+type Cinema struct {
+	ID       uint   `gorm:"primaryKey" json:"id"`
+	Name     string `gorm:"not null" json:"name"`
+	City     string `gorm:"not null;index" json:"city"`
+	Address  string `json:"address"`
+	Phone    string `json:"phone,omitempty"`
+	Timezone string `gorm:"not null;default:'America/Bogota'" json:"timezone"`
+
+	// Salas del cine
+	Screens []Screen `gorm:"foreignKey:CinemaID" json:"screens,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Screen representa una sala de un cine
+type Screen struct {
+	ID       uint   `gorm:"primaryKey" json:"id"`
+	CinemaID uint   `gorm:"not null;index" json:"cinema_id"`
+	Name     string `gorm:"not null" json:"name"`
+	Capacity int    `json:"capacity"`
+
+	// Cine al que pertenece la sala
+	Cinema *Cinema `gorm:"foreignKey:CinemaID" json:"cinema,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -0,0 +1,48 @@
+package models
+
+import (
+	movieModels "cine_conecta_backend/movies/models"
+	"time"
+)
+
+// ShowtimeFormat representa el formato de proyección de una función
+type ShowtimeFormat string
+
+const (
+	Format2D   ShowtimeFormat = "2D"
+	Format3D   ShowtimeFormat = "3D"
+	FormatIMAX ShowtimeFormat = "IMAX"
+	Format4DX  ShowtimeFormat = "4DX"
+)
+
+// IsValidShowtimeFormat verifica si un formato está entre los permitidos
+func IsValidShowtimeFormat(format ShowtimeFormat) bool {
+	switch format {
+	case Format2D, Format3D, FormatIMAX, Format4DX:
+		return true
+	default:
+		return false
+	}
+}
+
+// Showtime representa una función de una película en una sala
+type Showtime struct {
+	ID       uint      `gorm:"primaryKey" json:"id"`
+	MovieID  uint      `gorm:"not null;index" json:"movie_id"`
+	ScreenID uint      `gorm:"not null;index" json:"screen_id"`
+	StartsAt time.Time `gorm:"not null;index" json:"starts_at"`
+	EndsAt   time.Time `gorm:"not null" json:"ends_at"`
+
+	// Idioma del audio (código ISO, por ejemplo "es" o "en") y subtítulos opcionales
+	Language  string         `gorm:"type:varchar(10);not null" json:"language"`
+	Subtitles string         `gorm:"type:varchar(10)" json:"subtitles,omitempty"`
+	Format    ShowtimeFormat `gorm:"type:varchar(10);not null;default:'2D'" json:"format"`
+	Price     float64        `gorm:"type:numeric(10,2);not null" json:"price"`
+	Currency  string         `gorm:"type:varchar(3);not null;default:'COP'" json:"currency"`
+
+	Movie  *movieModels.Movie `gorm:"foreignKey:MovieID" json:"movie,omitempty"`
+	Screen *Screen            `gorm:"foreignKey:ScreenID" json:"screen,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
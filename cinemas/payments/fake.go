@@ -0,0 +1,107 @@
+package payments
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"sync"
+)
+
+// Tokens especiales reconocidos por el proveedor de pruebas
+const (
+	FakeTokenDeclined = "tok_declined" // El cobro se rechaza
+)
+
+// FakeProvider simula una pasarela de pagos en memoria para desarrollo y pruebas.
+// Acepta cualquier token salvo FakeTokenDeclined.
+type FakeProvider struct {
+	mu          sync.Mutex
+	charges     map[string]float64
+	references  map[string]string // Referencia interna de la compra -> referencia del cobro
+	failRefunds bool
+}
+
+// NewFakeProvider crea un proveedor de pagos de pruebas
+func NewFakeProvider() *FakeProvider {
+	return &FakeProvider{charges: make(map[string]float64), references: make(map[string]string)}
+}
+
+// Name devuelve el nombre del proveedor
+func (p *FakeProvider) Name() string {
+	return "fake"
+}
+
+// Charge registra el cobro y devuelve una referencia aleatoria
+func (p *FakeProvider) Charge(ctx context.Context, req ChargeRequest) (*ChargeResult, error) {
+	if req.Token == FakeTokenDeclined {
+		return nil, ErrPaymentDeclined
+	}
+	if req.Amount < 0 {
+		return nil, errors.New("importe inválido")
+	}
+
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, err
+	}
+	reference := "fake_" + hex.EncodeToString(buf)
+
+	p.mu.Lock()
+	p.charges[reference] = req.Amount
+	if req.Reference != "" {
+		p.references[req.Reference] = reference
+	}
+	p.mu.Unlock()
+
+	return &ChargeResult{Reference: reference, Status: "succeeded"}, nil
+}
+
+// Refund devuelve un cobro. Las referencias desconocidas (por ejemplo tras un
+// reinicio del servidor) se aceptan, ya que no hay dinero real de por medio.
+func (p *FakeProvider) Refund(ctx context.Context, reference string, amount float64) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.failRefunds {
+		return errors.New("la pasarela no está disponible")
+	}
+
+	if charged, ok := p.charges[reference]; ok && amount > charged {
+		return errors.New("el reembolso supera el importe cobrado")
+	}
+	delete(p.charges, reference)
+	return nil
+}
+
+// FindCharge busca el cobro no reembolsado hecho con una referencia interna
+func (p *FakeProvider) FindCharge(ctx context.Context, reference string) (*ChargeResult, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	charge, ok := p.references[reference]
+	if !ok {
+		return nil, ErrChargeNotFound
+	}
+	if _, ok := p.charges[charge]; !ok {
+		return nil, ErrChargeNotFound
+	}
+	return &ChargeResult{Reference: charge, Status: "succeeded"}, nil
+}
+
+// Charged indica si un cobro sigue registrado (no reembolsado) y su importe
+func (p *FakeProvider) Charged(reference string) (float64, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	amount, ok := p.charges[reference]
+	return amount, ok
+}
+
+// FailRefunds hace que los reembolsos fallen, para simular una caída de la pasarela
+func (p *FakeProvider) FailRefunds(fail bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.failRefunds = fail
+}
@@ -0,0 +1,116 @@
+package payments
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestFakeProviderCharge(t *testing.T) {
+	provider := NewFakeProvider()
+
+	result, err := provider.Charge(context.Background(), ChargeRequest{Amount: 24000, Currency: "COP", Token: "tok_visa"})
+	if err != nil {
+		t.Fatalf("Charge: %v", err)
+	}
+	if result.Reference == "" || result.Status != "succeeded" {
+		t.Fatalf("resultado inesperado: %+v", result)
+	}
+	if amount, ok := provider.Charged(result.Reference); !ok || amount != 24000 {
+		t.Fatalf("cobro registrado = %v, %v; se esperaba 24000", amount, ok)
+	}
+}
+
+func TestFakeProviderChargeDeclined(t *testing.T) {
+	provider := NewFakeProvider()
+
+	_, err := provider.Charge(context.Background(), ChargeRequest{Amount: 12000, Token: FakeTokenDeclined})
+	if !errors.Is(err, ErrPaymentDeclined) {
+		t.Fatalf("err = %v; se esperaba ErrPaymentDeclined", err)
+	}
+}
+
+func TestFakeProviderChargeInvalidAmount(t *testing.T) {
+	provider := NewFakeProvider()
+
+	if _, err := provider.Charge(context.Background(), ChargeRequest{Amount: -1, Token: "tok_visa"}); err == nil {
+		t.Fatal("se esperaba un error con un importe negativo")
+	}
+}
+
+func TestFakeProviderRefund(t *testing.T) {
+	provider := NewFakeProvider()
+	result, err := provider.Charge(context.Background(), ChargeRequest{Amount: 10000, Token: "tok_visa"})
+	if err != nil {
+		t.Fatalf("Charge: %v", err)
+	}
+
+	if err := provider.Refund(context.Background(), result.Reference, 20000); err == nil {
+		t.Fatal("se esperaba un error al reembolsar más de lo cobrado")
+	}
+	if err := provider.Refund(context.Background(), result.Reference, 10000); err != nil {
+		t.Fatalf("Refund: %v", err)
+	}
+	if _, ok := provider.Charged(result.Reference); ok {
+		t.Fatal("el cobro sigue registrado tras el reembolso")
+	}
+}
+
+func TestFakeProviderFailRefunds(t *testing.T) {
+	provider := NewFakeProvider()
+	result, err := provider.Charge(context.Background(), ChargeRequest{Amount: 10000, Token: "tok_visa"})
+	if err != nil {
+		t.Fatalf("Charge: %v", err)
+	}
+
+	provider.FailRefunds(true)
+	if err := provider.Refund(context.Background(), result.Reference, 10000); err == nil {
+		t.Fatal("se esperaba un error con los reembolsos desactivados")
+	}
+	if _, ok := provider.Charged(result.Reference); !ok {
+		t.Fatal("un reembolso fallido no debe eliminar el cobro")
+	}
+
+	provider.FailRefunds(false)
+	if err := provider.Refund(context.Background(), result.Reference, 10000); err != nil {
+		t.Fatalf("Refund: %v", err)
+	}
+}
+
+func TestFakeProviderFindCharge(t *testing.T) {
+	provider := NewFakeProvider()
+	ctx := context.Background()
+
+	if _, err := provider.FindCharge(ctx, "booking-1"); !errors.Is(err, ErrChargeNotFound) {
+		t.Fatalf("err = %v; se esperaba ErrChargeNotFound", err)
+	}
+
+	result, err := provider.Charge(ctx, ChargeRequest{Amount: 10000, Reference: "booking-1", Token: "tok_visa"})
+	if err != nil {
+		t.Fatalf("Charge: %v", err)
+	}
+	found, err := provider.FindCharge(ctx, "booking-1")
+	if err != nil || found.Reference != result.Reference {
+		t.Fatalf("FindCharge = %+v, %v; se esperaba %s", found, err, result.Reference)
+	}
+
+	if err := provider.Refund(ctx, result.Reference, 10000); err != nil {
+		t.Fatalf("Refund: %v", err)
+	}
+	if _, err := provider.FindCharge(ctx, "booking-1"); !errors.Is(err, ErrChargeNotFound) {
+		t.Fatalf("err = %v; un cobro reembolsado no debe encontrarse", err)
+	}
+}
+
+func TestNewProviderFromEnv(t *testing.T) {
+	t.Setenv("PAYMENT_PROVIDER", "")
+	provider, err := NewProviderFromEnv()
+	if err != nil || provider.Name() != "fake" {
+		t.Fatalf("NewProviderFromEnv() = %v, %v; se esperaba el proveedor fake", provider, err)
+	}
+
+	t.Setenv("PAYMENT_PROVIDER", "desconocido")
+	if _, err := NewProviderFromEnv(); err == nil {
+		t.Fatal("se esperaba un error con un proveedor desconocido")
+	}
+}
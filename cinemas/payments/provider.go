@@ -0,0 +1,58 @@
+package payments
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ErrPaymentDeclined indica que el proveedor rechazó el cobro
+var ErrPaymentDeclined = errors.New("el pago fue rechazado")
+
+// ErrChargeNotFound indica que no hay ningún cobro vigente con la referencia indicada
+var ErrChargeNotFound = errors.New("cobro no encontrado")
+
+// ChargeRequest contiene los datos de un cobro
+type ChargeRequest struct {
+	Amount    float64
+	Currency  string
+	Reference string // Referencia interna de la compra
+	Token     string // Token del medio de pago generado en el cliente
+}
+
+// ChargeResult contiene la respuesta del proveedor a un cobro
+type ChargeResult struct {
+	Reference string // Identificador del cobro en el proveedor
+	Status    string
+}
+
+// Provider es la interfaz que deben implementar las pasarelas de pago
+type Provider interface {
+	// Name devuelve el nombre del proveedor (por ejemplo "fake")
+	Name() string
+	// Charge realiza un cobro o devuelve ErrPaymentDeclined
+	Charge(ctx context.Context, req ChargeRequest) (*ChargeResult, error)
+	// Refund devuelve el importe de un cobro anterior
+	Refund(ctx context.Context, reference string, amount float64) error
+	// FindCharge busca un cobro no reembolsado por la referencia interna de la compra
+	// (ChargeRequest.Reference) o devuelve ErrChargeNotFound
+	FindCharge(ctx context.Context, reference string) (*ChargeResult, error)
+}
+
+// NewProviderFromEnv crea el proveedor configurado en PAYMENT_PROVIDER.
+// Si no se indica, se usa el proveedor local de pruebas.
+func NewProviderFromEnv() (Provider, error) {
+	name := strings.ToLower(strings.TrimSpace(os.Getenv("PAYMENT_PROVIDER")))
+	if name == "" {
+		name = "fake"
+	}
+
+	switch name {
+	case "fake":
+		return NewFakeProvider(), nil
+	default:
+		return nil, fmt.Errorf("proveedor de pagos desconocido: %s", name)
+	}
+}
@@ -0,0 +1,60 @@
+package routes
+
+import (
+	"cine_conecta_backend/auth/middlewares"
+	"cine_conecta_backend/cinemas/controllers"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterCinemaRoutes(r *gin.Engine) {
+	cinemas := r.Group("/api/cinemas")
+	{
+		// Consultas públicas de cines y cartelera
+		cinemas.GET("/", controllers.GetCinemas)
+		cinemas.GET("/:cinemaId", controllers.GetCinema)
+		cinemas.GET("/:cinemaId/showtimes", controllers.GetCinemaSchedule)
+		cinemas.GET("/:cinemaId/screens/:screenId/seats", controllers.GetScreenSeats)
+
+		// Rutas restringidas a admin
+		cinemas.POST("/", middlewares.AdminRequired(), controllers.CreateCinema)
+		cinemas.PUT("/:cinemaId", middlewares.AdminRequired(), controllers.UpdateCinema)
+		cinemas.DELETE("/:cinemaId", middlewares.AdminRequired(), controllers.DeleteCinema)
+		cinemas.POST("/:cinemaId/screens", middlewares.AdminRequired(), controllers.CreateScreen)
+		cinemas.PUT("/:cinemaId/screens/:screenId", middlewares.AdminRequired(), controllers.UpdateScreen)
+		cinemas.DELETE("/:cinemaId/screens/:screenId", middlewares.AdminRequired(), controllers.DeleteScreen)
+		cinemas.PUT("/:cinemaId/screens/:screenId/seats", middlewares.AdminRequired(), controllers.UpdateScreenSeats)
+	}
+
+	showtimes := r.Group("/api/showtimes")
+	{
+		// Consultas públicas de funciones
+		showtimes.GET("/", controllers.GetShowtimes)
+		showtimes.GET("/:showtimeId", controllers.GetShowtime)
+
+		// Selección y retención de butacas (usuario autenticado)
+		showtimes.GET("/:showtimeId/seats", middlewares.AuthRequired(), controllers.GetShowtimeSeats)
+		showtimes.POST("/:showtimeId/holds", middlewares.AuthRequired(), controllers.HoldSeats)
+		showtimes.DELETE("/:showtimeId/holds", middlewares.AuthRequired(), controllers.ReleaseHolds)
+
+		// Rutas restringidas a admin
+		showtimes.POST("/", middlewares.AdminRequired(), controllers.CreateShowtime)
+		showtimes.PUT("/:showtimeId", middlewares.AdminRequired(), controllers.UpdateShowtime)
+		showtimes.DELETE("/:showtimeId", middlewares.AdminRequired(), controllers.DeleteShowtime)
+	}
+
+	bookings := r.Group("/api/bookings")
+	{
+		// Compras de entradas del usuario autenticado
+		bookings.GET("/", middlewares.AuthRequired(), controllers.GetMyBookings)
+		bookings.POST("/", middlewares.AuthRequired(), controllers.CreateBooking)
+		bookings.GET("/:bookingId", middlewares.AuthRequired(), controllers.GetBooking)
+		bookings.POST("/:bookingId/cancel", middlewares.AuthRequired(), controllers.CancelBooking)
+
+		// Reintento de los reembolsos fallidos (restringido a admin)
+		bookings.POST("/refunds/retry", middlewares.AdminRequired(), controllers.RetryPendingRefunds)
+	}
+
+	// Funciones de una película agrupadas por cine
+	r.GET("/api/movies/:movieId/showtimes", controllers.GetMovieShowtimes)
+}
@@ -0,0 +1,845 @@
+package services
+
+import (
+	"cine_conecta_backend/cinemas/models"
+	"cine_conecta_backend/cinemas/payments"
+	"cine_conecta_backend/config"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// Tiempo que una butaca queda retenida mientras el usuario paga
+	seatHoldDuration = 10 * time.Minute
+	// Máximo de butacas por compra
+	maxSeatsPerBooking = 10
+	// Máximo de filas y butacas por fila al generar el mapa de una sala
+	maxSeatRows    = 52
+	maxSeatsPerRow = 60
+	// Tiempo máximo de espera de la pasarela de pagos
+	paymentTimeout = 30 * time.Second
+	// Margen tras el cobro para confirmar la reserva antes de que el barrido la libere
+	pendingBookingGrace = 2 * time.Minute
+)
+
+// ErrHasBookings indica que la operación afectaría a entradas ya vendidas
+var ErrHasBookings = errors.New("hay entradas vendidas; cancela las reservas antes de continuar")
+
+// Estados de una butaca en el mapa de una función
+const (
+	SeatAvailable = "available"
+	SeatHeld      = "held"
+	SeatBooked    = "booked"
+)
+
+// SeatRowInput describe una fila del mapa de butacas de una sala
+type SeatRowInput struct {
+	Row   string          `json:"row"`
+	Seats int             `json:"seats"`
+	Type  models.SeatType `json:"type"`
+	// Tipos de butaca distintos al de la fila, por número de butaca
+	Overrides map[int]models.SeatType `json:"overrides"`
+}
+
+// ShowtimeSeat es una butaca con su estado para una función
+type ShowtimeSeat struct {
+	models.Seat
+	Label  string `json:"label"`
+	Status string `json:"status"`
+	Mine   bool   `json:"mine"` // La retención o la venta pertenece al usuario actual
+}
+
+var (
+	paymentProvider     payments.Provider
+	paymentProviderErr  error
+	paymentProviderOnce sync.Once
+)
+
+// currentPaymentProvider devuelve la pasarela de pagos configurada, creada una sola vez
+// para que el proveedor de pruebas conserve sus cobros en memoria
+func currentPaymentProvider() (payments.Provider, error) {
+	paymentProviderOnce.Do(func() {
+		paymentProvider, paymentProviderErr = payments.NewProviderFromEnv()
+	})
+	return paymentProvider, paymentProviderErr
+}
+
+// GetScreenSeats devuelve el mapa de butacas de una sala ordenado por fila y número
+func GetScreenSeats(cinemaID, screenID uint) (*models.Screen, []models.Seat, error) {
+	var screen models.Screen
+	if err := config.DB.Where("id = ? AND cinema_id = ?", screenID, cinemaID).First(&screen).Error; err != nil {
+		return nil, nil, err
+	}
+
+	var seats []models.Seat
+	if err := config.DB.Where("screen_id = ?", screenID).Order("seat_row ASC, number ASC").Find(&seats).Error; err != nil {
+		return nil, nil, err
+	}
+	return &screen, seats, nil
+}
+
+// SetScreenSeats reemplaza el mapa de butacas de una sala y actualiza su capacidad.
+// No se permite si alguna butaca tiene retenciones o reservas.
+func SetScreenSeats(cinemaID, screenID uint, rows []SeatRowInput) (*models.Screen, []models.Seat, error) {
+	seats, err := buildSeatLayout(screenID, rows)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tx := config.DB.Begin()
+
+	var screen models.Screen
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ? AND cinema_id = ?", screenID, cinemaID).First(&screen).Error; err != nil {
+		tx.Rollback()
+		return nil, nil, err
+	}
+
+	// Las retenciones vencidas no cuentan como uso de la butaca
+	seatIDs := tx.Model(&models.Seat{}).Select("id").Where("screen_id = ?", screenID)
+	if err := tx.Where("seat_id IN (?) AND status = ? AND expires_at <= ?", seatIDs, models.ReservationHeld, time.Now()).
+		Delete(&models.SeatReservation{}).Error; err != nil {
+		tx.Rollback()
+		return nil, nil, err
+	}
+
+	var reserved int64
+	if err := tx.Model(&models.SeatReservation{}).Where("seat_id IN (?)", seatIDs).Count(&reserved).Error; err != nil {
+		tx.Rollback()
+		return nil, nil, err
+	}
+	if reserved > 0 {
+		tx.Rollback()
+		return nil, nil, errors.New("la sala ya tiene butacas reservadas; no se puede modificar su mapa")
+	}
+
+	if err := tx.Where("screen_id = ?", screenID).Delete(&models.Seat{}).Error; err != nil {
+		tx.Rollback()
+		return nil, nil, err
+	}
+	if len(seats) > 0 {
+		if err := tx.Create(&seats).Error; err != nil {
+			tx.Rollback()
+			return nil, nil, err
+		}
+	}
+
+	screen.Capacity = len(seats)
+	if err := tx.Model(&screen).Update("capacity", screen.Capacity).Error; err != nil {
+		tx.Rollback()
+		return nil, nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, nil, err
+	}
+	return &screen, seats, nil
+}
+
+// buildSeatLayout genera las butacas a partir de la descripción de las filas
+func buildSeatLayout(screenID uint, rows []SeatRowInput) ([]models.Seat, error) {
+	if len(rows) > maxSeatRows {
+		return nil, fmt.Errorf("el mapa no puede tener más de %d filas", maxSeatRows)
+	}
+
+	seats := []models.Seat{}
+	seen := make(map[string]bool)
+	for _, row := range rows {
+		name := strings.ToUpper(strings.TrimSpace(row.Row))
+		if name == "" || len(name) > 5 {
+			return nil, errors.New("cada fila necesita un nombre de hasta 5 caracteres")
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("la fila %s está repetida", name)
+		}
+		seen[name] = true
+
+		if row.Seats <= 0 || row.Seats > maxSeatsPerRow {
+			return nil, fmt.Errorf("la fila %s debe tener entre 1 y %d butacas", name, maxSeatsPerRow)
+		}
+		if row.Type == "" {
+			row.Type = models.SeatStandard
+		}
+		if !models.IsValidSeatType(row.Type) {
+			return nil, errors.New("tipo de butaca no válido (standard, vip o wheelchair)")
+		}
+
+		for number := 1; number <= row.Seats; number++ {
+			seatType := row.Type
+			if override, ok := row.Overrides[number]; ok {
+				if !models.IsValidSeatType(override) {
+					return nil, errors.New("tipo de butaca no válido (standard, vip o wheelchair)")
+				}
+				seatType = override
+			}
+			seats = append(seats, models.Seat{ScreenID: screenID, Row: name, Number: number, Type: seatType})
+		}
+	}
+	return seats, nil
+}
+
+// GetShowtimeSeats devuelve el mapa de butacas de una función con el estado de cada una
+func GetShowtimeSeats(showtimeID, userID uint) ([]ShowtimeSeat, error) {
+	var showtime models.Showtime
+	if err := config.DB.First(&showtime, showtimeID).Error; err != nil {
+		return nil, err
+	}
+
+	var seats []models.Seat
+	if err := config.DB.Where("screen_id = ?", showtime.ScreenID).Order("seat_row ASC, number ASC").Find(&seats).Error; err != nil {
+		return nil, err
+	}
+
+	var reservations []models.SeatReservation
+	err := config.DB.Where("showtime_id = ?", showtimeID).
+		Where("status IN ? OR (status = ? AND expires_at > ?)",
+			[]string{models.ReservationBooked, models.ReservationPaying}, models.ReservationHeld, time.Now()).
+		Find(&reservations).Error
+	if err != nil {
+		return nil, err
+	}
+
+	active := make(map[uint]models.SeatReservation, len(reservations))
+	for _, reservation := range reservations {
+		active[reservation.SeatID] = reservation
+	}
+
+	result := make([]ShowtimeSeat, 0, len(seats))
+	for _, seat := range seats {
+		item := ShowtimeSeat{Seat: seat, Label: seat.Label(), Status: SeatAvailable}
+		if reservation, ok := active[seat.ID]; ok {
+			item.Status = SeatHeld // También las que se están pagando
+			if reservation.Status == models.ReservationBooked {
+				item.Status = SeatBooked
+			}
+			item.Mine = userID != 0 && reservation.UserID == userID
+		}
+		result = append(result, item)
+	}
+	return result, nil
+}
+
+// HoldSeats retiene butacas de una función para el usuario durante unos minutos.
+// La selección reemplaza a las retenciones anteriores del usuario en la misma función.
+// La fila de la función se bloquea para que dos usuarios no retengan la misma butaca.
+func HoldSeats(showtimeID, userID uint, seatIDs []uint) ([]models.SeatReservation, error) {
+	seatIDs = uniqueIDs(seatIDs)
+	if len(seatIDs) == 0 {
+		return nil, errors.New("selecciona al menos una butaca")
+	}
+	if len(seatIDs) > maxSeatsPerBooking {
+		return nil, fmt.Errorf("no se pueden retener más de %d butacas", maxSeatsPerBooking)
+	}
+
+	tx := config.DB.Begin()
+
+	showtime, err := lockShowtime(tx, showtimeID)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	now := time.Now()
+	if !showtime.StartsAt.After(now) {
+		tx.Rollback()
+		return nil, errors.New("la función ya comenzó")
+	}
+
+	// Liberar las retenciones vencidas y las anteriores del usuario
+	if err := tx.Where("showtime_id = ? AND status = ? AND (expires_at <= ? OR user_id = ?)",
+		showtimeID, models.ReservationHeld, now, userID).Delete(&models.SeatReservation{}).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	var seats []models.Seat
+	if err := tx.Where("id IN ? AND screen_id = ?", seatIDs, showtime.ScreenID).Find(&seats).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if len(seats) != len(seatIDs) {
+		tx.Rollback()
+		return nil, errors.New("alguna de las butacas no pertenece a la sala de la función")
+	}
+
+	var taken int64
+	if err := tx.Model(&models.SeatReservation{}).
+		Where("showtime_id = ? AND seat_id IN ? AND status <> ?", showtimeID, seatIDs, models.ReservationCancelled).
+		Count(&taken).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if taken > 0 {
+		tx.Rollback()
+		return nil, errors.New("alguna de las butacas ya no está disponible")
+	}
+
+	expiresAt := now.Add(seatHoldDuration)
+	holds := make([]models.SeatReservation, 0, len(seats))
+	for _, seat := range seats {
+		holds = append(holds, models.SeatReservation{
+			ShowtimeID: showtimeID,
+			SeatID:     seat.ID,
+			UserID:     userID,
+			Status:     models.ReservationHeld,
+			ExpiresAt:  &expiresAt,
+		})
+	}
+	if err := tx.Create(&holds).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+
+	for i := range holds {
+		holds[i].Seat = &seats[i]
+	}
+	return holds, nil
+}
+
+// ReleaseHolds libera las butacas retenidas por el usuario en una función
+func ReleaseHolds(showtimeID, userID uint) error {
+	return config.DB.Where("showtime_id = ? AND user_id = ? AND status = ?", showtimeID, userID, models.ReservationHeld).
+		Delete(&models.SeatReservation{}).Error
+}
+
+// CreateBooking convierte las butacas retenidas por el usuario en una compra confirmada.
+// Las butacas se apartan en una transacción corta, el cobro se hace sin bloquear la función
+// y la compra se confirma en una segunda transacción; si el cobro falla las butacas vuelven a quedar retenidas.
+func CreateBooking(userID, showtimeID uint, paymentToken string) (*models.Booking, error) {
+	provider, err := currentPaymentProvider()
+	if err != nil {
+		return nil, err
+	}
+
+	booking, holds, err := reserveHeldSeats(userID, showtimeID, provider.Name())
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), paymentTimeout)
+	defer cancel()
+
+	charge, err := provider.Charge(ctx, payments.ChargeRequest{
+		Amount:    booking.Total,
+		Currency:  booking.Currency,
+		Reference: bookingChargeReference(booking.ID),
+		Token:     paymentToken,
+	})
+	if err != nil {
+		if releaseErr := releasePendingBooking(booking.ID); releaseErr != nil {
+			log.Printf("⚠️  No se pudo liberar la reserva pendiente %d: %v", booking.ID, releaseErr)
+		}
+		return nil, err
+	}
+
+	labels := make([]string, 0, len(holds))
+	for _, hold := range holds {
+		labels = append(labels, hold.Seat.Label())
+	}
+	booking.PaymentReference = charge.Reference
+	booking.QRPayload = buildQRPayload(*booking, labels)
+
+	if err := confirmPendingBooking(booking); err != nil {
+		refundCharge(provider, charge.Reference, booking.Total)
+		if releaseErr := releasePendingBooking(booking.ID); releaseErr != nil {
+			log.Printf("⚠️  No se pudo liberar la reserva pendiente %d: %v", booking.ID, releaseErr)
+		}
+		return nil, err
+	}
+
+	return GetBookingByID(booking.ID)
+}
+
+// reserveHeldSeats crea la reserva pendiente de pago y aparta las butacas retenidas por el usuario
+// para que no caduquen ni se liberen mientras se realiza el cobro. La reserva y las butacas tienen
+// un plazo para confirmarse; si el proceso se interrumpe, SweepPendingBookings las libera al vencer.
+func reserveHeldSeats(userID, showtimeID uint, providerName string) (*models.Booking, []models.SeatReservation, error) {
+	tx := config.DB.Begin()
+
+	showtime, err := lockShowtime(tx, showtimeID)
+	if err != nil {
+		tx.Rollback()
+		return nil, nil, err
+	}
+	now := time.Now()
+	if !showtime.StartsAt.After(now) {
+		tx.Rollback()
+		return nil, nil, errors.New("la función ya comenzó")
+	}
+
+	var holds []models.SeatReservation
+	if err := tx.Preload("Seat").
+		Where("showtime_id = ? AND user_id = ? AND status = ? AND expires_at > ?", showtimeID, userID, models.ReservationHeld, now).
+		Order("seat_id ASC").
+		Find(&holds).Error; err != nil {
+		tx.Rollback()
+		return nil, nil, err
+	}
+	if len(holds) == 0 {
+		tx.Rollback()
+		return nil, nil, errors.New("no tienes butacas retenidas para esta función o la retención expiró")
+	}
+
+	ticketCode, err := generateTicketCode()
+	if err != nil {
+		tx.Rollback()
+		return nil, nil, err
+	}
+
+	deadline := now.Add(paymentTimeout + pendingBookingGrace)
+	booking := models.Booking{
+		UserID:          userID,
+		ShowtimeID:      showtimeID,
+		Status:          models.BookingPending,
+		TicketCode:      ticketCode,
+		SeatCount:       len(holds),
+		Total:           showtime.Price * float64(len(holds)),
+		Currency:        showtime.Currency,
+		PaymentProvider: providerName,
+		ExpiresAt:       &deadline,
+	}
+	if err := tx.Create(&booking).Error; err != nil {
+		tx.Rollback()
+		return nil, nil, err
+	}
+
+	holdIDs := make([]uint, 0, len(holds))
+	for _, hold := range holds {
+		holdIDs = append(holdIDs, hold.ID)
+	}
+	// Si el cobro falla las butacas vuelven a retenidas al menos hasta que venza la retención original
+	if err := tx.Model(&models.SeatReservation{}).Where("id IN ?", holdIDs).Updates(map[string]interface{}{
+		"status":     models.ReservationPaying,
+		"booking_id": booking.ID,
+		"expires_at": gorm.Expr("GREATEST(expires_at, ?)", deadline),
+	}).Error; err != nil {
+		tx.Rollback()
+		return nil, nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, nil, err
+	}
+	return &booking, holds, nil
+}
+
+// confirmPendingBooking guarda el cobro de una reserva pendiente y marca sus butacas como vendidas.
+// Una reserva vencida ya no se confirma: el barrido puede estar reembolsándola.
+func confirmPendingBooking(booking *models.Booking) error {
+	tx := config.DB.Begin()
+
+	result := tx.Model(&models.Booking{}).
+		Where("id = ? AND status = ? AND expires_at > ?", booking.ID, models.BookingPending, time.Now()).
+		Updates(map[string]interface{}{
+			"status":            models.BookingConfirmed,
+			"payment_reference": booking.PaymentReference,
+			"qr_payload":        booking.QRPayload,
+			"expires_at":        nil,
+		})
+	if result.Error != nil {
+		tx.Rollback()
+		return result.Error
+	}
+	if result.RowsAffected != 1 {
+		tx.Rollback()
+		return errors.New("la reserva ya no está pendiente de pago o venció el plazo para confirmarla")
+	}
+
+	if err := tx.Model(&models.SeatReservation{}).
+		Where("booking_id = ? AND status = ?", booking.ID, models.ReservationPaying).
+		Updates(map[string]interface{}{
+			"status":     models.ReservationBooked,
+			"expires_at": nil,
+		}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return err
+	}
+	booking.Status = models.BookingConfirmed
+	return nil
+}
+
+// releasePendingBooking elimina una reserva cuyo cobro no se completó y devuelve sus butacas
+// a retenidas, para que el usuario pueda reintentar el pago mientras no caduque la retención
+func releasePendingBooking(bookingID uint) error {
+	tx := config.DB.Begin()
+
+	if err := tx.Model(&models.SeatReservation{}).
+		Where("booking_id = ? AND status = ?", bookingID, models.ReservationPaying).
+		Updates(map[string]interface{}{
+			"status":     models.ReservationHeld,
+			"booking_id": nil,
+		}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := tx.Where("id = ? AND status = ?", bookingID, models.BookingPending).Delete(&models.Booking{}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// GetUserBookings devuelve las reservas de un usuario, las más recientes primero
+func GetUserBookings(userID uint) ([]models.Booking, error) {
+	var bookings []models.Booking
+	err := bookingQuery().Where("user_id = ?", userID).Order("created_at DESC").Find(&bookings).Error
+	return bookings, err
+}
+
+// GetBookingByID obtiene una reserva con sus butacas y la función
+func GetBookingByID(id uint) (*models.Booking, error) {
+	var booking models.Booking
+	if err := bookingQuery().First(&booking, id).Error; err != nil {
+		return nil, err
+	}
+	return &booking, nil
+}
+
+// CancelBooking cancela una reserva, libera sus butacas y reembolsa el pago.
+// Solo el dueño puede cancelar antes del inicio de la función; un admin puede hacerlo siempre.
+// La cancelación se guarda antes de reembolsar: si el reembolso falla la reserva queda como
+// refund_pending con el error y RetryPendingRefunds lo vuelve a intentar.
+func CancelBooking(bookingID, userID uint, isAdmin bool) (*models.Booking, error) {
+	provider, err := currentPaymentProvider()
+	if err != nil {
+		return nil, err
+	}
+
+	tx := config.DB.Begin()
+
+	var booking models.Booking
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&booking, bookingID).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if booking.UserID != userID && !isAdmin {
+		tx.Rollback()
+		return nil, gorm.ErrRecordNotFound
+	}
+	if booking.Status != models.BookingConfirmed {
+		tx.Rollback()
+		return nil, errors.New("la reserva no está confirmada")
+	}
+
+	var showtime models.Showtime
+	if err := tx.First(&showtime, booking.ShowtimeID).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if !showtime.StartsAt.After(time.Now()) && !isAdmin {
+		tx.Rollback()
+		return nil, errors.New("no se puede cancelar una reserva de una función que ya comenzó")
+	}
+
+	// Sin un cobro de esta pasarela no hay nada que reembolsar
+	needsRefund := booking.PaymentReference != "" && booking.PaymentProvider == provider.Name()
+	status := models.BookingCancelled
+	if needsRefund {
+		status = models.BookingRefundPending
+	}
+
+	now := time.Now()
+	if err := tx.Model(&booking).Updates(map[string]interface{}{
+		"status":       status,
+		"cancelled_at": now,
+	}).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := tx.Model(&models.SeatReservation{}).Where("booking_id = ?", booking.ID).
+		Update("status", models.ReservationCancelled).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+
+	if needsRefund {
+		if err := refundBooking(provider, booking.ID); err != nil {
+			log.Printf("⚠️  No se pudo reembolsar la reserva %d: %v", booking.ID, err)
+		}
+	}
+
+	return GetBookingByID(booking.ID)
+}
+
+// PendingSweepReport resume un barrido de las reservas pendientes de pago vencidas
+type PendingSweepReport struct {
+	DryRun   bool     `json:"dry_run"`
+	Expired  []uint   `json:"expired"`  // Reservas vencidas encontradas
+	Released []uint   `json:"released"` // Reservas liberadas
+	Refunded []uint   `json:"refunded"` // Reservas con un cobro que se reembolsó
+	Errors   []string `json:"errors,omitempty"`
+}
+
+// SweepPendingBookings libera las reservas que siguen pendientes de pago después de su plazo, por
+// ejemplo porque el servidor se detuvo entre el cobro y la confirmación. Si la pasarela registró
+// un cobro se reembolsa antes de liberar las butacas; si el reembolso falla la reserva se conserva
+// para el siguiente barrido.
+func SweepPendingBookings(dryRun bool) (*PendingSweepReport, error) {
+	provider, err := currentPaymentProvider()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	report := &PendingSweepReport{DryRun: dryRun, Expired: []uint{}, Released: []uint{}, Refunded: []uint{}}
+	// Las reservas anteriores al plazo no tienen expires_at: se usa su fecha de creación
+	if err := config.DB.Model(&models.Booking{}).
+		Where("status = ? AND payment_provider = ?", models.BookingPending, provider.Name()).
+		Where("expires_at < ? OR (expires_at IS NULL AND created_at < ?)", now, now.Add(-paymentTimeout-pendingBookingGrace)).
+		Order("id ASC").Pluck("id", &report.Expired).Error; err != nil {
+		return nil, err
+	}
+	if dryRun {
+		return report, nil
+	}
+
+	for _, id := range report.Expired {
+		refunded, err := refundExpiredBooking(provider, id)
+		if err != nil {
+			report.Errors = append(report.Errors, fmt.Sprintf("reserva %d: %v", id, err))
+			continue
+		}
+		if refunded {
+			report.Refunded = append(report.Refunded, id)
+		}
+		if err := releasePendingBooking(id); err != nil {
+			report.Errors = append(report.Errors, fmt.Sprintf("reserva %d: %v", id, err))
+			continue
+		}
+		report.Released = append(report.Released, id)
+	}
+
+	fmt.Printf("[DEBUG-BOOKINGS] Reservas pendientes vencidas: %d, liberadas: %d, reembolsadas: %d\n",
+		len(report.Expired), len(report.Released), len(report.Refunded))
+	return report, nil
+}
+
+// refundExpiredBooking reembolsa el cobro que la pasarela haya registrado para una reserva pendiente
+// vencida. La reserva se reclama antes para que dos barridos simultáneos no reembolsen dos veces.
+func refundExpiredBooking(provider payments.Provider, bookingID uint) (bool, error) {
+	now := time.Now()
+	claim := config.DB.Model(&models.Booking{}).
+		Where("id = ? AND status = ?", bookingID, models.BookingPending).
+		Where("refund_attempted_at IS NULL OR refund_attempted_at < ?", now.Add(-paymentTimeout)).
+		Update("refund_attempted_at", now)
+	if claim.Error != nil {
+		return false, claim.Error
+	}
+	if claim.RowsAffected != 1 {
+		return false, errors.New("la reserva ya se está procesando")
+	}
+
+	var booking models.Booking
+	if err := config.DB.First(&booking, bookingID).Error; err != nil {
+		return false, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), paymentTimeout)
+	defer cancel()
+
+	charge, err := provider.FindCharge(ctx, bookingChargeReference(bookingID))
+	if errors.Is(err, payments.ErrChargeNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("no se pudo consultar el cobro: %w", err)
+	}
+
+	if err := provider.Refund(ctx, charge.Reference, booking.Total); err != nil {
+		if saveErr := config.DB.Model(&booking).Update("refund_error", err.Error()).Error; saveErr != nil {
+			log.Printf("⚠️  No se pudo guardar el error del reembolso de la reserva %d: %v", bookingID, saveErr)
+		}
+		return false, fmt.Errorf("no se pudo reembolsar el pago: %w", err)
+	}
+	return true, nil
+}
+
+// RetryPendingRefunds vuelve a intentar los reembolsos de las reservas canceladas que quedaron
+// pendientes. Devuelve el número de reembolsos completados y de los que siguen fallando.
+func RetryPendingRefunds() (refunded int, failed int, err error) {
+	provider, err := currentPaymentProvider()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var ids []uint
+	if err := config.DB.Model(&models.Booking{}).
+		Where("status = ? AND payment_provider = ?", models.BookingRefundPending, provider.Name()).
+		Order("id ASC").Pluck("id", &ids).Error; err != nil {
+		return 0, 0, err
+	}
+
+	for _, id := range ids {
+		if err := refundBooking(provider, id); err != nil {
+			log.Printf("⚠️  No se pudo reembolsar la reserva %d: %v", id, err)
+			failed++
+			continue
+		}
+		refunded++
+	}
+	return refunded, failed, nil
+}
+
+// refundBooking reembolsa una reserva en refund_pending y guarda el resultado. La reserva se reclama
+// antes de llamar a la pasarela para que dos intentos simultáneos no reembolsen dos veces.
+func refundBooking(provider payments.Provider, bookingID uint) error {
+	now := time.Now()
+	claim := config.DB.Model(&models.Booking{}).
+		Where("id = ? AND status = ?", bookingID, models.BookingRefundPending).
+		Where("refund_attempted_at IS NULL OR refund_attempted_at < ?", now.Add(-paymentTimeout)).
+		Update("refund_attempted_at", now)
+	if claim.Error != nil {
+		return claim.Error
+	}
+	if claim.RowsAffected != 1 {
+		return errors.New("el reembolso ya se está procesando")
+	}
+
+	var booking models.Booking
+	if err := config.DB.First(&booking, bookingID).Error; err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), paymentTimeout)
+	defer cancel()
+
+	if err := provider.Refund(ctx, booking.PaymentReference, booking.Total); err != nil {
+		if saveErr := config.DB.Model(&booking).Update("refund_error", err.Error()).Error; saveErr != nil {
+			log.Printf("⚠️  No se pudo guardar el error del reembolso de la reserva %d: %v", bookingID, saveErr)
+		}
+		return fmt.Errorf("no se pudo reembolsar el pago: %w", err)
+	}
+
+	return config.DB.Model(&booking).Updates(map[string]interface{}{
+		"status":       models.BookingCancelled,
+		"refunded_at":  time.Now(),
+		"refund_error": "",
+	}).Error
+}
+
+// lockShowtime obtiene la función bloqueando su fila hasta el final de la transacción
+func lockShowtime(tx *gorm.DB, showtimeID uint) (*models.Showtime, error) {
+	var showtime models.Showtime
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&showtime, showtimeID).Error; err != nil {
+		return nil, err
+	}
+	return &showtime, nil
+}
+
+// bookingQuery precarga los datos que se muestran con cada reserva
+func bookingQuery() *gorm.DB {
+	return config.DB.
+		Preload("Seats", func(db *gorm.DB) *gorm.DB {
+			return db.Order("seat_id ASC")
+		}).
+		Preload("Seats.Seat").
+		Preload("Showtime.Movie").
+		Preload("Showtime.Screen.Cinema")
+}
+
+// bookingChargeReference es la referencia interna con la que se cobra una reserva
+func bookingChargeReference(bookingID uint) string {
+	return fmt.Sprintf("booking-%d", bookingID)
+}
+
+// refundCharge intenta devolver un cobro cuando la reserva no se pudo guardar
+func refundCharge(provider payments.Provider, reference string, amount float64) {
+	ctx, cancel := context.WithTimeout(context.Background(), paymentTimeout)
+	defer cancel()
+
+	if err := provider.Refund(ctx, reference, amount); err != nil {
+		log.Printf("⚠️  No se pudo reembolsar el cobro %s: %v", reference, err)
+	}
+}
+
+// generateTicketCode genera un código de entrada aleatorio y legible (10 caracteres)
+func generateTicketCode() (string, error) {
+	buf := make([]byte, 10)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(buf)[:10], nil
+}
+
+// buildQRPayload genera el contenido del QR de la entrada firmado con HMAC,
+// para que la taquilla pueda validar que no fue alterado
+func buildQRPayload(booking models.Booking, seatLabels []string) string {
+	payload := fmt.Sprintf("CC1|%s|%d|%d|%s", booking.TicketCode, booking.ID, booking.ShowtimeID, strings.Join(seatLabels, ","))
+
+	mac := hmac.New(sha256.New, []byte(os.Getenv("JWT_SECRET")))
+	mac.Write([]byte(payload))
+	signature := base64.RawURLEncoding.EncodeToString(mac.Sum(nil)[:16])
+
+	return payload + "|" + signature
+}
+
+// uniqueIDs elimina los identificadores repetidos o vacíos conservando el orden
+func uniqueIDs(ids []uint) []uint {
+	seen := make(map[uint]bool, len(ids))
+	result := make([]uint, 0, len(ids))
+	for _, id := range ids {
+		if id == 0 || seen[id] {
+			continue
+		}
+		seen[id] = true
+		result = append(result, id)
+	}
+	return result
+}
+
+// activeBookingStatuses son los estados de las reservas que impiden modificar o eliminar su función:
+// pagadas, pendientes de cobro o con un reembolso por completar
+var activeBookingStatuses = []string{models.BookingPending, models.BookingConfirmed, models.BookingRefundPending}
+
+// checkShowtimeBookings devuelve ErrHasBookings si alguna de las funciones indicadas tiene reservas activas
+func checkShowtimeBookings(tx *gorm.DB, showtimeIDs interface{}) error {
+	var active int64
+	if err := tx.Model(&models.Booking{}).
+		Where("showtime_id IN (?) AND status IN ?", showtimeIDs, activeBookingStatuses).
+		Count(&active).Error; err != nil {
+		return err
+	}
+	if active > 0 {
+		return ErrHasBookings
+	}
+	return nil
+}
+
+// purgeShowtimeBookings elimina las retenciones y reservas canceladas de las funciones indicadas.
+// Devuelve ErrHasBookings si alguna tiene reservas activas.
+func purgeShowtimeBookings(tx *gorm.DB, showtimeIDs interface{}) error {
+	if err := checkShowtimeBookings(tx, showtimeIDs); err != nil {
+		return err
+	}
+
+	if err := tx.Where("showtime_id IN (?)", showtimeIDs).Delete(&models.SeatReservation{}).Error; err != nil {
+		return err
+	}
+	return tx.Where("showtime_id IN (?)", showtimeIDs).Delete(&models.Booking{}).Error
+}
@@ -0,0 +1,486 @@
+package services
+
+import (
+	"cine_conecta_backend/cinemas/models"
+	"cine_conecta_backend/cinemas/payments"
+	"cine_conecta_backend/config"
+	movieModels "cine_conecta_backend/movies/models"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// Las pruebas de compras necesitan una base de datos PostgreSQL de pruebas (TEST_DATABASE_URL)
+// y usan la pasarela de pagos fake
+
+var testDBOnce sync.Once
+
+// setupBookingTest conecta con la base de datos de pruebas, configura la pasarela fake y crea una
+// función de mañana en una sala con cuatro butacas. Los datos creados se eliminan al terminar.
+func setupBookingTest(t *testing.T) (*payments.FakeProvider, *models.Showtime, []models.Seat) {
+	t.Helper()
+
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL no está configurada")
+	}
+
+	var setupErr error
+	testDBOnce.Do(func() {
+		db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+		if err != nil {
+			setupErr = err
+			return
+		}
+		if err := db.AutoMigrate(&movieModels.Genre{}, &movieModels.Movie{}, &models.Cinema{}, &models.Screen{}, &models.Showtime{},
+			&models.Seat{}, &models.Booking{}, &models.SeatReservation{}); err != nil {
+			setupErr = err
+			return
+		}
+		db.Exec("CREATE UNIQUE INDEX IF NOT EXISTS idx_seat_reservations_active ON seat_reservations (showtime_id, seat_id) WHERE status <> 'cancelled'")
+		db.Exec("CREATE UNIQUE INDEX IF NOT EXISTS idx_bookings_ticket_code ON bookings (ticket_code)")
+		config.DB = db
+	})
+	if setupErr != nil {
+		t.Fatalf("no se pudo preparar la base de datos de pruebas: %v", setupErr)
+	}
+	if config.DB == nil {
+		t.Fatal("la base de datos de pruebas no está disponible")
+	}
+
+	provider := payments.NewFakeProvider()
+	paymentProviderOnce.Do(func() {})
+	paymentProvider, paymentProviderErr = provider, nil
+
+	movie := movieModels.Movie{Title: fmt.Sprintf("Película de prueba %d", time.Now().UnixNano()), RuntimeMinutes: 100}
+	mustCreate(t, &movie)
+	cinema := models.Cinema{Name: "Cine de prueba", City: "Bogotá"}
+	mustCreate(t, &cinema)
+	screen := models.Screen{CinemaID: cinema.ID, Name: "Sala 1"}
+	mustCreate(t, &screen)
+
+	_, seats, err := SetScreenSeats(cinema.ID, screen.ID, []SeatRowInput{{Row: "A", Seats: 4}})
+	if err != nil {
+		t.Fatalf("SetScreenSeats: %v", err)
+	}
+
+	showtime := models.Showtime{
+		MovieID:  movie.ID,
+		ScreenID: screen.ID,
+		StartsAt: time.Now().Add(24 * time.Hour).Truncate(time.Minute),
+		Language: "es",
+		Price:    12000,
+	}
+	if err := CreateShowtime(&showtime); err != nil {
+		t.Fatalf("CreateShowtime: %v", err)
+	}
+
+	t.Cleanup(func() {
+		config.DB.Where("showtime_id = ?", showtime.ID).Delete(&models.SeatReservation{})
+		config.DB.Where("showtime_id = ?", showtime.ID).Delete(&models.Booking{})
+		config.DB.Delete(&models.Showtime{}, showtime.ID)
+		config.DB.Where("screen_id = ?", screen.ID).Delete(&models.Seat{})
+		config.DB.Delete(&models.Screen{}, screen.ID)
+		config.DB.Delete(&models.Cinema{}, cinema.ID)
+		config.DB.Unscoped().Delete(&movieModels.Movie{}, movie.ID)
+	})
+
+	return provider, &showtime, seats
+}
+
+func mustCreate(t *testing.T, value interface{}) {
+	t.Helper()
+	if err := config.DB.Create(value).Error; err != nil {
+		t.Fatalf("no se pudo crear %T: %v", value, err)
+	}
+}
+
+// reservationStatuses devuelve el estado de las butacas de una función por butaca
+func reservationStatuses(t *testing.T, showtimeID uint) map[uint]string {
+	t.Helper()
+	var reservations []models.SeatReservation
+	if err := config.DB.Where("showtime_id = ?", showtimeID).Find(&reservations).Error; err != nil {
+		t.Fatalf("no se pudieron leer las reservas: %v", err)
+	}
+	statuses := make(map[uint]string, len(reservations))
+	for _, reservation := range reservations {
+		statuses[reservation.SeatID] = reservation.Status
+	}
+	return statuses
+}
+
+func TestCreateBookingConfirmsHeldSeats(t *testing.T) {
+	provider, showtime, seats := setupBookingTest(t)
+
+	if _, err := HoldSeats(showtime.ID, 1, []uint{seats[0].ID, seats[1].ID}); err != nil {
+		t.Fatalf("HoldSeats: %v", err)
+	}
+
+	booking, err := CreateBooking(1, showtime.ID, "tok_visa")
+	if err != nil {
+		t.Fatalf("CreateBooking: %v", err)
+	}
+	if booking.Status != models.BookingConfirmed || booking.SeatCount != 2 || booking.Total != 24000 {
+		t.Fatalf("reserva inesperada: estado %s, %d butacas, total %v", booking.Status, booking.SeatCount, booking.Total)
+	}
+	if booking.TicketCode == "" || booking.QRPayload == "" {
+		t.Fatal("la reserva confirmada debe tener código de entrada y QR")
+	}
+	if amount, ok := provider.Charged(booking.PaymentReference); !ok || amount != 24000 {
+		t.Fatalf("cobro = %v, %v; se esperaba 24000", amount, ok)
+	}
+
+	statuses := reservationStatuses(t, showtime.ID)
+	for _, seat := range seats[:2] {
+		if statuses[seat.ID] != models.ReservationBooked {
+			t.Fatalf("butaca %s en estado %q; se esperaba booked", seat.Label(), statuses[seat.ID])
+		}
+	}
+}
+
+func TestCreateBookingChargeDeclined(t *testing.T) {
+	_, showtime, seats := setupBookingTest(t)
+
+	if _, err := HoldSeats(showtime.ID, 1, []uint{seats[0].ID}); err != nil {
+		t.Fatalf("HoldSeats: %v", err)
+	}
+
+	if _, err := CreateBooking(1, showtime.ID, payments.FakeTokenDeclined); !errors.Is(err, payments.ErrPaymentDeclined) {
+		t.Fatalf("err = %v; se esperaba ErrPaymentDeclined", err)
+	}
+
+	// No queda ninguna reserva y la butaca sigue retenida para reintentar el pago
+	var count int64
+	config.DB.Model(&models.Booking{}).Where("showtime_id = ?", showtime.ID).Count(&count)
+	if count != 0 {
+		t.Fatalf("quedaron %d reservas tras un cobro rechazado", count)
+	}
+	if status := reservationStatuses(t, showtime.ID)[seats[0].ID]; status != models.ReservationHeld {
+		t.Fatalf("butaca en estado %q; se esperaba held", status)
+	}
+
+	booking, err := CreateBooking(1, showtime.ID, "tok_visa")
+	if err != nil {
+		t.Fatalf("reintento de CreateBooking: %v", err)
+	}
+	if booking.Status != models.BookingConfirmed {
+		t.Fatalf("estado = %s; se esperaba confirmed", booking.Status)
+	}
+}
+
+func TestHoldSeatsRejectsSeatHeldByAnotherUser(t *testing.T) {
+	_, showtime, seats := setupBookingTest(t)
+
+	if _, err := HoldSeats(showtime.ID, 1, []uint{seats[0].ID}); err != nil {
+		t.Fatalf("HoldSeats: %v", err)
+	}
+	if _, err := HoldSeats(showtime.ID, 2, []uint{seats[0].ID, seats[1].ID}); err == nil {
+		t.Fatal("se esperaba un error al retener una butaca ya retenida")
+	}
+
+	// La retención fallida no deja butacas retenidas para el segundo usuario
+	if status, ok := reservationStatuses(t, showtime.ID)[seats[1].ID]; ok {
+		t.Fatalf("la butaca %s quedó en estado %q", seats[1].Label(), status)
+	}
+}
+
+func TestHoldSeatsConcurrentSameSeat(t *testing.T) {
+	_, showtime, seats := setupBookingTest(t)
+
+	const users = 5
+	var wg sync.WaitGroup
+	errs := make(chan error, users)
+	for user := uint(1); user <= users; user++ {
+		wg.Add(1)
+		go func(user uint) {
+			defer wg.Done()
+			_, err := HoldSeats(showtime.ID, user, []uint{seats[2].ID})
+			errs <- err
+		}(user)
+	}
+	wg.Wait()
+	close(errs)
+
+	succeeded := 0
+	for err := range errs {
+		if err == nil {
+			succeeded++
+		}
+	}
+	if succeeded != 1 {
+		t.Fatalf("%d retenciones simultáneas de la misma butaca tuvieron éxito; se esperaba 1", succeeded)
+	}
+}
+
+func TestCancelBookingRefundsAndReleasesSeats(t *testing.T) {
+	provider, showtime, seats := setupBookingTest(t)
+
+	if _, err := HoldSeats(showtime.ID, 1, []uint{seats[0].ID}); err != nil {
+		t.Fatalf("HoldSeats: %v", err)
+	}
+	booking, err := CreateBooking(1, showtime.ID, "tok_visa")
+	if err != nil {
+		t.Fatalf("CreateBooking: %v", err)
+	}
+
+	if _, err := CancelBooking(booking.ID, 2, false); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("otro usuario canceló la reserva: err = %v", err)
+	}
+
+	cancelled, err := CancelBooking(booking.ID, 1, false)
+	if err != nil {
+		t.Fatalf("CancelBooking: %v", err)
+	}
+	if cancelled.Status != models.BookingCancelled || cancelled.RefundedAt == nil {
+		t.Fatalf("estado = %s, reembolsada = %v; se esperaba cancelada y reembolsada", cancelled.Status, cancelled.RefundedAt)
+	}
+	if _, ok := provider.Charged(booking.PaymentReference); ok {
+		t.Fatal("el cobro no se reembolsó")
+	}
+	if status := reservationStatuses(t, showtime.ID)[seats[0].ID]; status != models.ReservationCancelled {
+		t.Fatalf("butaca en estado %q; se esperaba cancelled", status)
+	}
+
+	if _, err := CancelBooking(booking.ID, 1, false); err == nil {
+		t.Fatal("se esperaba un error al cancelar dos veces la misma reserva")
+	}
+
+	// La butaca liberada se puede volver a retener
+	if _, err := HoldSeats(showtime.ID, 2, []uint{seats[0].ID}); err != nil {
+		t.Fatalf("la butaca cancelada no se pudo volver a retener: %v", err)
+	}
+}
+
+func TestCancelBookingKeepsFailedRefundPending(t *testing.T) {
+	provider, showtime, seats := setupBookingTest(t)
+
+	if _, err := HoldSeats(showtime.ID, 1, []uint{seats[0].ID}); err != nil {
+		t.Fatalf("HoldSeats: %v", err)
+	}
+	booking, err := CreateBooking(1, showtime.ID, "tok_visa")
+	if err != nil {
+		t.Fatalf("CreateBooking: %v", err)
+	}
+
+	provider.FailRefunds(true)
+	cancelled, err := CancelBooking(booking.ID, 1, false)
+	if err != nil {
+		t.Fatalf("CancelBooking: %v", err)
+	}
+	if cancelled.Status != models.BookingRefundPending || cancelled.RefundError == "" {
+		t.Fatalf("estado = %s, error = %q; se esperaba refund_pending con el error", cancelled.Status, cancelled.RefundError)
+	}
+	if _, ok := provider.Charged(booking.PaymentReference); !ok {
+		t.Fatal("el cobro desapareció aunque el reembolso falló")
+	}
+
+	// El reintento inmediato espera a que venza el intento anterior
+	config.DB.Model(&models.Booking{}).Where("id = ?", booking.ID).Update("refund_attempted_at", time.Now().Add(-time.Hour))
+
+	provider.FailRefunds(false)
+	if _, _, err := RetryPendingRefunds(); err != nil {
+		t.Fatalf("RetryPendingRefunds: %v", err)
+	}
+	refunded, err := GetBookingByID(booking.ID)
+	if err != nil {
+		t.Fatalf("GetBookingByID: %v", err)
+	}
+	if refunded.Status != models.BookingCancelled || refunded.RefundedAt == nil || refunded.RefundError != "" {
+		t.Fatalf("estado = %s, reembolsada = %v, error = %q tras reintentar", refunded.Status, refunded.RefundedAt, refunded.RefundError)
+	}
+	if _, ok := provider.Charged(booking.PaymentReference); ok {
+		t.Fatal("el reintento no reembolsó el cobro")
+	}
+}
+
+func TestUpdateShowtimeRejectsScheduleChangeWithBookings(t *testing.T) {
+	_, showtime, seats := setupBookingTest(t)
+
+	if _, err := HoldSeats(showtime.ID, 1, []uint{seats[0].ID}); err != nil {
+		t.Fatalf("HoldSeats: %v", err)
+	}
+
+	moved := *showtime
+	moved.StartsAt = showtime.StartsAt.Add(2 * time.Hour)
+	moved.EndsAt = time.Time{}
+	if err := UpdateShowtime(&moved); !errors.Is(err, ErrHasBookings) {
+		t.Fatalf("err = %v; se esperaba ErrHasBookings", err)
+	}
+
+	// Los cambios que no afectan a las entradas siguen permitidos
+	subtitled := *showtime
+	subtitled.Subtitles = "en"
+	if err := UpdateShowtime(&subtitled); err != nil {
+		t.Fatalf("UpdateShowtime: %v", err)
+	}
+}
+
+func TestCreateShowtimeConcurrentOverlap(t *testing.T) {
+	_, showtime, _ := setupBookingTest(t)
+
+	const attempts = 4
+	var wg sync.WaitGroup
+	created := make(chan uint, attempts)
+	for i := 0; i < attempts; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			candidate := models.Showtime{
+				MovieID:  showtime.MovieID,
+				ScreenID: showtime.ScreenID,
+				StartsAt: showtime.StartsAt.Add(6 * time.Hour),
+				Language: "es",
+				Price:    12000,
+			}
+			if err := CreateShowtime(&candidate); err == nil {
+				created <- candidate.ID
+			}
+		}()
+	}
+	wg.Wait()
+	close(created)
+
+	var ids []uint
+	for id := range created {
+		ids = append(ids, id)
+	}
+	t.Cleanup(func() {
+		if len(ids) > 0 {
+			config.DB.Delete(&models.Showtime{}, ids)
+		}
+	})
+	if len(ids) != 1 {
+		t.Fatalf("se crearon %d funciones solapadas en la misma sala; se esperaba 1", len(ids))
+	}
+}
+
+// expirePendingBooking adelanta el plazo de una reserva pendiente y de sus butacas como si hubiera vencido
+func expirePendingBooking(t *testing.T, bookingID uint) {
+	t.Helper()
+	past := time.Now().Add(-time.Minute)
+	if err := config.DB.Model(&models.Booking{}).Where("id = ?", bookingID).Update("expires_at", past).Error; err != nil {
+		t.Fatalf("no se pudo vencer la reserva: %v", err)
+	}
+	if err := config.DB.Model(&models.SeatReservation{}).Where("booking_id = ?", bookingID).Update("expires_at", past).Error; err != nil {
+		t.Fatalf("no se pudieron vencer las butacas: %v", err)
+	}
+}
+
+func containsID(ids []uint, id uint) bool {
+	for _, candidate := range ids {
+		if candidate == id {
+			return true
+		}
+	}
+	return false
+}
+
+func TestSweepPendingBookingsRefundsChargeAfterCrash(t *testing.T) {
+	provider, showtime, seats := setupBookingTest(t)
+
+	if _, err := HoldSeats(showtime.ID, 1, []uint{seats[0].ID, seats[1].ID}); err != nil {
+		t.Fatalf("HoldSeats: %v", err)
+	}
+
+	// Simular una interrupción entre el cobro y la confirmación: la reserva queda pendiente y cobrada
+	booking, _, err := reserveHeldSeats(1, showtime.ID, provider.Name())
+	if err != nil {
+		t.Fatalf("reserveHeldSeats: %v", err)
+	}
+	charge, err := provider.Charge(context.Background(), payments.ChargeRequest{
+		Amount:    booking.Total,
+		Currency:  booking.Currency,
+		Reference: bookingChargeReference(booking.ID),
+		Token:     "tok_visa",
+	})
+	if err != nil {
+		t.Fatalf("Charge: %v", err)
+	}
+
+	// Antes del plazo el barrido no toca la reserva
+	report, err := SweepPendingBookings(false)
+	if err != nil {
+		t.Fatalf("SweepPendingBookings: %v", err)
+	}
+	if containsID(report.Expired, booking.ID) {
+		t.Fatal("el barrido liberó una reserva que todavía estaba en plazo")
+	}
+	if err := checkShowtimeBookings(config.DB, []uint{showtime.ID}); !errors.Is(err, ErrHasBookings) {
+		t.Fatalf("err = %v; la reserva pendiente debe bloquear la función", err)
+	}
+
+	expirePendingBooking(t, booking.ID)
+
+	// Una reserva vencida ya no se puede confirmar
+	booking.PaymentReference = charge.Reference
+	if err := confirmPendingBooking(booking); err == nil {
+		t.Fatal("se confirmó una reserva vencida")
+	}
+
+	report, err = SweepPendingBookings(false)
+	if err != nil {
+		t.Fatalf("SweepPendingBookings: %v", err)
+	}
+	if !containsID(report.Released, booking.ID) || !containsID(report.Refunded, booking.ID) {
+		t.Fatalf("reporte inesperado: %+v", report)
+	}
+	if _, ok := provider.Charged(charge.Reference); ok {
+		t.Fatal("el cobro de la reserva vencida no se reembolsó")
+	}
+	if err := config.DB.First(&models.Booking{}, booking.ID).Error; !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("err = %v; la reserva vencida debe eliminarse", err)
+	}
+	if err := checkShowtimeBookings(config.DB, []uint{showtime.ID}); err != nil {
+		t.Fatalf("la función sigue bloqueada tras el barrido: %v", err)
+	}
+
+	// Las butacas quedan libres para otro usuario
+	if _, err := HoldSeats(showtime.ID, 2, []uint{seats[0].ID, seats[1].ID}); err != nil {
+		t.Fatalf("las butacas no se liberaron: %v", err)
+	}
+}
+
+func TestSweepPendingBookingsReleasesUnchargedBooking(t *testing.T) {
+	provider, showtime, seats := setupBookingTest(t)
+
+	if _, err := HoldSeats(showtime.ID, 1, []uint{seats[0].ID}); err != nil {
+		t.Fatalf("HoldSeats: %v", err)
+	}
+
+	// Interrupción antes del cobro
+	booking, _, err := reserveHeldSeats(1, showtime.ID, provider.Name())
+	if err != nil {
+		t.Fatalf("reserveHeldSeats: %v", err)
+	}
+	expirePendingBooking(t, booking.ID)
+
+	dry, err := SweepPendingBookings(true)
+	if err != nil {
+		t.Fatalf("SweepPendingBookings: %v", err)
+	}
+	if !containsID(dry.Expired, booking.ID) || len(dry.Released) != 0 {
+		t.Fatalf("reporte inesperado en modo dry-run: %+v", dry)
+	}
+	if status := reservationStatuses(t, showtime.ID)[seats[0].ID]; status != models.ReservationPaying {
+		t.Fatalf("butaca en estado %q tras el dry-run; se esperaba paying", status)
+	}
+
+	report, err := SweepPendingBookings(false)
+	if err != nil {
+		t.Fatalf("SweepPendingBookings: %v", err)
+	}
+	if !containsID(report.Released, booking.ID) || containsID(report.Refunded, booking.ID) {
+		t.Fatalf("reporte inesperado: %+v", report)
+	}
+	if _, err := HoldSeats(showtime.ID, 2, []uint{seats[0].ID}); err != nil {
+		t.Fatalf("la butaca no se liberó: %v", err)
+	}
+}
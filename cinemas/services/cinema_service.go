@@ -0,0 +1,185 @@
+package services
+
+import (
+	"cine_conecta_backend/cinemas/models"
+	"cine_conecta_backend/config"
+	"errors"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// GetCinemas obtiene los cines, opcionalmente filtrados por ciudad
+func GetCinemas(city string) ([]models.Cinema, error) {
+	var cinemas []models.Cinema
+	query := config.DB.Preload("Screens", func(db *gorm.DB) *gorm.DB {
+		return db.Order("name ASC")
+	}).Order("city ASC, name ASC")
+
+	if city != "" {
+		query = query.Where("LOWER(city) = LOWER(?)", strings.TrimSpace(city))
+	}
+
+	if err := query.Find(&cinemas).Error; err != nil {
+		return nil, err
+	}
+	return cinemas, nil
+}
+
+// GetCinemaByID obtiene un cine con sus salas
+func GetCinemaByID(id uint) (models.Cinema, error) {
+	var cinema models.Cinema
+	err := config.DB.Preload("Screens", func(db *gorm.DB) *gorm.DB {
+		return db.Order("name ASC")
+	}).First(&cinema, id).Error
+	return cinema, err
+}
+
+// CreateCinema guarda un nuevo cine
+func CreateCinema(cinema *models.Cinema) error {
+	if err := validateCinema(cinema); err != nil {
+		return err
+	}
+	cinema.Screens = nil
+	return config.DB.Create(cinema).Error
+}
+
+// UpdateCinema actualiza los datos de un cine existente
+func UpdateCinema(cinema *models.Cinema) error {
+	if err := validateCinema(cinema); err != nil {
+		return err
+	}
+	cinema.Screens = nil
+	return config.DB.Save(cinema).Error
+}
+
+// DeleteCinema elimina un cine junto con sus salas, butacas y funciones.
+// No se permite si alguna función tiene entradas vendidas.
+func DeleteCinema(id uint) error {
+	tx := config.DB.Begin()
+
+	screenIDs := tx.Model(&models.Screen{}).Select("id").Where("cinema_id = ?", id)
+	showtimeIDs := tx.Model(&models.Showtime{}).Select("id").Where("screen_id IN (?)", screenIDs)
+	if err := purgeShowtimeBookings(tx, showtimeIDs); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := tx.Where("screen_id IN (?)", screenIDs).Delete(&models.Showtime{}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := tx.Where("screen_id IN (?)", screenIDs).Delete(&models.Seat{}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := tx.Where("cinema_id = ?", id).Delete(&models.Screen{}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	result := tx.Delete(&models.Cinema{}, id)
+	if result.Error != nil {
+		tx.Rollback()
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		tx.Rollback()
+		return gorm.ErrRecordNotFound
+	}
+
+	return tx.Commit().Error
+}
+
+// CreateScreen añade una sala a un cine
+func CreateScreen(screen *models.Screen) error {
+	if err := config.DB.First(&models.Cinema{}, screen.CinemaID).Error; err != nil {
+		return errors.New("cine no encontrado")
+	}
+	if err := validateScreen(screen); err != nil {
+		return err
+	}
+	return config.DB.Create(screen).Error
+}
+
+// UpdateScreen actualiza una sala de un cine
+func UpdateScreen(cinemaID, screenID uint, name string, capacity int) (*models.Screen, error) {
+	var screen models.Screen
+	if err := config.DB.Where("id = ? AND cinema_id = ?", screenID, cinemaID).First(&screen).Error; err != nil {
+		return nil, err
+	}
+
+	if name != "" {
+		screen.Name = name
+	}
+	if capacity != 0 {
+		screen.Capacity = capacity
+	}
+	if err := validateScreen(&screen); err != nil {
+		return nil, err
+	}
+
+	if err := config.DB.Save(&screen).Error; err != nil {
+		return nil, err
+	}
+	return &screen, nil
+}
+
+// DeleteScreen elimina una sala con sus butacas y funciones.
+// No se permite si alguna función tiene entradas vendidas.
+func DeleteScreen(cinemaID, screenID uint) error {
+	if err := config.DB.Where("id = ? AND cinema_id = ?", screenID, cinemaID).First(&models.Screen{}).Error; err != nil {
+		return err
+	}
+
+	tx := config.DB.Begin()
+
+	showtimeIDs := tx.Model(&models.Showtime{}).Select("id").Where("screen_id = ?", screenID)
+	if err := purgeShowtimeBookings(tx, showtimeIDs); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := tx.Where("screen_id = ?", screenID).Delete(&models.Showtime{}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := tx.Where("screen_id = ?", screenID).Delete(&models.Seat{}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := tx.Delete(&models.Screen{}, screenID).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// validateCinema comprueba los campos obligatorios y la zona horaria
+func validateCinema(cinema *models.Cinema) error {
+	cinema.Name = strings.TrimSpace(cinema.Name)
+	cinema.City = strings.TrimSpace(cinema.City)
+	if cinema.Name == "" || cinema.City == "" {
+		return errors.New("el nombre y la ciudad del cine son obligatorios")
+	}
+
+	if cinema.Timezone == "" {
+		cinema.Timezone = models.DefaultTimezone
+	}
+	if _, err := time.LoadLocation(cinema.Timezone); err != nil {
+		return errors.New("zona horaria no válida")
+	}
+	return nil
+}
+
+// validateScreen comprueba los datos de una sala
+func validateScreen(screen *models.Screen) error {
+	screen.Name = strings.TrimSpace(screen.Name)
+	if screen.Name == "" {
+		return errors.New("el nombre de la sala es obligatorio")
+	}
+	if screen.Capacity < 0 {
+		return errors.New("la capacidad no puede ser negativa")
+	}
+	return nil
+}
@@ -0,0 +1,356 @@
+package services
+
+import (
+	"cine_conecta_backend/cinemas/models"
+	"cine_conecta_backend/config"
+	movieModels "cine_conecta_backend/movies/models"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	_ "time/tzdata" // Incluir las zonas horarias por si el entorno no las tiene (Vercel)
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// Duración usada cuando la película no tiene duración registrada
+	defaultRuntimeMinutes = 120
+	// Tiempo de limpieza de la sala entre funciones
+	screenCleanupMinutes = 15
+)
+
+// ShowtimeFilter define los criterios para buscar funciones
+type ShowtimeFilter struct {
+	MovieID  uint
+	CinemaID uint
+	City     string
+	Date     string    // Día local del cine (YYYY-MM-DD)
+	From     time.Time // Inicio del intervalo (incluido)
+	To       time.Time // Fin del intervalo (excluido)
+}
+
+// CinemaShowtimes agrupa las funciones de un cine
+type CinemaShowtimes struct {
+	Cinema    models.Cinema     `json:"cinema"`
+	Showtimes []models.Showtime `json:"showtimes"`
+}
+
+// DaySchedule agrupa las funciones de un día por película
+type DaySchedule struct {
+	Date   string          `json:"date"`
+	Movies []MovieSchedule `json:"movies"`
+}
+
+// MovieSchedule contiene las funciones de una película en un día
+type MovieSchedule struct {
+	Movie     *movieModels.Movie `json:"movie"`
+	Showtimes []models.Showtime  `json:"showtimes"`
+}
+
+// FindShowtimes busca funciones según el filtro, ordenadas por hora de inicio
+func FindShowtimes(filter ShowtimeFilter) ([]models.Showtime, error) {
+	query := config.DB.Model(&models.Showtime{}).
+		Select("showtimes.*").
+		Joins("JOIN screens ON screens.id = showtimes.screen_id").
+		Joins("JOIN cinemas ON cinemas.id = screens.cinema_id").
+		Where("showtimes.movie_id IN (" + movieModels.ActiveMovieIDs + ")")
+
+	if filter.MovieID != 0 {
+		query = query.Where("showtimes.movie_id = ?", filter.MovieID)
+	}
+	if filter.CinemaID != 0 {
+		query = query.Where("cinemas.id = ?", filter.CinemaID)
+	}
+	if filter.City != "" {
+		query = query.Where("LOWER(cinemas.city) = LOWER(?)", strings.TrimSpace(filter.City))
+	}
+	if filter.Date != "" {
+		// El día se interpreta en la zona horaria de cada cine
+		query = query.Where("(showtimes.starts_at AT TIME ZONE cinemas.timezone)::date = ?", filter.Date)
+	}
+	if !filter.From.IsZero() {
+		query = query.Where("showtimes.starts_at >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		query = query.Where("showtimes.starts_at < ?", filter.To)
+	}
+
+	var showtimes []models.Showtime
+	err := query.
+		Preload("Screen.Cinema").
+		Preload("Movie").
+		Order("showtimes.starts_at ASC").
+		Find(&showtimes).Error
+	return showtimes, err
+}
+
+// GetMovieShowtimesByCinema devuelve las funciones de una película agrupadas por cine.
+// Sin fecha se devuelven las funciones a partir de ahora.
+func GetMovieShowtimesByCinema(movieID uint, city, date string) ([]CinemaShowtimes, error) {
+	filter := ShowtimeFilter{MovieID: movieID, City: city, Date: date}
+	if date == "" {
+		filter.From = time.Now()
+	}
+
+	showtimes, err := FindShowtimes(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	groups := []CinemaShowtimes{}
+	index := make(map[uint]int)
+	for _, showtime := range showtimes {
+		cinema := *showtime.Screen.Cinema
+		pos, ok := index[cinema.ID]
+		if !ok {
+			pos = len(groups)
+			index[cinema.ID] = pos
+			groups = append(groups, CinemaShowtimes{Cinema: cinema})
+		}
+		groups[pos].Showtimes = append(groups[pos].Showtimes, showtime)
+	}
+
+	return groups, nil
+}
+
+// GetCinemaSchedule devuelve la cartelera de un cine para los próximos días, agrupada por día y película
+func GetCinemaSchedule(cinemaID uint, from string, days int) (*models.Cinema, []DaySchedule, error) {
+	cinema, err := GetCinemaByID(cinemaID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	location, err := time.LoadLocation(cinema.Timezone)
+	if err != nil {
+		location = time.UTC
+	}
+
+	// Por defecto desde hoy (en la zona horaria del cine)
+	now := time.Now().In(location)
+	start := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, location)
+	if from != "" {
+		start, err = time.ParseInLocation("2006-01-02", from, location)
+		if err != nil {
+			return nil, nil, errors.New("fecha inválida, use el formato YYYY-MM-DD")
+		}
+	}
+	if days <= 0 || days > 31 {
+		days = 7
+	}
+
+	showtimes, err := FindShowtimes(ShowtimeFilter{
+		CinemaID: cinemaID,
+		From:     start,
+		To:       start.AddDate(0, 0, days),
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	schedule := []DaySchedule{}
+	dayIndex := make(map[string]int)
+	movieIndex := make(map[string]int)
+	for _, showtime := range showtimes {
+		day := showtime.StartsAt.In(location).Format("2006-01-02")
+		dPos, ok := dayIndex[day]
+		if !ok {
+			dPos = len(schedule)
+			dayIndex[day] = dPos
+			schedule = append(schedule, DaySchedule{Date: day, Movies: []MovieSchedule{}})
+		}
+
+		key := fmt.Sprintf("%s|%d", day, showtime.MovieID)
+		mPos, ok := movieIndex[key]
+		if !ok {
+			mPos = len(schedule[dPos].Movies)
+			movieIndex[key] = mPos
+			schedule[dPos].Movies = append(schedule[dPos].Movies, MovieSchedule{Movie: showtime.Movie})
+		}
+
+		// La película ya se incluye en el grupo
+		showtime.Movie = nil
+		showtime.Screen.Cinema = nil
+		schedule[dPos].Movies[mPos].Showtimes = append(schedule[dPos].Movies[mPos].Showtimes, showtime)
+	}
+
+	return &cinema, schedule, nil
+}
+
+// GetShowtimeByID obtiene una función con su película, sala y cine
+func GetShowtimeByID(id uint) (models.Showtime, error) {
+	var showtime models.Showtime
+	err := config.DB.Preload("Screen.Cinema").Preload("Movie").First(&showtime, id).Error
+	return showtime, err
+}
+
+// CreateShowtime programa una nueva función validando la sala, la película y los solapamientos
+func CreateShowtime(showtime *models.Showtime) error {
+	if err := prepareShowtime(showtime); err != nil {
+		return err
+	}
+	showtime.Movie = nil
+	showtime.Screen = nil
+
+	tx := config.DB.Begin()
+
+	if err := checkScreenAvailable(tx, showtime); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Create(showtime).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit().Error
+}
+
+// UpdateShowtime modifica una función existente. La sala, la película y el horario no pueden
+// cambiar si la función tiene entradas vendidas o butacas retenidas, ya que dejarían de corresponder.
+func UpdateShowtime(showtime *models.Showtime) error {
+	if err := prepareShowtime(showtime); err != nil {
+		return err
+	}
+	showtime.Movie = nil
+	showtime.Screen = nil
+
+	tx := config.DB.Begin()
+
+	// La sala se bloquea antes que la función, en el mismo orden que al crear funciones
+	if err := checkScreenAvailable(tx, showtime); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	current, err := lockShowtime(tx, showtime.ID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if current.ScreenID != showtime.ScreenID || current.MovieID != showtime.MovieID ||
+		!current.StartsAt.Equal(showtime.StartsAt) || !current.EndsAt.Equal(showtime.EndsAt) {
+		if err := checkShowtimeBookings(tx, []uint{showtime.ID}); err != nil {
+			tx.Rollback()
+			return err
+		}
+
+		var held int64
+		if err := tx.Model(&models.SeatReservation{}).
+			Where("showtime_id = ? AND status = ? AND expires_at > ?", showtime.ID, models.ReservationHeld, time.Now()).
+			Count(&held).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+		if held > 0 {
+			tx.Rollback()
+			return ErrHasBookings
+		}
+	}
+
+	if err := tx.Save(showtime).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit().Error
+}
+
+// DeleteShowtime elimina una función junto con sus retenciones y reservas canceladas.
+// No se permite si tiene entradas vendidas.
+func DeleteShowtime(id uint) error {
+	tx := config.DB.Begin()
+
+	if err := purgeShowtimeBookings(tx, []uint{id}); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	result := tx.Delete(&models.Showtime{}, id)
+	if result.Error != nil {
+		tx.Rollback()
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		tx.Rollback()
+		return gorm.ErrRecordNotFound
+	}
+
+	return tx.Commit().Error
+}
+
+// prepareShowtime valida la función y calcula la hora de fin
+func prepareShowtime(showtime *models.Showtime) error {
+	var movie movieModels.Movie
+	if err := config.DB.Select("id", "runtime_minutes").First(&movie, showtime.MovieID).Error; err != nil {
+		return errors.New("película no encontrada")
+	}
+	if err := config.DB.First(&models.Screen{}, showtime.ScreenID).Error; err != nil {
+		return errors.New("sala no encontrada")
+	}
+
+	if showtime.StartsAt.IsZero() {
+		return errors.New("la hora de inicio es obligatoria")
+	}
+	if showtime.Format == "" {
+		showtime.Format = models.Format2D
+	}
+	if !models.IsValidShowtimeFormat(showtime.Format) {
+		return errors.New("formato no válido (2D, 3D, IMAX o 4DX)")
+	}
+	showtime.Language = strings.ToLower(strings.TrimSpace(showtime.Language))
+	if showtime.Language == "" {
+		return errors.New("el idioma es obligatorio")
+	}
+	showtime.Subtitles = strings.ToLower(strings.TrimSpace(showtime.Subtitles))
+	if showtime.Price < 0 {
+		return errors.New("el precio no puede ser negativo")
+	}
+	if showtime.Currency == "" {
+		showtime.Currency = "COP"
+	}
+	showtime.Currency = strings.ToUpper(showtime.Currency)
+
+	// Calcular el fin a partir de la duración de la película si no se indica
+	if showtime.EndsAt.IsZero() {
+		runtime := movie.RuntimeMinutes
+		if runtime <= 0 {
+			runtime = defaultRuntimeMinutes
+		}
+		showtime.EndsAt = showtime.StartsAt.Add(time.Duration(runtime) * time.Minute)
+	}
+	if !showtime.EndsAt.After(showtime.StartsAt) {
+		return errors.New("la hora de fin debe ser posterior a la de inicio")
+	}
+
+	return nil
+}
+
+// checkScreenAvailable bloquea la sala dentro de la transacción y comprueba que la función no se solape
+// con otra de la misma sala (incluida la limpieza). El bloqueo impide que dos altas simultáneas pasen
+// la comprobación a la vez.
+func checkScreenAvailable(tx *gorm.DB, showtime *models.Showtime) error {
+	var screen models.Screen
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&screen, showtime.ScreenID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("sala no encontrada")
+		}
+		return err
+	}
+
+	cleanup := time.Duration(screenCleanupMinutes) * time.Minute
+	var overlapping int64
+	err := tx.Model(&models.Showtime{}).
+		Where("screen_id = ? AND id <> ?", showtime.ScreenID, showtime.ID).
+		Where("starts_at < ? AND ends_at > ?", showtime.EndsAt.Add(cleanup), showtime.StartsAt.Add(-cleanup)).
+		Count(&overlapping).Error
+	if err != nil {
+		return err
+	}
+	if overlapping > 0 {
+		return errors.New("la sala ya tiene otra función en ese horario")
+	}
+
+	return nil
+}
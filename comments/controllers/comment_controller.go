@@ -5,6 +5,7 @@ import (
 	"cine_conecta_backend/comments/models"
 	"cine_conecta_backend/comments/services"
 	"cine_conecta_backend/config"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
@@ -12,6 +13,7 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 // POST /api/comments    (AuthRequired)
@@ -27,7 +29,7 @@ func CreateComment(c *gin.Context) {
 
 	// Verificar si la película existe
 	var movieExists bool
-	if err := config.DB.Table("movies").Select("count(*) > 0").Where("id = ?", input.MovieID).Scan(&movieExists).Error; err != nil {
+	if err := config.DB.Table("movies").Select("count(*) > 0").Where("id = ? AND deleted_at IS NULL", input.MovieID).Scan(&movieExists).Error; err != nil {
 		fmt.Printf("[DEBUG-CONTROLLER] Error al verificar si existe la película: %v\n", err)
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Error al verificar si existe la película")
 		return
@@ -183,6 +185,10 @@ func DeleteComment(c *gin.Context) {
 	}
 
 	if err := services.DeleteComment(uint(id)); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.ErrorResponse(c, http.StatusNotFound, "Comentario no encontrado")
+			return
+		}
 		utils.ErrorResponse(c, http.StatusInternalServerError, "No se pudo eliminar el comentario")
 		return
 	}
@@ -270,7 +276,7 @@ func ListAvailableMovies(c *gin.Context) {
 	}
 
 	var movies []MovieInfo
-	if err := config.DB.Table("movies").Select("id, title").Find(&movies).Error; err != nil {
+	if err := config.DB.Table("movies").Select("id, title").Where("deleted_at IS NULL").Find(&movies).Error; err != nil {
 		fmt.Printf("[DEBUG] Error al obtener películas: %v\n", err)
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Error al obtener películas disponibles")
 		return
@@ -297,7 +303,7 @@ func CheckMovieExists(c *gin.Context) {
 	}
 
 	var movie MovieInfo
-	result := config.DB.Table("movies").Select("id, title").Where("id = ?", movieID).First(&movie)
+	result := config.DB.Table("movies").Select("id, title").Where("id = ? AND deleted_at IS NULL", movieID).First(&movie)
 
 	if result.Error != nil {
 		fmt.Printf("[DEBUG] Error al verificar película %d: %v\n", movieID, result.Error)
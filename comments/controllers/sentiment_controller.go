@@ -2,7 +2,9 @@ package controllers
 
 import (
 	"cine_conecta_backend/auth/utils"
+	"cine_conecta_backend/comments/models"
 	"cine_conecta_backend/comments/services"
+	movieServices "cine_conecta_backend/movies/services"
 	"net/http"
 	"os"
 	"strconv"
@@ -250,28 +252,7 @@ func GetPublicMovieCommentsByName(c *gin.Context) {
 		return
 	}
 
-	// Añadir información enriquecida de sentimiento para cada comentario
-	// y limitar información del usuario a solo ID y nombre
-	var enhancedComments []gin.H
-	for _, comment := range comments {
-		enhancedComments = append(enhancedComments, gin.H{
-			"id":             comment.ID,
-			"user_id":        comment.UserID,
-			"movie_id":       comment.MovieID,
-			"content":        comment.Content,
-			"created_at":     comment.CreatedAt,
-			"sentiment":      comment.Sentiment,
-			"sentiment_text": getSentimentText(string(comment.Sentiment)),
-			"rating":         comment.SentimentScore,
-			"rating_text":    getPuntuacionTexto(comment.SentimentScore),
-			"user": gin.H{
-				"id":   comment.User.ID,
-				"name": comment.User.Name,
-			},
-		})
-	}
-
-	c.JSON(http.StatusOK, enhancedComments)
+	c.JSON(http.StatusOK, publicComments(comments))
 }
 
 // GetPublicMovieSentimentByName obtiene el sentimiento de una película por su nombre sin requerir autenticación
@@ -304,3 +285,98 @@ func GetPublicMovieSentimentByName(c *gin.Context) {
 		"rating_text":    getPuntuacionTexto(score),
 	})
 }
+
+// GetPublicMovieCommentsBySlug obtiene los comentarios de una película por su slug sin requerir autenticación
+// GET /api/movies/by-slug/:slug/comments
+func GetPublicMovieCommentsBySlug(c *gin.Context) {
+	movie, redirected, err := movieServices.ResolveMovieSlug(c.Param("slug"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Película no encontrada")
+		return
+	}
+	if !movieVisible(c, movie.ID) {
+		utils.ErrorResponse(c, http.StatusNotFound, "Película no encontrada")
+		return
+	}
+	if redirected {
+		c.Redirect(http.StatusMovedPermanently, "/api/movies/by-slug/"+movie.Slug+"/comments")
+		return
+	}
+
+	comments, err := services.GetCommentsByMovie(movie.ID)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Error al obtener comentarios")
+		return
+	}
+
+	c.JSON(http.StatusOK, publicComments(comments))
+}
+
+// GetPublicMovieSentimentBySlug obtiene el sentimiento de una película por su slug sin requerir autenticación
+// GET /api/movies/by-slug/:slug/sentiment
+func GetPublicMovieSentimentBySlug(c *gin.Context) {
+	movie, redirected, err := movieServices.ResolveMovieSlug(c.Param("slug"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Película no encontrada")
+		return
+	}
+	if !movieVisible(c, movie.ID) {
+		utils.ErrorResponse(c, http.StatusNotFound, "Película no encontrada")
+		return
+	}
+	if redirected {
+		c.Redirect(http.StatusMovedPermanently, "/api/movies/by-slug/"+movie.Slug+"/sentiment")
+		return
+	}
+
+	sentiment, score, err := services.GetMovieSentiment(movie.ID)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Error al obtener sentimiento")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"movie_id":       movie.ID,
+		"movie_name":     movie.Title,
+		"slug":           movie.Slug,
+		"sentiment":      sentiment,
+		"sentiment_text": getSentimentText(string(sentiment)),
+		"rating":         score,
+		"rating_text":    getPuntuacionTexto(score),
+	})
+}
+
+// publicComments añade información enriquecida de sentimiento para cada comentario
+// y limita la información del usuario a solo ID y nombre
+func publicComments(comments []models.Comment) []gin.H {
+	var enhancedComments []gin.H
+	for _, comment := range comments {
+		enhancedComments = append(enhancedComments, gin.H{
+			"id":             comment.ID,
+			"user_id":        comment.UserID,
+			"movie_id":       comment.MovieID,
+			"content":        comment.Content,
+			"created_at":     comment.CreatedAt,
+			"sentiment":      comment.Sentiment,
+			"sentiment_text": getSentimentText(string(comment.Sentiment)),
+			"rating":         comment.SentimentScore,
+			"rating_text":    getPuntuacionTexto(comment.SentimentScore),
+			"user": gin.H{
+				"id":   comment.User.ID,
+				"name": comment.User.Name,
+			},
+		})
+	}
+
+	return enhancedComments
+}
+
+// movieVisible indica si la película es apta para el usuario con sesión; sin sesión no hay restricciones
+func movieVisible(c *gin.Context, movieID uint) bool {
+	claims, exists := c.Get("claims")
+	if !exists {
+		return true
+	}
+	userClaims := claims.(*utils.Claims)
+	return movieServices.IsMovieAllowed(movieServices.GetContentRestrictions(userClaims.UserID, userClaims.Role), movieID)
+}
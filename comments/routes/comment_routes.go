@@ -28,7 +28,7 @@ func RegisterCommentRoutes(r *gin.Engine) {
 		comments.POST("/update-sentiments", middlewares.AdminRequired(), controllers.UpdateAllSentiments)
 
 		// Ruta para actualizar todos los ratings de películas (sólo admin)
-		comments.POST("/update-ratings", middlewares.AuthRequired(), controllers.UpdateAllMovieRatings)
+		comments.POST("/update-ratings", middlewares.AdminRequired(), controllers.UpdateAllMovieRatings)
 
 		// Rutas para configuración del análisis de sentimientos (sólo admin)
 		comments.GET("/settings", middlewares.AdminRequired(), controllers.GetSentimentSettings)
@@ -46,6 +46,10 @@ func RegisterCommentRoutes(r *gin.Engine) {
 		// Rutas públicas sin autenticación
 		moviesByName.GET("/public-comments/:name", controllers.GetPublicMovieCommentsByName)
 		moviesByName.GET("/public-sentiment/:name", controllers.GetPublicMovieSentimentByName)
+
+		// Variantes por slug, estables frente a títulos repetidos o con caracteres especiales
+		moviesByName.GET("/by-slug/:slug/comments", middlewares.OptionalAuth(), controllers.GetPublicMovieCommentsBySlug)
+		moviesByName.GET("/by-slug/:slug/sentiment", middlewares.OptionalAuth(), controllers.GetPublicMovieSentimentBySlug)
 	}
 
 	// Rutas para película-comentarios por ID (protegidas)
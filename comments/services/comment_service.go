@@ -3,79 +3,14 @@ package services
 import (
 	"cine_conecta_backend/comments/models"
 	"cine_conecta_backend/config"
+	"cine_conecta_backend/movies/counters"
 	movieModels "cine_conecta_backend/movies/models"
 	"errors"
 	"fmt"
-)
-
-// UpdateMovieRating actualiza el rating de una película basado en los comentarios
-// Esta función se mantiene por compatibilidad pero ya no es necesario llamarla
-// directamente ya que los ratings se actualizan automáticamente al consultar las películas
-func UpdateMovieRating(movieID uint) error {
-	fmt.Printf("[DEBUG-SERVICE] Actualizando rating de la película ID=%d\n", movieID)
-	return updateMovieRatingAfterComment(movieID)
-}
-
-// updateAllMoviesRatings actualiza los ratings de todas las películas
-func updateAllMoviesRatings() error {
-	// Obtener todas las películas
-	var movies []movieModels.Movie
-	if err := config.DB.Find(&movies).Error; err != nil {
-		return fmt.Errorf("error al obtener películas: %w", err)
-	}
 
-	fmt.Printf("[DEBUG-SERVICE] Actualizando ratings para %d películas\n", len(movies))
-
-	// Actualizar el rating de cada película
-	for _, movie := range movies {
-		if err := updateMovieRatingAfterComment(movie.ID); err != nil {
-			fmt.Printf("[DEBUG-SERVICE] Error al actualizar rating de película %d: %v\n", movie.ID, err)
-			// Continuamos con la siguiente película en caso de error
-			continue
-		}
-	}
-
-	return nil
-}
-
-// updateMovieRatingAfterComment actualiza el rating de una película basado en sus comentarios
-func updateMovieRatingAfterComment(movieID uint) error {
-	// Verificar si la película existe
-	var movie movieModels.Movie
-	if err := config.DB.First(&movie, movieID).Error; err != nil {
-		return fmt.Errorf("película no encontrada: %w", err)
-	}
-
-	// Obtener todos los comentarios de la película
-	var comments []models.Comment
-	if err := config.DB.Where("movie_id = ?", movieID).Find(&comments).Error; err != nil {
-		return fmt.Errorf("error al obtener comentarios: %w", err)
-	}
-
-	// Calcular el nuevo rating basado en el promedio de las puntuaciones de sentimientos
-	var newRating float32
-	if len(comments) > 0 {
-		var totalScore float64
-		for _, comment := range comments {
-			totalScore += comment.SentimentScore
-		}
-		newRating = float32(totalScore / float64(len(comments)))
-		fmt.Printf("[DEBUG-SERVICE] Nuevo rating calculado para película %d: %.2f (basado en %d comentarios)\n",
-			movieID, newRating, len(comments))
-	} else {
-		// Si no hay comentarios, dejar el rating en 0
-		newRating = 0
-		fmt.Printf("[DEBUG-SERVICE] No hay comentarios para la película %d, rating establecido a 0\n", movieID)
-	}
-
-	// Actualizar el rating de la película
-	if err := config.DB.Model(&movieModels.Movie{}).Where("id = ?", movieID).Update("rating", newRating).Error; err != nil {
-		return fmt.Errorf("error al actualizar rating: %w", err)
-	}
-
-	fmt.Printf("[DEBUG-SERVICE] Rating de película %d actualizado exitosamente a %.2f\n", movieID, newRating)
-	return nil
-}
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
 
 func CreateComment(c *models.Comment) error {
 	fmt.Printf("[DEBUG-SERVICE] Creando comentario: UserID=%d, MovieID=%d, Content=%s\n", c.UserID, c.MovieID, c.Content)
@@ -102,34 +37,37 @@ func CreateComment(c *models.Comment) error {
 	c.Sentiment = sentiment
 	c.SentimentScore = score
 
-	// Crear el comentario en la base de datos
+	// Crear el comentario y actualizar los contadores de la película en la misma transacción
 	fmt.Println("[DEBUG-SERVICE] Guardando comentario en la base de datos")
-	err = config.DB.Create(c).Error
-	if err != nil {
+	tx := config.DB.Begin()
+	if err := tx.Create(c).Error; err != nil {
+		tx.Rollback()
 		fmt.Printf("[DEBUG-SERVICE] Error al guardar en la base de datos: %v\n", err)
 		return fmt.Errorf("error al guardar en la base de datos: %w", err)
 	}
-
-	fmt.Printf("[DEBUG-SERVICE] Comentario guardado exitosamente con ID: %d\n", c.ID)
-
-	// Actualizar el rating de la película basado en los nuevos comentarios
-	if err := updateMovieRatingAfterComment(c.MovieID); err != nil {
-		fmt.Printf("[DEBUG-SERVICE] Error al actualizar rating de película %d: %v\n", c.MovieID, err)
-		// No retornamos el error para no afectar la creación del comentario
+	if err := counters.CommentAdded(tx, c.MovieID, c.Sentiment, c.SentimentScore); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("error al actualizar los contadores de la película: %w", err)
+	}
+	if err := tx.Commit().Error; err != nil {
+		return fmt.Errorf("error al guardar en la base de datos: %w", err)
 	}
 
+	fmt.Printf("[DEBUG-SERVICE] Comentario guardado exitosamente con ID: %d\n", c.ID)
 	return nil
 }
 
 func GetComments() ([]models.Comment, error) {
 	var list []models.Comment
-	err := config.DB.Preload("User").Preload("Movie").Find(&list).Error
+	err := config.DB.Where("movie_id IN (" + movieModels.ActiveMovieIDs + ")").
+		Preload("User").Preload("Movie").Find(&list).Error
 	return list, err
 }
 
 func GetCommentByID(id uint) (models.Comment, error) {
 	var c models.Comment
-	err := config.DB.Preload("User").Preload("Movie").First(&c, id).Error
+	err := config.DB.Where("movie_id IN ("+movieModels.ActiveMovieIDs+")").
+		Preload("User").Preload("Movie").First(&c, id).Error
 	return c, err
 }
 
@@ -147,47 +85,46 @@ func UpdateComment(c *models.Comment) error {
 	c.Sentiment = sentiment
 	c.SentimentScore = score
 
-	err = config.DB.Save(c).Error
-	if err != nil {
+	tx := config.DB.Begin()
+	if err := saveCommentSentiment(tx, c); err != nil {
+		tx.Rollback()
 		return err
 	}
 
-	// Actualizar el rating de la película basado en los comentarios actualizados
-	if err := updateMovieRatingAfterComment(c.MovieID); err != nil {
-		fmt.Printf("[DEBUG-SERVICE] Error al actualizar rating de película %d: %v\n", c.MovieID, err)
-		// No retornamos el error para no afectar la actualización del comentario
-	}
-
-	return nil
+	return tx.Commit().Error
 }
 
 func DeleteComment(id uint) error {
-	// Obtener el comentario primero para conocer la película asociada
+	// Leer el comentario bloqueado para que otra eliminación simultánea espere y no lo descuente dos veces
+	tx := config.DB.Begin()
 	var comment models.Comment
-	if err := config.DB.First(&comment, id).Error; err != nil {
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&comment, id).Error; err != nil {
+		tx.Rollback()
 		return err
 	}
 
-	movieID := comment.MovieID
-
-	// Eliminar el comentario
-	if err := config.DB.Delete(&models.Comment{}, id).Error; err != nil {
-		return err
+	// Eliminar el comentario y descontarlo de los contadores de la película solo si se eliminó aquí
+	result := tx.Delete(&models.Comment{}, id)
+	if result.Error != nil {
+		tx.Rollback()
+		return result.Error
 	}
-
-	// Actualizar el rating de la película tras eliminar el comentario
-	if err := updateMovieRatingAfterComment(movieID); err != nil {
-		fmt.Printf("[DEBUG-SERVICE] Error al actualizar rating de película %d: %v\n", movieID, err)
-		// No retornamos el error para no afectar la eliminación del comentario
+	if result.RowsAffected != 1 {
+		tx.Rollback()
+		return gorm.ErrRecordNotFound
+	}
+	if err := counters.CommentRemoved(tx, comment.MovieID, comment.Sentiment, comment.SentimentScore); err != nil {
+		tx.Rollback()
+		return err
 	}
 
-	return nil
+	return tx.Commit().Error
 }
 
 // GetCommentsByMovie obtiene todos los comentarios de una película
 func GetCommentsByMovie(movieID uint) ([]models.Comment, error) {
 	var comments []models.Comment
-	err := config.DB.Where("movie_id = ?", movieID).
+	err := config.DB.Where("movie_id = ? AND movie_id IN ("+movieModels.ActiveMovieIDs+")", movieID).
 		Preload("User").
 		Find(&comments).Error
 	return comments, err
@@ -223,7 +160,7 @@ func GetCommentsByMovieName(movieName string) ([]models.Comment, error) {
 // GetCommentsByUser obtiene todos los comentarios de un usuario
 func GetCommentsByUser(userID uint) ([]models.Comment, error) {
 	var comments []models.Comment
-	err := config.DB.Where("user_id = ?", userID).
+	err := config.DB.Where("user_id = ? AND movie_id IN ("+movieModels.ActiveMovieIDs+")", userID).
 		Preload("Movie").
 		Find(&comments).Error
 	return comments, err
@@ -238,7 +175,7 @@ func GetSentimentStats() (map[string]int, error) {
 	}
 
 	var comments []models.Comment
-	if err := config.DB.Find(&comments).Error; err != nil {
+	if err := config.DB.Where("movie_id IN (" + movieModels.ActiveMovieIDs + ")").Find(&comments).Error; err != nil {
 		return stats, err
 	}
 
@@ -282,7 +219,13 @@ func UpdateAllCommentSentiments() error {
 		comment.Sentiment = sentiment
 		comment.SentimentScore = score
 
-		if err := config.DB.Save(&comment).Error; err != nil {
+		tx := config.DB.Begin()
+		if err := saveCommentSentiment(tx, &comment); err != nil {
+			tx.Rollback()
+			fmt.Printf("[DEBUG-SERVICE] Error al guardar comentario %d: %v\n", comment.ID, err)
+			continue
+		}
+		if err := tx.Commit().Error; err != nil {
 			fmt.Printf("[DEBUG-SERVICE] Error al guardar comentario %d: %v\n", comment.ID, err)
 			continue
 		}
@@ -295,17 +238,50 @@ func UpdateAllCommentSentiments() error {
 
 // DeleteAllComments elimina todos los comentarios de la base de datos
 func DeleteAllComments() error {
-	// Usar eliminación en masa para mayor eficiencia
-	err := config.DB.Exec("DELETE FROM comments").Error
-	if err != nil {
+	// Usar eliminación en masa para mayor eficiencia y dejar a cero los contadores de las películas
+	tx := config.DB.Begin()
+	if err := tx.Exec("DELETE FROM comments").Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := counters.ResetAllComments(tx); err != nil {
+		tx.Rollback()
 		return err
 	}
 
-	return nil
+	return tx.Commit().Error
 }
 
-// UpdateAllMoviesRatings actualiza los ratings de todas las películas
-// Esta función es útil para actualizar todos los ratings manualmente si es necesario
+// UpdateAllMoviesRatings verifica los contadores y la valoración de todas las películas y repara los
+// que no coinciden con los comentarios y me gusta reales
 func UpdateAllMoviesRatings() error {
-	return updateAllMoviesRatings()
+	report, err := counters.Reconcile(config.DB, false)
+	if err != nil {
+		return err
+	}
+	if len(report.Errors) > 0 {
+		return fmt.Errorf("%d películas no se pudieron reparar", len(report.Errors))
+	}
+	return nil
+}
+
+// saveCommentSentiment guarda dentro de la transacción un comentario con su nuevo sentimiento y ajusta los
+// contadores de la película. El sentimiento anterior se lee con bloqueo para que dos ediciones simultáneas
+// no descuenten el mismo valor.
+func saveCommentSentiment(tx *gorm.DB, c *models.Comment) error {
+	var previous models.Comment
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id", "movie_id", "sentiment", "sentiment_score").
+		First(&previous, c.ID).Error
+	if err != nil {
+		return err
+	}
+
+	// Un comentario no cambia de película
+	c.MovieID = previous.MovieID
+
+	if err := tx.Save(c).Error; err != nil {
+		return err
+	}
+	return counters.CommentChanged(tx, c.MovieID, previous.Sentiment, previous.SentimentScore, c.Sentiment, c.SentimentScore)
 }
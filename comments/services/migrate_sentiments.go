@@ -98,7 +98,14 @@ func RecomputeAllSentiments() (*MigrationStats, error) {
 		stats.ScoreRanges[scoreRange]++
 
 		// Guardar en la base de datos
-		if err := config.DB.Save(&comments[i]).Error; err != nil {
+		tx := config.DB.Begin()
+		err := saveCommentSentiment(tx, &comments[i])
+		if err != nil {
+			tx.Rollback()
+		} else {
+			err = tx.Commit().Error
+		}
+		if err != nil {
 			stats.Failed++
 			stats.FailedIds = append(stats.FailedIds, comments[i].ID)
 			fmt.Printf("Error guardando comentario ID %d: %v\n", comments[i].ID, err)
@@ -3,6 +3,7 @@ package services
 import (
 	"cine_conecta_backend/comments/models"
 	"cine_conecta_backend/config"
+	movieModels "cine_conecta_backend/movies/models"
 	"encoding/json"
 	"errors"
 	"time"
@@ -51,6 +52,10 @@ func GetRecommendationDatasets(userID uint) ([]models.RecommendationDataset, err
 		if err := json.Unmarshal([]byte(datasets[i].RecommendationsJSON), &recommendations); err != nil {
 			return nil, err
 		}
+		recommendations, err := withoutTrashedMovies(recommendations)
+		if err != nil {
+			return nil, err
+		}
 		datasets[i].Recommendations = recommendations
 	}
 
@@ -69,6 +74,10 @@ func GetRecommendationDatasetByID(id uint) (models.RecommendationDataset, error)
 	if err := json.Unmarshal([]byte(dataset.RecommendationsJSON), &recommendations); err != nil {
 		return dataset, err
 	}
+	recommendations, err := withoutTrashedMovies(recommendations)
+	if err != nil {
+		return dataset, err
+	}
 	dataset.Recommendations = recommendations
 
 	return dataset, nil
@@ -84,3 +93,32 @@ func DeleteRecommendationDataset(id uint, userID uint) error {
 
 	return config.DB.Delete(&dataset).Error
 }
+
+// withoutTrashedMovies descarta las recomendaciones de películas que están en la papelera
+func withoutTrashedMovies(recommendations []models.RecommendationItem) ([]models.RecommendationItem, error) {
+	if len(recommendations) == 0 {
+		return recommendations, nil
+	}
+
+	ids := make([]uint, 0, len(recommendations))
+	for _, item := range recommendations {
+		ids = append(ids, item.MovieID)
+	}
+
+	var active []uint
+	if err := config.DB.Model(&movieModels.Movie{}).Where("id IN ?", ids).Pluck("id", &active).Error; err != nil {
+		return nil, err
+	}
+	activeSet := make(map[uint]struct{}, len(active))
+	for _, id := range active {
+		activeSet[id] = struct{}{}
+	}
+
+	filtered := make([]models.RecommendationItem, 0, len(recommendations))
+	for _, item := range recommendations {
+		if _, ok := activeSet[item.MovieID]; ok {
+			filtered = append(filtered, item)
+		}
+	}
+	return filtered, nil
+}
@@ -26,7 +26,7 @@ func GetRecommendationsForUser(userID uint, limit int) ([]RecommendationResponse
 	// Obtener todos los comentarios del usuario (independientemente del score)
 	var userComments []models.Comment
 	if err := config.DB.
-		Where("user_id = ?", userID).
+		Where("user_id = ? AND movie_id IN ("+movieModels.ActiveMovieIDs+")", userID).
 		Preload("Movie").
 		Find(&userComments).Error; err != nil {
 		return nil, err
@@ -152,19 +152,19 @@ func scoreToType(r float64) models.SentimentType {
 ------------------------------------------------------------
 */
 func GetMovieSentiment(movieID uint) (models.SentimentType, float64, error) {
-	var comments []models.Comment
-	if err := config.DB.Where("movie_id = ?", movieID).Find(&comments).Error; err != nil {
+	// Se leen los contadores de la película en lugar de recorrer todos sus comentarios
+	var counts struct {
+		CommentCount int64
+		AvgScore     float64
+	}
+	if err := config.DB.Raw("SELECT comment_count, avg_score FROM movies WHERE id = ?", movieID).
+		Scan(&counts).Error; err != nil {
 		return models.SentimentNeutral, 3.0, err
 	}
-	if len(comments) == 0 {
+	if counts.CommentCount == 0 {
 		return models.SentimentNeutral, 3.0, nil
 	}
-	var sum float64
-	for _, c := range comments {
-		sum += c.SentimentScore
-	}
-	avg := sum / float64(len(comments))
-	return scoreToType(avg), avg, nil
+	return scoreToType(counts.AvgScore), counts.AvgScore, nil
 }
 
 // GetMovieSentimentByName obtiene el sentimiento promedio para una película por su nombre
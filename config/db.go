@@ -2,8 +2,13 @@ package config
 
 import (
 	authModels "cine_conecta_backend/auth/models"
+	cinemaModels "cine_conecta_backend/cinemas/models"
 	commentModels "cine_conecta_backend/comments/models"
+	listModels "cine_conecta_backend/lists/models"
+	"cine_conecta_backend/movies/counters"
 	movieModels "cine_conecta_backend/movies/models"
+	"cine_conecta_backend/movies/people"
+	"cine_conecta_backend/movies/slugs"
 	"fmt"
 	"log"
 	"os"
@@ -80,11 +85,47 @@ func ConnectDB() {
 	}
 
 	fmt.Println("✅ Conectado a PostgreSQL correctamente")
+
+	// Los contadores de las películas se crean a cero: si todavía no existen se rellenan tras migrar
+	backfillCounters := !db.Migrator().HasColumn(&movieModels.Movie{}, "comment_count")
+	// Igual con los slugs: las películas existentes se crean sin slug y se rellenan tras crear el índice
+	backfillSlugs := !db.Migrator().HasColumn(&movieModels.Movie{}, "slug")
+	// Y con los directores: al crear la tabla de personas se convierte el campo director en créditos
+	backfillDirectors := !db.Migrator().HasTable(&movieModels.Person{})
+
 	db.AutoMigrate(
 		&authModels.User{},
 		&movieModels.Movie{},
 		&movieModels.Genre{},
 		&movieModels.Like{},
+		&movieModels.Person{},
+		&movieModels.MovieCredit{},
+		&movieModels.MovieMedia{},
+		&movieModels.WatchlistItem{},
+		&movieModels.DiaryEntry{},
+		&movieModels.MovieTranslation{},
+		&movieModels.MovieCertification{},
+		&movieModels.MovieRevision{},
+		&movieModels.MovieSlugRedirect{},
+		&movieModels.MovieViewStat{},
+		&movieModels.TrendingSnapshot{},
+		&movieModels.TrendingSnapshotEntry{},
+		&movieModels.MovieSimilarity{},
+		&movieModels.FactorModel{},
+		&movieModels.UserFactor{},
+		&movieModels.MovieFactor{},
+		&movieModels.Collection{},
+		&movieModels.CollectionMovie{},
+		&cinemaModels.Cinema{},
+		&cinemaModels.Screen{},
+		&cinemaModels.Showtime{},
+		&cinemaModels.Seat{},
+		&cinemaModels.Booking{},
+		&cinemaModels.SeatReservation{},
+		&listModels.MovieList{},
+		&listModels.MovieListItem{},
+		&listModels.ListLike{},
+		&listModels.ListCollaborator{},
 		&commentModels.Comment{},
 		&commentModels.RecommendationDataset{})
 
@@ -94,6 +135,73 @@ func ConnectDB() {
 	// Crear índice único para asegurar que un usuario solo pueda dar me gusta una vez por película
 	db.Exec("CREATE UNIQUE INDEX IF NOT EXISTS idx_movie_likes_user_movie ON movie_likes (user_id, movie_id)")
 
+	// Crear índice único para que una película aparezca una sola vez en la lista de pendientes de cada usuario
+	db.Exec("CREATE UNIQUE INDEX IF NOT EXISTS idx_watchlist_items_user_movie ON watchlist_items (user_id, movie_id)")
+
+	// Crear índice único para que cada película tenga una sola traducción por idioma
+	db.Exec("CREATE UNIQUE INDEX IF NOT EXISTS idx_movie_translations_movie_locale ON movie_translations (movie_id, locale)")
+
+	// Crear índice único para que cada película tenga una sola clasificación por país
+	db.Exec("CREATE UNIQUE INDEX IF NOT EXISTS idx_movie_certifications_movie_country ON movie_certifications (movie_id, country)")
+
+	// Crear índice único para que una película aparezca una sola vez en cada colección
+	db.Exec("CREATE UNIQUE INDEX IF NOT EXISTS idx_collection_movies_unique ON collection_movies (collection_id, movie_id)")
+
+	// Crear índices únicos para que una película aparezca una vez por lista, y un usuario dé un solo me gusta
+	// o reciba una sola invitación por lista
+	db.Exec("CREATE UNIQUE INDEX IF NOT EXISTS idx_movie_list_items_list_movie ON movie_list_items (list_id, movie_id)")
+	db.Exec("CREATE UNIQUE INDEX IF NOT EXISTS idx_list_likes_list_user ON list_likes (list_id, user_id)")
+	db.Exec("CREATE UNIQUE INDEX IF NOT EXISTS idx_list_collaborators_list_user ON list_collaborators (list_id, user_id)")
+
+	// Crear índice único parcial para el identificador externo de las películas importadas
+	db.Exec("CREATE UNIQUE INDEX IF NOT EXISTS idx_movies_external_id ON movies (external_id) WHERE external_id <> ''")
+
+	// Crear índices únicos para los slugs de las películas y los slugs antiguos que redirigen al actual
+	db.Exec("CREATE UNIQUE INDEX IF NOT EXISTS idx_movies_slug ON movies (slug) WHERE slug <> ''")
+	db.Exec("CREATE UNIQUE INDEX IF NOT EXISTS idx_movie_slug_redirects_slug ON movie_slug_redirects (slug)")
+
+	// Crear índice único para acumular las visitas de cada película en una sola fila por hora
+	db.Exec("CREATE UNIQUE INDEX IF NOT EXISTS idx_movie_view_stats_movie_hour ON movie_view_stats (movie_id, hour)")
+
+	// Crear índice único para evitar créditos duplicados de una persona con el mismo rol y personaje
+	db.Exec("CREATE UNIQUE INDEX IF NOT EXISTS idx_movie_credits_unique ON movie_credits (movie_id, person_id, role, character)")
+
+	// Crear índice único para que cada butaca aparezca una sola vez en el mapa de la sala
+	db.Exec("CREATE UNIQUE INDEX IF NOT EXISTS idx_seats_position ON seats (screen_id, seat_row, number)")
+
+	// Crear índice único parcial: una butaca solo puede estar retenida o vendida una vez por función
+	db.Exec("CREATE UNIQUE INDEX IF NOT EXISTS idx_seat_reservations_active ON seat_reservations (showtime_id, seat_id) WHERE status <> 'cancelled'")
+
+	// Crear índice único para los códigos de entrada
+	db.Exec("CREATE UNIQUE INDEX IF NOT EXISTS idx_bookings_ticket_code ON bookings (ticket_code)")
+
+	if backfillCounters {
+		report, err := counters.Reconcile(db, false)
+		if err != nil {
+			log.Printf("⚠️ [DB] No se pudieron rellenar los contadores de las películas (ejecute go run . -reconcile-counters): %v", err)
+		} else {
+			log.Printf("✅ [DB] Contadores rellenados: %d películas reparadas", report.Repaired)
+		}
+	}
+
+	if backfillDirectors {
+		migrated, err := people.MigrateDirectors(db)
+		if err != nil {
+			log.Printf("⚠️ [DB] No se pudieron migrar los directores a personas (ejecute POST /api/people/migrate-directors): %v", err)
+		} else {
+			log.Printf("✅ [DB] Directores migrados para %d películas", migrated)
+		}
+	}
+
+	if backfillSlugs {
+		migrated, err := slugs.Migrate(db)
+		if err != nil {
+			log.Printf("⚠️ [DB] No se pudieron generar los slugs de las películas (ejecute POST /api/movies/migrate-slugs): %v", err)
+		} else {
+			log.Printf("✅ [DB] Slugs generados para %d películas", migrated)
+		}
+	}
+
 	DB = db
 }
 
@@ -0,0 +1,459 @@
+package controllers
+
+import (
+	"cine_conecta_backend/auth/utils"
+	"cine_conecta_backend/lists/models"
+	"cine_conecta_backend/lists/services"
+	movieServices "cine_conecta_backend/movies/services"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Estructura para recibir los datos de creación/actualización de listas
+type ListInput struct {
+	Title       *string                `json:"title"`
+	Description *string                `json:"description"`
+	Visibility  *models.ListVisibility `json:"visibility"` // public, unlisted o private
+}
+
+// Estructura para recibir una película de la lista
+type ListItemInput struct {
+	MovieID uint   `json:"movie_id"`
+	Notes   string `json:"notes"`
+}
+
+// SearchLists busca entre las listas públicas
+// GET /api/lists?q=&user_id=&movie_id=&sort=popular|recent|title&limit=&offset=
+func SearchLists(c *gin.Context) {
+	filter := services.ListFilter{
+		Query: c.Query("q"),
+		Sort:  c.Query("sort"),
+	}
+	if userID, err := strconv.ParseUint(c.Query("user_id"), 10, 32); err == nil {
+		filter.UserID = uint(userID)
+	}
+	if movieID, err := strconv.ParseUint(c.Query("movie_id"), 10, 32); err == nil {
+		filter.MovieID = uint(movieID)
+	}
+	filter.Limit, _ = strconv.Atoi(c.Query("limit"))
+	filter.Offset, _ = strconv.Atoi(c.Query("offset"))
+
+	lists, total, err := services.SearchPublicLists(filter)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"lists": lists,
+		"count": len(lists),
+		"total": total,
+	})
+}
+
+// GetMyLists devuelve las listas del usuario y aquellas en las que colabora
+// GET /api/lists/mine
+func GetMyLists(c *gin.Context) {
+	claims, _ := c.Get("claims")
+	userID := claims.(*utils.Claims).UserID
+
+	lists, err := services.GetUserLists(userID)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Error al obtener tus listas")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"lists": lists,
+		"count": len(lists),
+	})
+}
+
+// GetMyInvitations devuelve las invitaciones pendientes para colaborar en listas
+// GET /api/lists/invitations
+func GetMyInvitations(c *gin.Context) {
+	claims, _ := c.Get("claims")
+	userID := claims.(*utils.Claims).UserID
+
+	lists, err := services.GetPendingInvitations(userID)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Error al obtener las invitaciones")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"lists": lists,
+		"count": len(lists),
+	})
+}
+
+// GetList devuelve una lista con sus películas
+// GET /api/lists/:listId
+func GetList(c *gin.Context) {
+	listID, ok := parseListParam(c)
+	if !ok {
+		return
+	}
+
+	claims, _ := c.Get("claims")
+	userClaims := claims.(*utils.Claims)
+
+	restrictions := movieServices.GetContentRestrictions(userClaims.UserID, userClaims.Role)
+	list, err := services.GetListByID(listID, userClaims.UserID, userClaims.Role == "admin", restrictions)
+	if err != nil {
+		respondListError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, list)
+}
+
+// CreateList crea una nueva lista para el usuario
+// POST /api/lists
+func CreateList(c *gin.Context) {
+	var input ListInput
+	if err := c.ShouldBindJSON(&input); err != nil || input.Title == nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "El título de la lista es obligatorio")
+		return
+	}
+
+	claims, _ := c.Get("claims")
+	userID := claims.(*utils.Claims).UserID
+
+	list := models.MovieList{UserID: userID, Title: *input.Title}
+	if input.Description != nil {
+		list.Description = *input.Description
+	}
+	if input.Visibility != nil {
+		list.Visibility = *input.Visibility
+	}
+
+	if err := services.CreateList(&list); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	list.CanEdit = true
+	c.JSON(http.StatusCreated, list)
+}
+
+// UpdateList cambia el título, la descripción o la visibilidad de una lista
+// PUT /api/lists/:listId (solo el dueño)
+func UpdateList(c *gin.Context) {
+	listID, ok := parseListParam(c)
+	if !ok {
+		return
+	}
+
+	var input ListInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Datos inválidos")
+		return
+	}
+
+	claims, _ := c.Get("claims")
+	userID := claims.(*utils.Claims).UserID
+
+	list, err := services.UpdateList(listID, userID, input.Title, input.Description, input.Visibility)
+	if err != nil {
+		respondListError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, list)
+}
+
+// DeleteList elimina una lista
+// DELETE /api/lists/:listId (dueño o admin)
+func DeleteList(c *gin.Context) {
+	listID, ok := parseListParam(c)
+	if !ok {
+		return
+	}
+
+	claims, _ := c.Get("claims")
+	userClaims := claims.(*utils.Claims)
+
+	if err := services.DeleteList(listID, userClaims.UserID, userClaims.Role == "admin"); err != nil {
+		respondListError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Lista eliminada correctamente"})
+}
+
+// AddListItem añade una película a la lista
+// POST /api/lists/:listId/items (dueño o colaboradores)
+func AddListItem(c *gin.Context) {
+	listID, ok := parseListParam(c)
+	if !ok {
+		return
+	}
+
+	var input ListItemInput
+	if err := c.ShouldBindJSON(&input); err != nil || input.MovieID == 0 {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Se requiere el movie_id de la película")
+		return
+	}
+
+	claims, _ := c.Get("claims")
+	userID := claims.(*utils.Claims).UserID
+
+	item, err := services.AddListItem(listID, userID, input.MovieID, input.Notes)
+	if err != nil {
+		respondListError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, item)
+}
+
+// UpdateListItem cambia las notas de una película de la lista
+// PUT /api/lists/:listId/items/:movieId (dueño o colaboradores)
+func UpdateListItem(c *gin.Context) {
+	listID, movieID, ok := parseListItemParams(c)
+	if !ok {
+		return
+	}
+
+	var input ListItemInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Datos inválidos")
+		return
+	}
+
+	claims, _ := c.Get("claims")
+	userID := claims.(*utils.Claims).UserID
+
+	item, err := services.UpdateListItem(listID, userID, movieID, input.Notes)
+	if err != nil {
+		respondListError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, item)
+}
+
+// RemoveListItem quita una película de la lista
+// DELETE /api/lists/:listId/items/:movieId (dueño o colaboradores)
+func RemoveListItem(c *gin.Context) {
+	listID, movieID, ok := parseListItemParams(c)
+	if !ok {
+		return
+	}
+
+	claims, _ := c.Get("claims")
+	userID := claims.(*utils.Claims).UserID
+
+	if err := services.RemoveListItem(listID, userID, movieID); err != nil {
+		respondListError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Película quitada de la lista"})
+}
+
+// ReorderListItems cambia el orden de las películas de la lista
+// POST /api/lists/:listId/items/reorder (dueño o colaboradores)
+func ReorderListItems(c *gin.Context) {
+	listID, ok := parseListParam(c)
+	if !ok {
+		return
+	}
+
+	var input struct {
+		MovieIDs []uint `json:"movie_ids" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Se requiere la lista ordenada de movie_ids")
+		return
+	}
+
+	claims, _ := c.Get("claims")
+	userClaims := claims.(*utils.Claims)
+
+	if err := services.ReorderListItems(listID, userClaims.UserID, input.MovieIDs); err != nil {
+		respondListError(c, err)
+		return
+	}
+
+	restrictions := movieServices.GetContentRestrictions(userClaims.UserID, userClaims.Role)
+	list, _ := services.GetListByID(listID, userClaims.UserID, userClaims.Role == "admin", restrictions)
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Orden actualizado correctamente",
+		"list":    list,
+	})
+}
+
+// LikeList da "me gusta" a una lista
+// POST /api/lists/:listId/like
+func LikeList(c *gin.Context) {
+	listID, ok := parseListParam(c)
+	if !ok {
+		return
+	}
+
+	claims, _ := c.Get("claims")
+	userID := claims.(*utils.Claims).UserID
+
+	if err := services.LikeList(listID, userID); err != nil {
+		respondListError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Has dado me gusta a la lista"})
+}
+
+// UnlikeList quita el "me gusta" de una lista
+// DELETE /api/lists/:listId/like
+func UnlikeList(c *gin.Context) {
+	listID, ok := parseListParam(c)
+	if !ok {
+		return
+	}
+
+	claims, _ := c.Get("claims")
+	userID := claims.(*utils.Claims).UserID
+
+	if err := services.UnlikeList(listID, userID); err != nil {
+		respondListError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Has quitado tu me gusta de la lista"})
+}
+
+// InviteCollaborator invita a un usuario a editar la lista
+// POST /api/lists/:listId/collaborators (solo el dueño)
+func InviteCollaborator(c *gin.Context) {
+	listID, ok := parseListParam(c)
+	if !ok {
+		return
+	}
+
+	var input struct {
+		Email string `json:"email" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Se requiere el email del usuario")
+		return
+	}
+
+	claims, _ := c.Get("claims")
+	userID := claims.(*utils.Claims).UserID
+
+	collaborator, err := services.InviteCollaborator(listID, userID, input.Email)
+	if err != nil {
+		if err.Error() == "usuario no encontrado" {
+			utils.ErrorResponse(c, http.StatusNotFound, "Usuario no encontrado")
+			return
+		}
+		respondListError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, collaborator)
+}
+
+// RemoveCollaborator retira a un colaborador (el dueño o el propio colaborador al salir)
+// DELETE /api/lists/:listId/collaborators/:userId
+func RemoveCollaborator(c *gin.Context) {
+	listID, ok := parseListParam(c)
+	if !ok {
+		return
+	}
+
+	collaboratorID, err := strconv.ParseUint(c.Param("userId"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "ID de usuario inválido")
+		return
+	}
+
+	claims, _ := c.Get("claims")
+	userID := claims.(*utils.Claims).UserID
+
+	if err := services.RemoveCollaborator(listID, userID, uint(collaboratorID)); err != nil {
+		respondListError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Colaborador retirado de la lista"})
+}
+
+// RespondInvitation acepta o rechaza la invitación a colaborar en una lista
+// POST /api/lists/:listId/invitation
+func RespondInvitation(c *gin.Context) {
+	listID, ok := parseListParam(c)
+	if !ok {
+		return
+	}
+
+	var input struct {
+		Accept *bool `json:"accept" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Indica si aceptas la invitación (accept)")
+		return
+	}
+
+	claims, _ := c.Get("claims")
+	userID := claims.(*utils.Claims).UserID
+
+	if err := services.RespondInvitation(listID, userID, *input.Accept); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.ErrorResponse(c, http.StatusNotFound, "No tienes invitaciones pendientes para esta lista")
+			return
+		}
+		utils.ErrorResponse(c, http.StatusInternalServerError, "No se pudo responder la invitación")
+		return
+	}
+
+	message := "Invitación rechazada"
+	if *input.Accept {
+		message = "Invitación aceptada; ya puedes editar la lista"
+	}
+	c.JSON(http.StatusOK, gin.H{"message": message})
+}
+
+// respondListError traduce los errores del servicio de listas a respuestas HTTP
+func respondListError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		utils.ErrorResponse(c, http.StatusNotFound, "Lista o película no encontrada")
+	case errors.Is(err, services.ErrListForbidden):
+		utils.ErrorResponse(c, http.StatusForbidden, "No tienes permiso para modificar esta lista")
+	case err.Error() == "película no encontrada":
+		utils.ErrorResponse(c, http.StatusNotFound, "Película no encontrada")
+	default:
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+	}
+}
+
+// parseListParam obtiene el identificador de la lista de la ruta
+func parseListParam(c *gin.Context) (uint, bool) {
+	listID, err := strconv.ParseUint(c.Param("listId"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "ID de lista inválido")
+		return 0, false
+	}
+	return uint(listID), true
+}
+
+// parseListItemParams obtiene los identificadores de la lista y de la película de la ruta
+func parseListItemParams(c *gin.Context) (uint, uint, bool) {
+	listID, ok := parseListParam(c)
+	if !ok {
+		return 0, 0, false
+	}
+
+	movieID, err := strconv.ParseUint(c.Param("movieId"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "ID de película inválido")
+		return 0, 0, false
+	}
+	return listID, uint(movieID), true
+}
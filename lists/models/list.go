@@ -0,0 +1,92 @@
+package models
+
+import (
+	movieModels "cine_conecta_backend/movies/models"
+	"time"
+)
+
+// ListVisibility indica quién puede ver una lista
+type ListVisibility string
+
+const (
+	ListPublic   ListVisibility = "public"   // Visible para todos y aparece en las búsquedas
+	ListUnlisted ListVisibility = "unlisted" // Visible con el enlace, pero no aparece en las búsquedas
+	ListPrivate  ListVisibility = "private"  // Solo para el dueño y los colaboradores
+)
+
+// IsValidListVisibility verifica si una visibilidad está entre las permitidas
+func IsValidListVisibility(visibility ListVisibility) bool {
+	switch visibility {
+	case ListPublic, ListUnlisted, ListPrivate:
+		return true
+	default:
+		return false
+	}
+}
+
+// MovieList representa una lista de películas creada por un usuario
+type MovieList struct {
+	ID          uint           `gorm:"primaryKey" json:"id"`
+	UserID      uint           `gorm:"not null;index" json:"user_id"`
+	Title       string         `gorm:"not null" json:"title"`
+	Description string         `gorm:"type:text" json:"description"`
+	Visibility  ListVisibility `gorm:"type:varchar(20);not null;default:'public';index" json:"visibility"`
+
+	Items         []MovieListItem    `gorm:"foreignKey:ListID" json:"items,omitempty"`
+	Collaborators []ListCollaborator `gorm:"foreignKey:ListID" json:"collaborators,omitempty"`
+
+	// Datos calculados en las consultas (no se guardan en la tabla)
+	OwnerName  string `gorm:"->;-:migration" json:"owner_name"`
+	ItemsCount int    `gorm:"->;-:migration" json:"items_count"`
+	LikesCount int    `gorm:"->;-:migration" json:"likes_count"`
+
+	// Datos relativos al usuario que consulta
+	LikedByMe bool `gorm:"-" json:"liked_by_me"`
+	CanEdit   bool `gorm:"-" json:"can_edit"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// MovieListItem representa una película dentro de una lista
+type MovieListItem struct {
+	ID       uint   `gorm:"primaryKey" json:"id"`
+	ListID   uint   `gorm:"not null;index" json:"list_id"`
+	MovieID  uint   `gorm:"not null" json:"movie_id"`
+	Position int    `gorm:"not null;default:0" json:"position"`
+	Notes    string `gorm:"type:text" json:"notes"`
+	AddedBy  uint   `json:"added_by"`
+
+	Movie *movieModels.Movie `gorm:"foreignKey:MovieID" json:"movie,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ListLike representa un "me gusta" de un usuario a una lista
+type ListLike struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	ListID    uint      `gorm:"not null;index" json:"list_id"`
+	UserID    uint      `gorm:"not null" json:"user_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Estados de una invitación para colaborar en una lista
+const (
+	CollaboratorPending  = "pending"
+	CollaboratorAccepted = "accepted"
+)
+
+// ListCollaborator representa a un usuario invitado a editar una lista
+type ListCollaborator struct {
+	ID        uint   `gorm:"primaryKey" json:"id"`
+	ListID    uint   `gorm:"not null;index" json:"list_id"`
+	UserID    uint   `gorm:"not null;index" json:"user_id"`
+	InvitedBy uint   `json:"invited_by"`
+	Status    string `gorm:"type:varchar(20);not null" json:"status"`
+
+	UserName string `gorm:"->;-:migration" json:"user_name"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
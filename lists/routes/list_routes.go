@@ -0,0 +1,39 @@
+package routes
+
+import (
+	"cine_conecta_backend/auth/middlewares"
+	"cine_conecta_backend/lists/controllers"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterListRoutes(r *gin.Engine) {
+	lists := r.Group("/api/lists")
+	{
+		// Búsqueda de listas públicas y listas del usuario
+		lists.GET("/", middlewares.AuthRequired(), controllers.SearchLists)
+		lists.GET("/mine", middlewares.AuthRequired(), controllers.GetMyLists)
+		lists.GET("/invitations", middlewares.AuthRequired(), controllers.GetMyInvitations)
+		lists.GET("/:listId", middlewares.AuthRequired(), controllers.GetList)
+
+		// Gestión de la lista (dueño)
+		lists.POST("/", middlewares.AuthRequired(), controllers.CreateList)
+		lists.PUT("/:listId", middlewares.AuthRequired(), controllers.UpdateList)
+		lists.DELETE("/:listId", middlewares.AuthRequired(), controllers.DeleteList)
+
+		// Películas de la lista (dueño y colaboradores)
+		lists.POST("/:listId/items", middlewares.AuthRequired(), controllers.AddListItem)
+		lists.POST("/:listId/items/reorder", middlewares.AuthRequired(), controllers.ReorderListItems)
+		lists.PUT("/:listId/items/:movieId", middlewares.AuthRequired(), controllers.UpdateListItem)
+		lists.DELETE("/:listId/items/:movieId", middlewares.AuthRequired(), controllers.RemoveListItem)
+
+		// Me gusta
+		lists.POST("/:listId/like", middlewares.AuthRequired(), controllers.LikeList)
+		lists.DELETE("/:listId/like", middlewares.AuthRequired(), controllers.UnlikeList)
+
+		// Colaboración
+		lists.POST("/:listId/collaborators", middlewares.AuthRequired(), controllers.InviteCollaborator)
+		lists.DELETE("/:listId/collaborators/:userId", middlewares.AuthRequired(), controllers.RemoveCollaborator)
+		lists.POST("/:listId/invitation", middlewares.AuthRequired(), controllers.RespondInvitation)
+	}
+}
@@ -0,0 +1,478 @@
+package services
+
+import (
+	authModels "cine_conecta_backend/auth/models"
+	"cine_conecta_backend/config"
+	"cine_conecta_backend/lists/models"
+	movieModels "cine_conecta_backend/movies/models"
+	movieServices "cine_conecta_backend/movies/services"
+	"errors"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// ErrListForbidden indica que el usuario no puede modificar la lista
+var ErrListForbidden = errors.New("no tienes permiso para modificar esta lista")
+
+// Límites de la paginación de listas
+const (
+	defaultListLimit = 20
+	maxListLimit     = 100
+)
+
+// ListFilter define los criterios de búsqueda de listas públicas
+type ListFilter struct {
+	Query   string // Texto a buscar en el título o la descripción
+	UserID  uint   // Listas de un usuario
+	MovieID uint   // Listas que contienen una película
+	Sort    string // popular, recent o title
+	Limit   int
+	Offset  int
+}
+
+// listQuery prepara la consulta de listas con el nombre del dueño y los contadores
+func listQuery() *gorm.DB {
+	return config.DB.Model(&models.MovieList{}).
+		Select(`movie_lists.*, users.name AS owner_name,
+			(SELECT COUNT(*) FROM movie_list_items WHERE movie_list_items.list_id = movie_lists.id
+				AND movie_list_items.movie_id IN (` + movieModels.ActiveMovieIDs + `)) AS items_count,
+			(SELECT COUNT(*) FROM list_likes WHERE list_likes.list_id = movie_lists.id) AS likes_count`).
+		Joins("LEFT JOIN users ON users.id = movie_lists.user_id")
+}
+
+// SearchPublicLists busca entre las listas públicas y devuelve también el total de resultados
+func SearchPublicLists(filter ListFilter) ([]models.MovieList, int64, error) {
+	var total int64
+	if err := applyListFilter(config.DB.Model(&models.MovieList{}), filter).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	query := applyListFilter(listQuery(), filter)
+	switch filter.Sort {
+	case "", "popular":
+		query = query.Order("likes_count DESC, movie_lists.updated_at DESC")
+	case "recent":
+		query = query.Order("movie_lists.updated_at DESC")
+	case "title":
+		query = query.Order("movie_lists.title ASC")
+	default:
+		return nil, 0, errors.New("orden no válido (popular, recent o title)")
+	}
+
+	if filter.Limit <= 0 || filter.Limit > maxListLimit {
+		filter.Limit = defaultListLimit
+	}
+	if filter.Offset < 0 {
+		filter.Offset = 0
+	}
+
+	var lists []models.MovieList
+	err := query.Order("movie_lists.id DESC").Limit(filter.Limit).Offset(filter.Offset).Find(&lists).Error
+	return lists, total, err
+}
+
+// applyListFilter aplica los filtros de búsqueda de listas públicas
+func applyListFilter(query *gorm.DB, filter ListFilter) *gorm.DB {
+	query = query.Where("movie_lists.visibility = ?", models.ListPublic)
+
+	if q := strings.TrimSpace(filter.Query); q != "" {
+		query = query.Where("movie_lists.title ILIKE ? OR movie_lists.description ILIKE ?", "%"+q+"%", "%"+q+"%")
+	}
+	if filter.UserID != 0 {
+		query = query.Where("movie_lists.user_id = ?", filter.UserID)
+	}
+	if filter.MovieID != 0 {
+		query = query.Where("movie_lists.id IN (?)",
+			config.DB.Model(&models.MovieListItem{}).Select("list_id").Where("movie_id = ?", filter.MovieID))
+	}
+	return query
+}
+
+// GetUserLists devuelve las listas de un usuario y aquellas en las que colabora
+func GetUserLists(userID uint) ([]models.MovieList, error) {
+	collaborating := config.DB.Model(&models.ListCollaborator{}).
+		Select("list_id").
+		Where("user_id = ? AND status = ?", userID, models.CollaboratorAccepted)
+
+	var lists []models.MovieList
+	err := listQuery().
+		Where("movie_lists.user_id = ? OR movie_lists.id IN (?)", userID, collaborating).
+		Order("movie_lists.updated_at DESC").
+		Find(&lists).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range lists {
+		lists[i].CanEdit = true
+	}
+	return lists, nil
+}
+
+// GetPendingInvitations devuelve las listas a las que el usuario fue invitado y aún no respondió
+func GetPendingInvitations(userID uint) ([]models.MovieList, error) {
+	pending := config.DB.Model(&models.ListCollaborator{}).
+		Select("list_id").
+		Where("user_id = ? AND status = ?", userID, models.CollaboratorPending)
+
+	var lists []models.MovieList
+	err := listQuery().Where("movie_lists.id IN (?)", pending).Order("movie_lists.id DESC").Find(&lists).Error
+	return lists, err
+}
+
+// GetListByID devuelve una lista con sus películas y colaboradores, sin las películas que no son aptas
+// para quien la consulta. Las listas privadas solo son visibles para el dueño, los colaboradores y los admin.
+func GetListByID(listID, viewerID uint, isAdmin bool, restrictions movieServices.ContentRestrictions) (*models.MovieList, error) {
+	allowed := config.DB.Model(&movieModels.Movie{}).Select("movies.id").Scopes(movieServices.ContentFilter(restrictions))
+
+	var list models.MovieList
+	err := listQuery().
+		Preload("Items", func(db *gorm.DB) *gorm.DB {
+			return db.Where("movie_id IN (?)", allowed).Order("position ASC, id ASC")
+		}).
+		Preload("Items.Movie").
+		Preload("Collaborators", func(db *gorm.DB) *gorm.DB {
+			return db.Select("list_collaborators.*, users.name AS user_name").
+				Joins("LEFT JOIN users ON users.id = list_collaborators.user_id").
+				Order("list_collaborators.id ASC")
+		}).
+		Where("movie_lists.id = ?", listID).
+		First(&list).Error
+	if err != nil {
+		return nil, err
+	}
+
+	member := isListMember(&list, viewerID)
+	if list.Visibility == models.ListPrivate && !member && !isAdmin {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	// Las invitaciones pendientes solo se muestran a quienes pueden editar
+	if !member {
+		list.Collaborators = nil
+	}
+
+	var liked int64
+	config.DB.Model(&models.ListLike{}).Where("list_id = ? AND user_id = ?", listID, viewerID).Count(&liked)
+	list.LikedByMe = liked > 0
+	list.CanEdit = member
+	return &list, nil
+}
+
+// CreateList crea una lista vacía para el usuario
+func CreateList(list *models.MovieList) error {
+	if err := validateList(list); err != nil {
+		return err
+	}
+	list.Items = nil
+	list.Collaborators = nil
+	return config.DB.Create(list).Error
+}
+
+// UpdateList cambia el título, la descripción o la visibilidad de una lista (solo el dueño)
+func UpdateList(listID, userID uint, title, description *string, visibility *models.ListVisibility) (*models.MovieList, error) {
+	var list models.MovieList
+	if err := config.DB.First(&list, listID).Error; err != nil {
+		return nil, err
+	}
+	if list.UserID != userID {
+		return nil, ErrListForbidden
+	}
+
+	if title != nil {
+		list.Title = *title
+	}
+	if description != nil {
+		list.Description = *description
+	}
+	if visibility != nil {
+		list.Visibility = *visibility
+	}
+	if err := validateList(&list); err != nil {
+		return nil, err
+	}
+
+	if err := config.DB.Save(&list).Error; err != nil {
+		return nil, err
+	}
+	return &list, nil
+}
+
+// DeleteList elimina una lista con sus películas, me gusta y colaboradores (dueño o admin)
+func DeleteList(listID, userID uint, isAdmin bool) error {
+	var list models.MovieList
+	if err := config.DB.First(&list, listID).Error; err != nil {
+		return err
+	}
+	if list.UserID != userID && !isAdmin {
+		return ErrListForbidden
+	}
+
+	tx := config.DB.Begin()
+	for _, model := range []interface{}{&models.MovieListItem{}, &models.ListLike{}, &models.ListCollaborator{}} {
+		if err := tx.Where("list_id = ?", listID).Delete(model).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	if err := tx.Delete(&list).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit().Error
+}
+
+// AddListItem añade una película al final de la lista
+func AddListItem(listID, userID, movieID uint, notes string) (*models.MovieListItem, error) {
+	if _, err := getEditableList(listID, userID); err != nil {
+		return nil, err
+	}
+	if err := config.DB.Select("id").First(&movieModels.Movie{}, movieID).Error; err != nil {
+		return nil, errors.New("película no encontrada")
+	}
+
+	var existing int64
+	config.DB.Model(&models.MovieListItem{}).Where("list_id = ? AND movie_id = ?", listID, movieID).Count(&existing)
+	if existing > 0 {
+		return nil, errors.New("la película ya está en la lista")
+	}
+
+	var maxPosition int
+	config.DB.Model(&models.MovieListItem{}).
+		Where("list_id = ?", listID).
+		Select("COALESCE(MAX(position), 0)").
+		Scan(&maxPosition)
+
+	item := models.MovieListItem{
+		ListID:   listID,
+		MovieID:  movieID,
+		Position: maxPosition + 1,
+		Notes:    strings.TrimSpace(notes),
+		AddedBy:  userID,
+	}
+	if err := config.DB.Create(&item).Error; err != nil {
+		return nil, errors.New("error al añadir la película a la lista")
+	}
+
+	touchList(listID)
+	return &item, nil
+}
+
+// UpdateListItem cambia las notas de una película de la lista
+func UpdateListItem(listID, userID, movieID uint, notes string) (*models.MovieListItem, error) {
+	if _, err := getEditableList(listID, userID); err != nil {
+		return nil, err
+	}
+
+	var item models.MovieListItem
+	if err := config.DB.Where("list_id = ? AND movie_id = ?", listID, movieID).First(&item).Error; err != nil {
+		return nil, err
+	}
+
+	item.Notes = strings.TrimSpace(notes)
+	if err := config.DB.Save(&item).Error; err != nil {
+		return nil, err
+	}
+
+	touchList(listID)
+	return &item, nil
+}
+
+// RemoveListItem quita una película de la lista
+func RemoveListItem(listID, userID, movieID uint) error {
+	if _, err := getEditableList(listID, userID); err != nil {
+		return err
+	}
+
+	result := config.DB.Where("list_id = ? AND movie_id = ?", listID, movieID).Delete(&models.MovieListItem{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	touchList(listID)
+	return nil
+}
+
+// ReorderListItems asigna posiciones según el orden de los IDs de película recibidos
+func ReorderListItems(listID, userID uint, movieIDs []uint) error {
+	if _, err := getEditableList(listID, userID); err != nil {
+		return err
+	}
+
+	tx := config.DB.Begin()
+	for i, movieID := range movieIDs {
+		result := tx.Model(&models.MovieListItem{}).
+			Where("list_id = ? AND movie_id = ?", listID, movieID).
+			Update("position", i+1)
+		if result.Error != nil {
+			tx.Rollback()
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			tx.Rollback()
+			return fmt.Errorf("la película %d no está en la lista", movieID)
+		}
+	}
+	if err := tx.Commit().Error; err != nil {
+		return err
+	}
+
+	touchList(listID)
+	return nil
+}
+
+// LikeList marca una lista visible con "me gusta"
+func LikeList(listID, userID uint) error {
+	list, err := GetListByID(listID, userID, false, movieServices.NoContentRestrictions)
+	if err != nil {
+		return err
+	}
+	if list.LikedByMe {
+		return errors.New("ya has dado me gusta a esta lista")
+	}
+
+	like := models.ListLike{ListID: listID, UserID: userID}
+	if err := config.DB.Create(&like).Error; err != nil {
+		return errors.New("error al dar me gusta a la lista")
+	}
+	return nil
+}
+
+// UnlikeList quita el "me gusta" de una lista
+func UnlikeList(listID, userID uint) error {
+	result := config.DB.Where("list_id = ? AND user_id = ?", listID, userID).Delete(&models.ListLike{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("no has dado me gusta a esta lista")
+	}
+	return nil
+}
+
+// InviteCollaborator invita a un usuario, por su email, a editar la lista (solo el dueño)
+func InviteCollaborator(listID, ownerID uint, email string) (*models.ListCollaborator, error) {
+	var list models.MovieList
+	if err := config.DB.First(&list, listID).Error; err != nil {
+		return nil, err
+	}
+	if list.UserID != ownerID {
+		return nil, ErrListForbidden
+	}
+
+	var user authModels.User
+	if err := config.DB.Select("id", "name").Where("LOWER(email) = LOWER(?)", strings.TrimSpace(email)).First(&user).Error; err != nil {
+		return nil, errors.New("usuario no encontrado")
+	}
+	if user.ID == ownerID {
+		return nil, errors.New("no puedes invitarte a tu propia lista")
+	}
+
+	var existing int64
+	config.DB.Model(&models.ListCollaborator{}).Where("list_id = ? AND user_id = ?", listID, user.ID).Count(&existing)
+	if existing > 0 {
+		return nil, errors.New("el usuario ya fue invitado a esta lista")
+	}
+
+	collaborator := models.ListCollaborator{
+		ListID:    listID,
+		UserID:    user.ID,
+		InvitedBy: ownerID,
+		Status:    models.CollaboratorPending,
+	}
+	if err := config.DB.Create(&collaborator).Error; err != nil {
+		return nil, errors.New("error al invitar al usuario")
+	}
+
+	collaborator.UserName = user.Name
+	return &collaborator, nil
+}
+
+// RespondInvitation acepta o rechaza una invitación pendiente para colaborar en una lista
+func RespondInvitation(listID, userID uint, accept bool) error {
+	var collaborator models.ListCollaborator
+	if err := config.DB.Where("list_id = ? AND user_id = ? AND status = ?", listID, userID, models.CollaboratorPending).
+		First(&collaborator).Error; err != nil {
+		return err
+	}
+
+	if !accept {
+		return config.DB.Delete(&collaborator).Error
+	}
+	return config.DB.Model(&collaborator).Update("status", models.CollaboratorAccepted).Error
+}
+
+// RemoveCollaborator retira a un colaborador; lo puede hacer el dueño o el propio colaborador
+func RemoveCollaborator(listID, actorID, collaboratorID uint) error {
+	var list models.MovieList
+	if err := config.DB.First(&list, listID).Error; err != nil {
+		return err
+	}
+	if list.UserID != actorID && collaboratorID != actorID {
+		return ErrListForbidden
+	}
+
+	result := config.DB.Where("list_id = ? AND user_id = ?", listID, collaboratorID).Delete(&models.ListCollaborator{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// getEditableList obtiene la lista comprobando que el usuario sea el dueño o un colaborador
+func getEditableList(listID, userID uint) (*models.MovieList, error) {
+	var list models.MovieList
+	if err := config.DB.Preload("Collaborators").First(&list, listID).Error; err != nil {
+		return nil, err
+	}
+	if !isListMember(&list, userID) {
+		return nil, ErrListForbidden
+	}
+	return &list, nil
+}
+
+// isListMember indica si el usuario es el dueño o un colaborador que aceptó la invitación
+func isListMember(list *models.MovieList, userID uint) bool {
+	if list.UserID == userID {
+		return true
+	}
+	for _, collaborator := range list.Collaborators {
+		if collaborator.UserID == userID && collaborator.Status == models.CollaboratorAccepted {
+			return true
+		}
+	}
+	return false
+}
+
+// touchList actualiza la fecha de modificación de la lista tras cambiar sus películas
+func touchList(listID uint) {
+	config.DB.Model(&models.MovieList{}).Where("id = ?", listID).Update("updated_at", gorm.Expr("NOW()"))
+}
+
+// validateList comprueba los campos de una lista
+func validateList(list *models.MovieList) error {
+	list.Title = strings.TrimSpace(list.Title)
+	list.Description = strings.TrimSpace(list.Description)
+	if list.Title == "" {
+		return errors.New("el título de la lista es obligatorio")
+	}
+	if len(list.Title) > 150 {
+		return errors.New("el título no puede superar los 150 caracteres")
+	}
+
+	if list.Visibility == "" {
+		list.Visibility = models.ListPublic
+	}
+	if !models.IsValidListVisibility(list.Visibility) {
+		return errors.New("visibilidad no válida (public, unlisted o private)")
+	}
+	return nil
+}
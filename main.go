@@ -6,9 +6,14 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"time"
 
 	handler "cine_conecta_backend/api"
+	"cine_conecta_backend/cinemas"
 	"cine_conecta_backend/comments/services"
+	"cine_conecta_backend/config"
+	"cine_conecta_backend/movies"
+	"cine_conecta_backend/movies/factorization"
 
 	"github.com/joho/godotenv"
 )
@@ -16,6 +21,24 @@ import (
 func main() {
 	// Procesar flags de línea de comandos
 	checkHFToken := flag.Bool("check-hf", false, "Verificar token de HuggingFace")
+	importMovies := flag.String("import-movies", "", "Importar películas desde un archivo CSV o JSON")
+	exportMovies := flag.String("export-movies", "", "Exportar el catálogo de películas a un archivo CSV o JSON")
+	dryRun := flag.Bool("dry-run", false, "Validar la importación, el barrido o la reconciliación sin guardar cambios")
+	sweepStorage := flag.Bool("sweep-storage", false, "Eliminar pósters y archivos de la galería huérfanos del almacenamiento")
+	sweepMinAge := flag.Duration("sweep-min-age", 24*time.Hour, "Antigüedad mínima de los archivos huérfanos a eliminar")
+	purgeTrash := flag.Bool("purge-trash", false, "Eliminar definitivamente las películas antiguas de la papelera")
+	trashRetention := flag.Duration("trash-retention", 30*24*time.Hour, "Tiempo que una película permanece en la papelera antes de purgarse")
+	reconcileCounters := flag.Bool("reconcile-counters", false, "Verificar y reparar los contadores de me gusta, comentarios y valoración de las películas")
+	snapshotTrending := flag.Bool("snapshot-trending", false, "Guardar una instantánea de las películas en tendencia")
+	buildSimilarities := flag.Bool("build-similarities", false, "Recalcular las similitudes entre películas para las recomendaciones")
+	exportInteractions := flag.String("export-interactions", "", "Exportar la matriz de interacciones usuario×película a un archivo CSV")
+	sweepBookings := flag.Bool("sweep-bookings", false, "Liberar las reservas pendientes de pago vencidas y reembolsar sus cobros")
+	trainFactors := flag.Bool("train-factors", false, "Entrenar el modelo de factores latentes para las recomendaciones")
+	alsDefaults := factorization.DefaultConfig()
+	alsFactors := flag.Int("factors", alsDefaults.Factors, "Dimensión de los vectores latentes del modelo de factores")
+	alsIterations := flag.Int("als-iterations", alsDefaults.Iterations, "Iteraciones de mínimos cuadrados alternos")
+	alsLambda := flag.Float64("als-lambda", alsDefaults.Regularization, "Regularización del modelo de factores")
+	alsAlpha := flag.Float64("als-alpha", alsDefaults.Alpha, "Confianza que aporta cada interacción en el modelo de factores")
 	flag.Parse()
 
 	// Mostrar directorio de trabajo actual
@@ -70,6 +93,119 @@ func main() {
 		return // No continuar con el servidor
 	}
 
+	// Importación masiva del catálogo desde la línea de comandos
+	if *importMovies != "" {
+		config.ConnectDB()
+		if err := movies.ImportCatalogFile(*importMovies, *dryRun); err != nil {
+			log.Printf("❌ Error al importar películas: %v", err)
+			os.Exit(1)
+		}
+		log.Println("✅ Importación de películas completada")
+		os.Exit(0)
+	}
+
+	// Exportación del catálogo desde la línea de comandos
+	if *exportMovies != "" {
+		config.ConnectDB()
+		if err := movies.ExportCatalogFile(*exportMovies); err != nil {
+			log.Printf("❌ Error al exportar películas: %v", err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
+	// Barrido de archivos huérfanos del almacenamiento
+	if *sweepStorage {
+		config.ConnectDB()
+		if err := movies.SweepStorage(*sweepMinAge, *dryRun); err != nil {
+			log.Printf("❌ Error al barrer el almacenamiento: %v", err)
+			os.Exit(1)
+		}
+		log.Println("✅ Barrido del almacenamiento completado")
+		os.Exit(0)
+	}
+
+	// Purga programada de la papelera de películas
+	if *purgeTrash {
+		config.ConnectDB()
+		if err := movies.PurgeTrash(*trashRetention, *dryRun); err != nil {
+			log.Printf("❌ Error al purgar la papelera: %v", err)
+			os.Exit(1)
+		}
+		log.Println("✅ Purga de la papelera completada")
+		os.Exit(0)
+	}
+
+	// Verificación y reparación de los contadores de las películas
+	if *reconcileCounters {
+		config.ConnectDB()
+		if err := movies.ReconcileCounters(*dryRun); err != nil {
+			log.Printf("❌ Error al reconciliar los contadores: %v", err)
+			os.Exit(1)
+		}
+		log.Println("✅ Reconciliación de contadores completada")
+		os.Exit(0)
+	}
+
+	// Instantánea periódica de las películas en tendencia
+	if *snapshotTrending {
+		config.ConnectDB()
+		if err := movies.SnapshotTrending(); err != nil {
+			log.Printf("❌ Error al guardar las tendencias: %v", err)
+			os.Exit(1)
+		}
+		log.Println("✅ Instantánea de tendencias guardada")
+		os.Exit(0)
+	}
+
+	// Cálculo periódico de las similitudes del filtrado colaborativo
+	if *buildSimilarities {
+		config.ConnectDB()
+		if err := movies.BuildSimilarities(); err != nil {
+			log.Printf("❌ Error al calcular las similitudes: %v", err)
+			os.Exit(1)
+		}
+		log.Println("✅ Similitudes entre películas calculadas")
+		os.Exit(0)
+	}
+
+	// Exportación de la matriz de interacciones para entrenar modelos externos
+	if *exportInteractions != "" {
+		config.ConnectDB()
+		if err := movies.ExportInteractions(*exportInteractions); err != nil {
+			log.Printf("❌ Error al exportar las interacciones: %v", err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
+	// Barrido de las reservas que quedaron pendientes de pago tras una interrupción
+	if *sweepBookings {
+		config.ConnectDB()
+		if err := cinemas.SweepPendingBookings(*dryRun); err != nil {
+			log.Printf("❌ Error al barrer las reservas pendientes: %v", err)
+			os.Exit(1)
+		}
+		log.Println("✅ Barrido de reservas pendientes completado")
+		os.Exit(0)
+	}
+
+	// Entrenamiento periódico del modelo de factores latentes
+	if *trainFactors {
+		config.ConnectDB()
+		cfg := alsDefaults
+		cfg.Factors = *alsFactors
+		cfg.Iterations = *alsIterations
+		cfg.Regularization = *alsLambda
+		cfg.Alpha = *alsAlpha
+		if err := movies.TrainFactors(cfg); err != nil {
+			log.Printf("❌ Error al entrenar el modelo de factores: %v", err)
+			os.Exit(1)
+		}
+		log.Println("✅ Modelo de factores entrenado")
+		os.Exit(0)
+	}
+
 	// Conexión a la base de datos
 	log.Println("Server running on http://localhost:8080")
 	http.ListenAndServe(":8080", http.HandlerFunc(handler.Handler))
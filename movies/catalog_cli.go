@@ -0,0 +1,76 @@
+package movies
+
+import (
+	"cine_conecta_backend/movies/services"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ImportCatalogFile importa películas desde un archivo CSV o JSON (según su extensión)
+func ImportCatalogFile(path string, dryRun bool) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("no se pudo abrir %s: %w", path, err)
+	}
+	defer file.Close()
+
+	var rows []services.CatalogRow
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		rows, err = services.ParseCatalogCSV(file)
+	case ".json":
+		rows, err = services.ParseCatalogJSON(file)
+	default:
+		return fmt.Errorf("extensión no soportada: %s (use .csv o .json)", filepath.Ext(path))
+	}
+	if err != nil {
+		return err
+	}
+
+	// Las importaciones por consola se registran sin autor en el historial de cambios
+	report, err := services.ImportCatalog(rows, dryRun, 0)
+	if err != nil {
+		return err
+	}
+
+	// Mostrar el reporte completo para revisar los errores por fila
+	output, _ := json.MarshalIndent(report, "", "  ")
+	fmt.Println(string(output))
+
+	if report.Failed > 0 {
+		return fmt.Errorf("%d filas con errores", report.Failed)
+	}
+	return nil
+}
+
+// ExportCatalogFile exporta el catálogo completo a un archivo CSV o JSON (según su extensión)
+func ExportCatalogFile(path string) error {
+	rows, err := services.ExportCatalog()
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("no se pudo crear %s: %w", path, err)
+	}
+	defer file.Close()
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		err = services.WriteCatalogCSV(file, rows)
+	case ".json":
+		err = services.WriteCatalogJSON(file, rows)
+	default:
+		return fmt.Errorf("extensión no soportada: %s (use .csv o .json)", filepath.Ext(path))
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("✅ %d películas exportadas a %s\n", len(rows), path)
+	return nil
+}
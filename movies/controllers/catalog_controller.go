@@ -0,0 +1,114 @@
+package controllers
+
+import (
+	"cine_conecta_backend/auth/utils"
+	"cine_conecta_backend/movies/services"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ImportMovies importa películas desde un archivo CSV o JSON
+// POST /api/movies/import?format=csv|json&dry_run=true (restringido a admin)
+func ImportMovies(c *gin.Context) {
+	var reader io.Reader
+	format := strings.ToLower(c.Query("format"))
+
+	// Aceptar el archivo como multipart (campo 'file') o directamente en el cuerpo
+	if fileHeader, err := c.FormFile("file"); err == nil {
+		file, err := fileHeader.Open()
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "No se pudo abrir el archivo")
+			return
+		}
+		defer file.Close()
+		reader = file
+		if format == "" {
+			format = strings.TrimPrefix(strings.ToLower(filepath.Ext(fileHeader.Filename)), ".")
+		}
+	} else {
+		reader = c.Request.Body
+		if format == "" {
+			if strings.Contains(c.ContentType(), "csv") {
+				format = "csv"
+			} else {
+				format = "json"
+			}
+		}
+	}
+
+	rows, err := parseCatalog(reader, format)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	claims, _ := c.Get("claims")
+	userID := claims.(*utils.Claims).UserID
+
+	dryRun := c.Query("dry_run") == "true"
+	report, err := services.ImportCatalog(rows, dryRun, userID)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Error al importar el catálogo: "+err.Error())
+		return
+	}
+
+	message := "Catálogo importado"
+	if dryRun {
+		message = "Simulación de importación completada (no se guardaron cambios)"
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": message,
+		"report":  report,
+	})
+}
+
+// ExportMovies exporta el catálogo completo en CSV o JSON
+// GET /api/movies/export?format=csv|json (restringido a admin)
+func ExportMovies(c *gin.Context) {
+	format := strings.ToLower(c.DefaultQuery("format", "json"))
+	if format != "csv" && format != "json" {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Formato no soportado. Use csv o json")
+		return
+	}
+
+	rows, err := services.ExportCatalog()
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Error al exportar el catálogo")
+		return
+	}
+
+	filename := fmt.Sprintf("catalogo_%s.%s", time.Now().Format("20060102"), format)
+	c.Header("Content-Disposition", "attachment; filename="+filename)
+
+	if format == "csv" {
+		c.Header("Content-Type", "text/csv; charset=utf-8")
+		if err := services.WriteCatalogCSV(c.Writer, rows); err != nil {
+			fmt.Printf("[DEBUG-CATALOG] Error al escribir CSV: %v\n", err)
+		}
+		return
+	}
+
+	c.Header("Content-Type", "application/json; charset=utf-8")
+	if err := services.WriteCatalogJSON(c.Writer, rows); err != nil {
+		fmt.Printf("[DEBUG-CATALOG] Error al escribir JSON: %v\n", err)
+	}
+}
+
+// parseCatalog interpreta el contenido según el formato indicado
+func parseCatalog(reader io.Reader, format string) ([]services.CatalogRow, error) {
+	switch format {
+	case "csv":
+		return services.ParseCatalogCSV(reader)
+	case "json":
+		return services.ParseCatalogJSON(reader)
+	default:
+		return nil, fmt.Errorf("formato no soportado: %s (use csv o json)", format)
+	}
+}
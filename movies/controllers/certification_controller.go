@@ -0,0 +1,158 @@
+package controllers
+
+import (
+	"cine_conecta_backend/auth/utils"
+	"cine_conecta_backend/movies/services"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Estructura para recibir la clasificación de una película en un país
+type CertificationInput struct {
+	Rating string `json:"rating"`
+	MinAge *int   `json:"min_age"` // Opcional si se puede deducir de la etiqueta
+}
+
+// Estructura para recibir los avisos de contenido de una película
+type AdvisoriesInput struct {
+	Advisories []string `json:"advisories"`
+}
+
+// GetMovieCertifications devuelve las clasificaciones por país y los avisos de contenido de una película
+// GET /api/movies/:movieId/certifications
+func GetMovieCertifications(c *gin.Context) {
+	movieID, ok := parseMovieIDParam(c)
+	if !ok {
+		return
+	}
+
+	movie, err := services.GetMovieCertifications(movieID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.ErrorResponse(c, http.StatusNotFound, "Película no encontrada")
+			return
+		}
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Error al obtener las clasificaciones")
+		return
+	}
+
+	advisories := movie.ContentAdvisories
+	if advisories == nil {
+		advisories = []string{}
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"movie_id":           movie.ID,
+		"certifications":     movie.Certifications,
+		"content_advisories": advisories,
+	})
+}
+
+// SaveMovieCertification crea o reemplaza la clasificación de una película en un país
+// PUT /api/movies/:movieId/certifications/:country (restringido a admin)
+func SaveMovieCertification(c *gin.Context) {
+	movieID, ok := parseMovieIDParam(c)
+	if !ok {
+		return
+	}
+
+	var input CertificationInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Datos inválidos")
+		return
+	}
+
+	claims, _ := c.Get("claims")
+	userID := claims.(*utils.Claims).UserID
+
+	certification, err := services.SaveMovieCertification(movieID, c.Param("country"), input.Rating, input.MinAge, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.ErrorResponse(c, http.StatusNotFound, "Película no encontrada")
+			return
+		}
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, certification)
+}
+
+// DeleteMovieCertification elimina la clasificación de una película en un país
+// DELETE /api/movies/:movieId/certifications/:country (restringido a admin)
+func DeleteMovieCertification(c *gin.Context) {
+	movieID, ok := parseMovieIDParam(c)
+	if !ok {
+		return
+	}
+
+	claims, _ := c.Get("claims")
+	userID := claims.(*utils.Claims).UserID
+
+	if err := services.DeleteMovieCertification(movieID, c.Param("country"), userID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.ErrorResponse(c, http.StatusNotFound, "Clasificación no encontrada")
+			return
+		}
+		utils.ErrorResponse(c, http.StatusInternalServerError, "No se pudo eliminar la clasificación")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Clasificación eliminada correctamente"})
+}
+
+// UpdateMovieAdvisories reemplaza los avisos de contenido de una película
+// PUT /api/movies/:movieId/advisories (restringido a admin)
+func UpdateMovieAdvisories(c *gin.Context) {
+	movieID, ok := parseMovieIDParam(c)
+	if !ok {
+		return
+	}
+
+	var input AdvisoriesInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Datos inválidos")
+		return
+	}
+
+	claims, _ := c.Get("claims")
+	userID := claims.(*utils.Claims).UserID
+
+	advisories, err := services.SetMovieAdvisories(movieID, input.Advisories, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.ErrorResponse(c, http.StatusNotFound, "Película no encontrada")
+			return
+		}
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"movie_id":           movieID,
+		"content_advisories": advisories,
+	})
+}
+
+// contentRestrictions obtiene las restricciones de contenido del usuario autenticado
+func contentRestrictions(c *gin.Context) services.ContentRestrictions {
+	claims, exists := c.Get("claims")
+	if !exists {
+		return services.NoContentRestrictions
+	}
+	userClaims := claims.(*utils.Claims)
+	return services.GetContentRestrictions(userClaims.UserID, userClaims.Role)
+}
+
+// parseMovieIDParam obtiene el identificador de la película de la ruta
+func parseMovieIDParam(c *gin.Context) (uint, bool) {
+	movieID, err := strconv.ParseUint(c.Param("movieId"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "ID de película inválido")
+		return 0, false
+	}
+	return uint(movieID), true
+}
@@ -0,0 +1,243 @@
+package controllers
+
+import (
+	"cine_conecta_backend/auth/utils"
+	"cine_conecta_backend/movies/models"
+	"cine_conecta_backend/movies/services"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Estructura para recibir los datos de creación/actualización de colecciones
+type CollectionInput struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	PosterURL   string `json:"poster_url"`
+}
+
+// GetCollections lista las colecciones
+// GET /api/collections?name=
+func GetCollections(c *gin.Context) {
+	collections, err := services.GetCollections(c.Query("name"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Error al obtener colecciones")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"collections": collections,
+		"count":       len(collections),
+	})
+}
+
+// GetCollection devuelve la página de una colección con sus películas y la valoración conjunta
+// GET /api/collections/:collectionId
+func GetCollection(c *gin.Context) {
+	collectionID, ok := parseCollectionParam(c)
+	if !ok {
+		return
+	}
+
+	page, err := services.GetCollectionPage(collectionID, contentRestrictions(c))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.ErrorResponse(c, http.StatusNotFound, "Colección no encontrada")
+			return
+		}
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Error al obtener la colección")
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+// CreateCollection crea una colección
+// POST /api/collections (restringido a admin)
+func CreateCollection(c *gin.Context) {
+	var input CollectionInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Datos inválidos")
+		return
+	}
+
+	collection := models.Collection{
+		Name:        input.Name,
+		Description: input.Description,
+		PosterURL:   input.PosterURL,
+	}
+	if err := services.CreateCollection(&collection); err != nil {
+		respondCollectionSaveError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, collection)
+}
+
+// UpdateCollection actualiza una colección
+// PUT /api/collections/:collectionId (restringido a admin)
+func UpdateCollection(c *gin.Context) {
+	collectionID, ok := parseCollectionParam(c)
+	if !ok {
+		return
+	}
+
+	collection, err := services.GetCollectionByID(collectionID)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Colección no encontrada")
+		return
+	}
+
+	var input CollectionInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Datos inválidos")
+		return
+	}
+
+	if input.Name != "" {
+		collection.Name = input.Name
+	}
+	if input.Description != "" {
+		collection.Description = input.Description
+	}
+	if input.PosterURL != "" {
+		collection.PosterURL = input.PosterURL
+	}
+
+	if err := services.UpdateCollection(&collection); err != nil {
+		respondCollectionSaveError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, collection)
+}
+
+// respondCollectionSaveError traduce los errores al guardar una colección a respuestas HTTP
+func respondCollectionSaveError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrCollectionNameRequired):
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+	case errors.Is(err, services.ErrCollectionNameTaken):
+		utils.ErrorResponse(c, http.StatusConflict, err.Error())
+	default:
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Error al guardar la colección")
+	}
+}
+
+// DeleteCollection elimina una colección sin eliminar sus películas
+// DELETE /api/collections/:collectionId (restringido a admin)
+func DeleteCollection(c *gin.Context) {
+	collectionID, ok := parseCollectionParam(c)
+	if !ok {
+		return
+	}
+
+	if err := services.DeleteCollection(collectionID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.ErrorResponse(c, http.StatusNotFound, "Colección no encontrada")
+			return
+		}
+		utils.ErrorResponse(c, http.StatusInternalServerError, "No se pudo eliminar la colección")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Colección eliminada correctamente"})
+}
+
+// AddMovieToCollection añade una película a la colección
+// POST /api/collections/:collectionId/movies (restringido a admin)
+func AddMovieToCollection(c *gin.Context) {
+	collectionID, ok := parseCollectionParam(c)
+	if !ok {
+		return
+	}
+
+	var input struct {
+		MovieID  uint `json:"movie_id" binding:"required"`
+		Position int  `json:"position"` // Opcional: por defecto al final
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Se requiere el movie_id de la película")
+		return
+	}
+
+	if err := services.AddMovieToCollection(collectionID, input.MovieID, input.Position); err != nil {
+		respondCollectionError(c, err)
+		return
+	}
+
+	page, _ := services.GetCollectionPage(collectionID, contentRestrictions(c))
+	c.JSON(http.StatusCreated, page)
+}
+
+// RemoveMovieFromCollection quita una película de la colección
+// DELETE /api/collections/:collectionId/movies/:movieId (restringido a admin)
+func RemoveMovieFromCollection(c *gin.Context) {
+	collectionID, ok := parseCollectionParam(c)
+	if !ok {
+		return
+	}
+
+	movieID, err := strconv.ParseUint(c.Param("movieId"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "ID de película inválido")
+		return
+	}
+
+	if err := services.RemoveMovieFromCollection(collectionID, uint(movieID)); err != nil {
+		respondCollectionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Película quitada de la colección"})
+}
+
+// ReorderCollection cambia el orden de las películas de la colección
+// POST /api/collections/:collectionId/reorder (restringido a admin)
+func ReorderCollection(c *gin.Context) {
+	collectionID, ok := parseCollectionParam(c)
+	if !ok {
+		return
+	}
+
+	var input struct {
+		MovieIDs []uint `json:"movie_ids" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Se requiere la lista ordenada de movie_ids")
+		return
+	}
+
+	if err := services.ReorderCollection(collectionID, input.MovieIDs); err != nil {
+		respondCollectionError(c, err)
+		return
+	}
+
+	page, _ := services.GetCollectionPage(collectionID, contentRestrictions(c))
+	c.JSON(http.StatusOK, page)
+}
+
+// respondCollectionError traduce los errores del servicio de colecciones a respuestas HTTP
+func respondCollectionError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		utils.ErrorResponse(c, http.StatusNotFound, "Colección o película no encontrada")
+	case err.Error() == "película no encontrada":
+		utils.ErrorResponse(c, http.StatusNotFound, "Película no encontrada")
+	default:
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+	}
+}
+
+// parseCollectionParam obtiene el identificador de la colección de la ruta
+func parseCollectionParam(c *gin.Context) (uint, bool) {
+	collectionID, err := strconv.ParseUint(c.Param("collectionId"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "ID de colección inválido")
+		return 0, false
+	}
+	return uint(collectionID), true
+}
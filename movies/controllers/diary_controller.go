@@ -0,0 +1,154 @@
+package controllers
+
+import (
+	"cine_conecta_backend/auth/utils"
+	"cine_conecta_backend/movies/services"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// GetDiary devuelve el diario de películas vistas del usuario
+// GET /api/diary?year=&month=&movie_id=
+func GetDiary(c *gin.Context) {
+	claims, _ := c.Get("claims")
+	userID := claims.(*utils.Claims).UserID
+
+	var filter services.DiaryFilter
+	filter.Year, _ = strconv.Atoi(c.Query("year"))
+	filter.Month, _ = strconv.Atoi(c.Query("month"))
+	if movieID, err := strconv.ParseUint(c.Query("movie_id"), 10, 32); err == nil {
+		filter.MovieID = uint(movieID)
+	}
+
+	entries, err := services.GetDiary(userID, filter)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Error al obtener el diario")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"entries": entries,
+		"count":   len(entries),
+	})
+}
+
+// CreateDiaryEntry registra que el usuario vio una película
+// POST /api/diary
+func CreateDiaryEntry(c *gin.Context) {
+	var input services.DiaryEntryInput
+	if err := c.ShouldBindJSON(&input); err != nil || input.MovieID == 0 {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Se requiere el movie_id de la película")
+		return
+	}
+
+	claims, _ := c.Get("claims")
+	userID := claims.(*utils.Claims).UserID
+
+	entry, err := services.CreateDiaryEntry(userID, input)
+	if err != nil {
+		if err.Error() == "película no encontrada" {
+			utils.ErrorResponse(c, http.StatusNotFound, "Película no encontrada")
+			return
+		}
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusCreated, entry)
+}
+
+// UpdateDiaryEntry modifica la fecha, la valoración o el comentario de una entrada
+// PUT /api/diary/:entryId
+func UpdateDiaryEntry(c *gin.Context) {
+	entryID, err := strconv.ParseUint(c.Param("entryId"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "ID de entrada inválido")
+		return
+	}
+
+	var input services.DiaryEntryInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Datos inválidos")
+		return
+	}
+
+	claims, _ := c.Get("claims")
+	userID := claims.(*utils.Claims).UserID
+
+	entry, err := services.UpdateDiaryEntry(userID, uint(entryID), input)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.ErrorResponse(c, http.StatusNotFound, "Entrada no encontrada")
+			return
+		}
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, entry)
+}
+
+// DeleteDiaryEntry elimina una entrada del diario
+// DELETE /api/diary/:entryId
+func DeleteDiaryEntry(c *gin.Context) {
+	entryID, err := strconv.ParseUint(c.Param("entryId"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "ID de entrada inválido")
+		return
+	}
+
+	claims, _ := c.Get("claims")
+	userID := claims.(*utils.Claims).UserID
+
+	if err := services.DeleteDiaryEntry(userID, uint(entryID)); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.ErrorResponse(c, http.StatusNotFound, "Entrada no encontrada")
+			return
+		}
+		utils.ErrorResponse(c, http.StatusInternalServerError, "No se pudo eliminar la entrada")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Entrada eliminada correctamente"})
+}
+
+// GetDiaryYearStats devuelve las estadísticas del diario de un año, con el desglose por mes
+// GET /api/diary/stats/:year
+func GetDiaryYearStats(c *gin.Context) {
+	respondDiaryStats(c, 0)
+}
+
+// GetDiaryMonthStats devuelve las estadísticas del diario de un mes, con el desglose por día
+// GET /api/diary/stats/:year/:month
+func GetDiaryMonthStats(c *gin.Context) {
+	month, err := strconv.Atoi(c.Param("month"))
+	if err != nil || month < 1 || month > 12 {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Mes inválido")
+		return
+	}
+	respondDiaryStats(c, month)
+}
+
+// respondDiaryStats calcula y devuelve las estadísticas del año de la ruta
+func respondDiaryStats(c *gin.Context, month int) {
+	year, err := strconv.Atoi(c.Param("year"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Año inválido")
+		return
+	}
+
+	claims, _ := c.Get("claims")
+	userID := claims.(*utils.Claims).UserID
+
+	stats, err := services.GetDiaryStats(userID, year, month)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
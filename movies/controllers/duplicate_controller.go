@@ -0,0 +1,87 @@
+package controllers
+
+import (
+	"cine_conecta_backend/auth/utils"
+	"cine_conecta_backend/movies/services"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// FindDuplicateMovies devuelve las parejas de películas que parecen duplicadas
+// GET /api/movies/duplicates?threshold=0.75&limit=50 (restringido a admin)
+func FindDuplicateMovies(c *gin.Context) {
+	threshold := services.DefaultDuplicateThreshold
+	if value := c.Query("threshold"); value != "" {
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil || parsed <= 0 || parsed > 1 {
+			utils.ErrorResponse(c, http.StatusBadRequest, "El umbral debe estar entre 0 y 1")
+			return
+		}
+		threshold = parsed
+	}
+
+	limit := 50
+	if value := c.Query("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed <= 0 {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Límite inválido")
+			return
+		}
+		limit = parsed
+	}
+
+	candidates, err := services.FindDuplicateMovies(threshold, limit)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Error al buscar películas duplicadas")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"duplicates": candidates,
+		"count":      len(candidates),
+		"threshold":  threshold,
+	})
+}
+
+// MergeMovies fusiona una película duplicada sobre la indicada en la ruta
+// POST /api/movies/:movieId/merge (restringido a admin)
+func MergeMovies(c *gin.Context) {
+	movieID, ok := parseMovieIDParam(c)
+	if !ok {
+		return
+	}
+
+	var input struct {
+		SourceID uint `json:"source_id" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Debes indicar la película duplicada (source_id)")
+		return
+	}
+
+	claims, _ := c.Get("claims")
+	userID := claims.(*utils.Claims).UserID
+
+	report, err := services.MergeMovies(movieID, input.SourceID, userID)
+	if err != nil {
+		if errors.Is(err, services.ErrMergeSameMovie) {
+			utils.ErrorResponse(c, http.StatusBadRequest, "No se puede fusionar una película consigo misma")
+			return
+		}
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.ErrorResponse(c, http.StatusNotFound, "Película no encontrada")
+			return
+		}
+		utils.ErrorResponse(c, http.StatusInternalServerError, "No se pudieron fusionar las películas")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Películas fusionadas correctamente",
+		"report":  report,
+	})
+}
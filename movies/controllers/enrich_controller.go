@@ -0,0 +1,103 @@
+package controllers
+
+import (
+	"cine_conecta_backend/auth/utils"
+	"cine_conecta_backend/movies/metadata"
+	"cine_conecta_backend/movies/services"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PreviewMovieEnrichment muestra los datos que el proveedor externo propone para una película
+// GET /api/movies/:movieId/enrich?imdb_id=&tmdb_id= (restringido a admin)
+func PreviewMovieEnrichment(c *gin.Context) {
+	movieID, err := strconv.ParseUint(c.Param("movieId"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "ID de película inválido")
+		return
+	}
+
+	query := metadata.Query{
+		ImdbID: c.Query("imdb_id"),
+		TmdbID: c.Query("tmdb_id"),
+	}
+
+	preview, err := services.PreviewEnrichment(uint(movieID), query)
+	if err != nil {
+		respondEnrichmentError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Vista previa del enriquecimiento",
+		"preview": preview,
+	})
+}
+
+// ApplyMovieEnrichment aplica los datos del proveedor externo a una película
+// POST /api/movies/:movieId/enrich (restringido a admin)
+func ApplyMovieEnrichment(c *gin.Context) {
+	movieID, err := strconv.ParseUint(c.Param("movieId"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "ID de película inválido")
+		return
+	}
+
+	var input struct {
+		ImdbID string   `json:"imdb_id"`
+		TmdbID string   `json:"tmdb_id"`
+		Fields []string `json:"fields"` // Campos a aplicar; vacío para aplicar todos
+	}
+	if err := c.ShouldBindJSON(&input); err != nil && c.Request.ContentLength > 0 {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Datos inválidos")
+		return
+	}
+
+	for _, field := range input.Fields {
+		if !services.IsEnrichableField(field) {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Campo no soportado: "+field)
+			return
+		}
+	}
+
+	claims, _ := c.Get("claims")
+	userID := claims.(*utils.Claims).UserID
+
+	query := metadata.Query{ImdbID: input.ImdbID, TmdbID: input.TmdbID}
+	result, err := services.ApplyEnrichment(uint(movieID), query, input.Fields, userID)
+	if err != nil {
+		respondEnrichmentError(c, err)
+		return
+	}
+
+	movie, err := services.GetMovieByID(uint(movieID))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Error al obtener la película actualizada")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Película enriquecida correctamente",
+		"provider": result.Provider,
+		"applied":  result.Changes,
+		"movie":    movie,
+	})
+}
+
+// respondEnrichmentError traduce los errores del enriquecimiento a respuestas HTTP: los del proveedor
+// externo son 502 y los locales (base de datos, configuración) son 500
+func respondEnrichmentError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, metadata.ErrNotFound):
+		utils.ErrorResponse(c, http.StatusNotFound, metadata.ErrNotFound.Error())
+	case errors.Is(err, services.ErrEnrichMovieNotFound):
+		utils.ErrorResponse(c, http.StatusNotFound, "Película no encontrada")
+	case errors.Is(err, services.ErrMetadataProvider):
+		utils.ErrorResponse(c, http.StatusBadGateway, err.Error())
+	default:
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Error al enriquecer la película: "+err.Error())
+	}
+}
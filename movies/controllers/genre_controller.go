@@ -103,7 +103,10 @@ func AddGenreToMovie(c *gin.Context) {
 		return
 	}
 
-	if err := services.AddGenreToMovie(uint(movieID), input.Genre); err != nil {
+	claims, _ := c.Get("claims")
+	userID := claims.(*utils.Claims).UserID
+
+	if err := services.AddGenreToMovie(uint(movieID), input.Genre, userID); err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Error al añadir género a la película")
 		return
 	}
@@ -126,7 +129,10 @@ func RemoveGenreFromMovie(c *gin.Context) {
 		return
 	}
 
-	if err := services.RemoveGenreFromMovie(uint(movieID), genre); err != nil {
+	claims, _ := c.Get("claims")
+	userID := claims.(*utils.Claims).UserID
+
+	if err := services.RemoveGenreFromMovie(uint(movieID), genre, userID); err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Error al eliminar género de la película")
 		return
 	}
@@ -152,7 +158,10 @@ func UpdateMovieGenre(c *gin.Context) {
 		return
 	}
 
-	if err := services.UpdateMovieGenre(uint(movieID), input.Genre); err != nil {
+	claims, _ := c.Get("claims")
+	userID := claims.(*utils.Claims).UserID
+
+	if err := services.UpdateMovieGenre(uint(movieID), input.Genre, userID); err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Error al actualizar género de la película")
 		return
 	}
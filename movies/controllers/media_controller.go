@@ -0,0 +1,214 @@
+package controllers
+
+import (
+	"cine_conecta_backend/auth/utils"
+	"cine_conecta_backend/movies/imaging"
+	"cine_conecta_backend/movies/models"
+	"cine_conecta_backend/movies/services"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Estructura para registrar tráileres incrustados o enlaces externos
+type MediaLinkInput struct {
+	Kind       string `json:"kind" binding:"required"`
+	Provider   string `json:"provider" binding:"required"` // youtube, vimeo o url
+	URL        string `json:"url"`
+	ExternalID string `json:"external_id"`
+	Caption    string `json:"caption"`
+	SortOrder  int    `json:"sort_order"`
+}
+
+// Estructura para actualizar el título o la posición de un contenido
+type MediaUpdateInput struct {
+	Caption   *string `json:"caption"`
+	SortOrder *int    `json:"sort_order"`
+}
+
+// GetMovieMedia devuelve los tráileres, fondos y fotogramas de una película
+// GET /api/movies/:movieId/media?kind=trailer|backdrop|still
+func GetMovieMedia(c *gin.Context) {
+	movieID, err := strconv.ParseUint(c.Param("movieId"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "ID de película inválido")
+		return
+	}
+
+	kind := c.Query("kind")
+	if kind != "" && !models.IsValidMediaKind(models.MediaKind(kind)) {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Tipo no válido. Use trailer, backdrop o still")
+		return
+	}
+
+	media, err := services.GetMovieMedia(uint(movieID), kind)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Error al obtener el contenido multimedia")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"movie_id": movieID,
+		"media":    media,
+		"count":    len(media),
+	})
+}
+
+// AddMovieMedia registra un tráiler de YouTube/Vimeo o un enlace externo
+// POST /api/movies/:movieId/media (restringido a admin)
+func AddMovieMedia(c *gin.Context) {
+	movieID, err := strconv.ParseUint(c.Param("movieId"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "ID de película inválido")
+		return
+	}
+
+	var input MediaLinkInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Se requieren kind y provider")
+		return
+	}
+
+	media := models.MovieMedia{
+		Kind:       models.MediaKind(input.Kind),
+		Provider:   input.Provider,
+		URL:        input.URL,
+		ExternalID: input.ExternalID,
+		Caption:    input.Caption,
+		SortOrder:  input.SortOrder,
+	}
+	if err := services.AddMovieMediaLink(uint(movieID), &media); err != nil {
+		respondMediaError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, media)
+}
+
+// UploadMovieMedia sube un fondo, fotograma o vídeo de tráiler al almacenamiento
+// POST /api/movies/:movieId/media/upload (restringido a admin)
+// Formulario: file, kind, caption, sort_order
+func UploadMovieMedia(c *gin.Context) {
+	movieID, err := strconv.ParseUint(c.Param("movieId"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "ID de película inválido")
+		return
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Archivo no encontrado (campo 'file')")
+		return
+	}
+
+	sortOrder, _ := strconv.Atoi(c.PostForm("sort_order"))
+	media, err := services.UploadMovieMedia(uint(movieID), models.MediaKind(c.PostForm("kind")), fileHeader, c.PostForm("caption"), sortOrder)
+	if err != nil {
+		respondMediaError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, media)
+}
+
+// UpdateMovieMedia modifica el título o la posición de un contenido
+// PUT /api/movies/:movieId/media/:mediaId (restringido a admin)
+func UpdateMovieMedia(c *gin.Context) {
+	movieID, mediaID, ok := parseMediaParams(c)
+	if !ok {
+		return
+	}
+
+	var input MediaUpdateInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Datos inválidos")
+		return
+	}
+
+	media, err := services.UpdateMovieMedia(movieID, mediaID, input.Caption, input.SortOrder)
+	if err != nil {
+		respondMediaError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, media)
+}
+
+// ReorderMovieMedia fija el orden de la galería según la lista de identificadores
+// POST /api/movies/:movieId/media/reorder (restringido a admin)
+func ReorderMovieMedia(c *gin.Context) {
+	movieID, err := strconv.ParseUint(c.Param("movieId"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "ID de película inválido")
+		return
+	}
+
+	var input struct {
+		IDs []uint `json:"ids" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Se requiere la lista ordenada de ids")
+		return
+	}
+
+	if err := services.ReorderMovieMedia(uint(movieID), input.IDs); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	media, _ := services.GetMovieMedia(uint(movieID), "")
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Orden actualizado correctamente",
+		"media":   media,
+	})
+}
+
+// DeleteMovieMedia elimina un contenido y sus archivos
+// DELETE /api/movies/:movieId/media/:mediaId (restringido a admin)
+func DeleteMovieMedia(c *gin.Context) {
+	movieID, mediaID, ok := parseMediaParams(c)
+	if !ok {
+		return
+	}
+
+	if err := services.DeleteMovieMedia(movieID, mediaID); err != nil {
+		respondMediaError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Contenido eliminado correctamente"})
+}
+
+// parseMediaParams obtiene los identificadores de película y contenido de la ruta
+func parseMediaParams(c *gin.Context) (uint, uint, bool) {
+	movieID, err := strconv.ParseUint(c.Param("movieId"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "ID de película inválido")
+		return 0, 0, false
+	}
+
+	mediaID, err := strconv.ParseUint(c.Param("mediaId"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "ID de contenido inválido")
+		return 0, 0, false
+	}
+
+	return uint(movieID), uint(mediaID), true
+}
+
+// respondMediaError traduce los errores de la galería a códigos HTTP
+func respondMediaError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		utils.ErrorResponse(c, http.StatusNotFound, "Contenido no encontrado")
+	case errors.Is(err, imaging.ErrInvalidImage):
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+	case err.Error() == "película no encontrada":
+		utils.ErrorResponse(c, http.StatusNotFound, "Película no encontrada")
+	default:
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+	}
+}
@@ -3,14 +3,17 @@ package controllers
 import (
 	"cine_conecta_backend/auth/utils"
 	"cine_conecta_backend/config"
+	"cine_conecta_backend/movies/imaging"
 	"cine_conecta_backend/movies/models"
 	"cine_conecta_backend/movies/services"
+	"errors"
 	"net/http"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 // Estructura para recibir los datos de creación/actualización de películas
@@ -19,7 +22,6 @@ type MovieInput struct {
 	Description string   `json:"description"`
 	Director    string   `json:"director"`
 	ReleaseDate string   `json:"release_date"`
-	Rating      float32  `json:"rating"`
 	PosterURL   string   `json:"poster_url"`
 	Genres      []string `json:"genres"` // Lista de nombres de géneros
 	Genre       string   `json:"genre"`  // Campo legacy para compatibilidad
@@ -32,7 +34,6 @@ func CreateMovie(c *gin.Context) {
 		Description string    `json:"description"`
 		Director    string    `json:"director"`
 		ReleaseDate time.Time `json:"release_date"`
-		Rating      float32   `json:"rating"`
 		PosterURL   string    `json:"poster_url"`
 		Genre       string    `json:"genre"`
 	}
@@ -42,13 +43,12 @@ func CreateMovie(c *gin.Context) {
 		return
 	}
 
-	// Crear la película
+	// Crear la película (la valoración se calcula a partir de los comentarios)
 	movie := models.Movie{
 		Title:       input.Title,
 		Description: input.Description,
 		Director:    input.Director,
 		ReleaseDate: input.ReleaseDate,
-		Rating:      input.Rating,
 		PosterURL:   input.PosterURL,
 	}
 
@@ -58,8 +58,11 @@ func CreateMovie(c *gin.Context) {
 		genreNames = models.ParseGenresString(input.Genre)
 	}
 
+	claims, _ := c.Get("claims")
+	userID := claims.(*utils.Claims).UserID
+
 	// Crear la película con géneros
-	if err := services.CreateMovieWithGenres(&movie, genreNames); err != nil {
+	if err := services.CreateMovieWithGenres(&movie, genreNames, services.RevisionInfo{UserID: userID, Source: models.RevisionSourceManual}); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al crear la película: " + err.Error()})
 		return
 	}
@@ -82,14 +85,46 @@ func GetMovies(c *gin.Context) {
 		// También se podría hacer con una subconsulta más compleja para buscar en la tabla de géneros
 	}
 
-	// Aplicar ordenamiento si se especifica
+	// Filtrar por persona del reparto o equipo técnico si se proporciona
+	if personID := c.Query("person_id"); personID != "" {
+		id, err := strconv.ParseUint(personID, 10, 32)
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "ID de persona inválido")
+			return
+		}
+		sub := config.DB.Model(&models.MovieCredit{}).Select("movie_id").Where("person_id = ?", id)
+		if role := c.Query("role"); role != "" {
+			if !models.IsValidCreditRole(models.CreditRole(role)) {
+				utils.ErrorResponse(c, http.StatusBadRequest, "Rol inválido")
+				return
+			}
+			sub = sub.Where("role = ?", role)
+		}
+		query = query.Where("id IN (?)", sub)
+	}
+
+	// Ocultar las películas no aptas según la edad y el control parental del usuario
+	query = query.Scopes(services.ContentFilter(contentRestrictions(c)))
+
+	prior, err := services.LoadRatingPrior()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Aplicar ordenamiento si se especifica (?sort=title o ?sort=-rating para orden descendente)
 	if sort := c.Query("sort"); sort != "" {
-		direction := "ASC"
+		direction := "asc"
 		if strings.HasPrefix(sort, "-") {
-			direction = "DESC"
+			direction = "desc"
 			sort = sort[1:]
 		}
-		query = query.Order(sort + " " + direction)
+		sortQuery, err := services.MovieSortClause(sort, direction, prior)
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		query = query.Order(sortQuery).Order("id")
 	} else {
 		// Ordenar por ID de forma descendente por defecto
 		query = query.Order("id DESC")
@@ -101,6 +136,10 @@ func GetMovies(c *gin.Context) {
 		return
 	}
 
+	prior.ApplyWeightedRatings(movies)
+
+	markWatchlist(c, movies)
+	localizeMovies(c, movies)
 	c.JSON(http.StatusOK, movies)
 }
 
@@ -114,11 +153,13 @@ func GetRecentMovies(c *gin.Context) {
 		limit = 10 // Si hay error o el límite es inválido, usar 10 por defecto
 	}
 
-	movies, err := services.GetRecentMovies(limit)
+	movies, err := services.GetRecentMovies(limit, contentRestrictions(c))
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, "No se pudieron obtener las películas recientes")
 		return
 	}
+	markWatchlist(c, movies)
+	localizeMovies(c, movies)
 	c.JSON(http.StatusOK, gin.H{
 		"count":   len(movies),
 		"results": movies,
@@ -128,21 +169,70 @@ func GetRecentMovies(c *gin.Context) {
 // GetMovie devuelve una película por su ID.
 // Método: GET /api/movies/:movieId
 func GetMovie(c *gin.Context) {
-	id := c.Param("id")
-
-	var movie models.Movie
-	if err := config.DB.Preload("Genres").First(&movie, id).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Película no encontrada"})
+	id, err := strconv.ParseUint(c.Param("movieId"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "ID inválido")
 		return
 	}
 
+	movie, ok := loadMovieDetail(c, uint(id))
+	if !ok {
+		return
+	}
 	c.JSON(http.StatusOK, movie)
 }
 
+// loadMovieDetail prepara la ficha de una película para quien consulta y registra la visita.
+// Responde 404 si no existe o no es apta para el usuario.
+func loadMovieDetail(c *gin.Context, id uint) (models.Movie, bool) {
+	// Las películas no aptas para el usuario se tratan como inexistentes
+	if !services.IsMovieAllowed(contentRestrictions(c), id) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Película no encontrada"})
+		return models.Movie{}, false
+	}
+
+	movie, err := movieDetail(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Película no encontrada"})
+		return models.Movie{}, false
+	}
+
+	services.RecordMovieView(movie.ID)
+
+	movies := []models.Movie{movie}
+	markWatchlist(c, movies)
+	localizeMovies(c, movies)
+	return movies[0], true
+}
+
+// movieDetail carga una película con sus géneros, multimedia, clasificaciones y sagas
+func movieDetail(id uint) (models.Movie, error) {
+	var movie models.Movie
+	err := config.DB.Preload("Genres").
+		Preload("Media", func(db *gorm.DB) *gorm.DB {
+			return db.Order("kind, sort_order, id")
+		}).
+		Preload("Certifications", func(db *gorm.DB) *gorm.DB {
+			return db.Order("country")
+		}).
+		First(&movie, id).Error
+	if err != nil {
+		return movie, err
+	}
+
+	// Indicar la película anterior y la siguiente de sus sagas
+	movie.Series, _ = services.GetSeriesHints(movie.ID)
+
+	if prior, err := services.LoadRatingPrior(); err == nil {
+		movie.WeightedRating = prior.Weighted(movie.ScoreSum, movie.CommentCount)
+	}
+	return movie, nil
+}
+
 // UpdateMovie actualiza una película existente.
 // Método: PUT /api/movies/:movieId (restringido a admin)
 func UpdateMovie(c *gin.Context) {
-	id := c.Param("id")
+	id := c.Param("movieId")
 
 	var movie models.Movie
 	if err := config.DB.First(&movie, id).Error; err != nil {
@@ -155,7 +245,6 @@ func UpdateMovie(c *gin.Context) {
 		Description string    `json:"description"`
 		Director    string    `json:"director"`
 		ReleaseDate time.Time `json:"release_date"`
-		Rating      float32   `json:"rating"`
 		PosterURL   string    `json:"poster_url"`
 		Genre       string    `json:"genre"`
 	}
@@ -178,11 +267,8 @@ func UpdateMovie(c *gin.Context) {
 	if !input.ReleaseDate.IsZero() {
 		movie.ReleaseDate = input.ReleaseDate
 	}
-	if input.Rating != 0 {
-		movie.Rating = input.Rating
-	}
-	if input.PosterURL != "" {
-		movie.PosterURL = input.PosterURL
+	if input.PosterURL != "" && input.PosterURL != movie.PosterURL {
+		movie.SetExternalPoster(input.PosterURL)
 	}
 
 	// Procesar géneros desde el string
@@ -191,8 +277,11 @@ func UpdateMovie(c *gin.Context) {
 		genreNames = models.ParseGenresString(input.Genre)
 	}
 
+	claims, _ := c.Get("claims")
+	userID := claims.(*utils.Claims).UserID
+
 	// Actualizar la película con géneros
-	if err := services.UpdateMovieWithGenres(&movie, genreNames); err != nil {
+	if err := services.UpdateMovieWithGenres(&movie, genreNames, services.RevisionInfo{UserID: userID, Source: models.RevisionSourceManual}); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al actualizar la película: " + err.Error()})
 		return
 	}
@@ -200,7 +289,7 @@ func UpdateMovie(c *gin.Context) {
 	c.JSON(http.StatusOK, movie)
 }
 
-// DeleteMovie elimina una película por su ID.
+// DeleteMovie envía una película a la papelera.
 // Método: DELETE /api/movies/:movieId (restringido a admin)
 func DeleteMovie(c *gin.Context) {
 	idParam := c.Param("movieId")
@@ -210,24 +299,37 @@ func DeleteMovie(c *gin.Context) {
 		return
 	}
 
-	if err := services.DeleteMovie(uint(id)); err != nil {
+	claims, _ := c.Get("claims")
+	userID := claims.(*utils.Claims).UserID
+
+	if err := services.DeleteMovie(uint(id), userID); err != nil {
+		if errors.Is(err, services.ErrMovieHasBookings) {
+			utils.ErrorResponse(c, http.StatusConflict, "La película tiene entradas vendidas; cancela las reservas antes de eliminarla")
+			return
+		}
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.ErrorResponse(c, http.StatusNotFound, "Película no encontrada")
+			return
+		}
 		utils.ErrorResponse(c, http.StatusInternalServerError, "No se pudo eliminar la película")
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Película eliminada correctamente"})
+	c.JSON(http.StatusOK, gin.H{"message": "Película enviada a la papelera correctamente"})
 }
 
 func GetMoviesSorted(c *gin.Context) {
 	sortBy := c.DefaultQuery("sortBy", "title")
 	order := c.DefaultQuery("order", "asc")
 
-	movies, err := services.GetMoviesSorted(sortBy, order)
+	movies, err := services.GetMoviesSorted(sortBy, order, contentRestrictions(c))
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
 		return
 	}
 
+	markWatchlist(c, movies)
+	localizeMovies(c, movies)
 	c.JSON(http.StatusOK, movies)
 }
 
@@ -240,8 +342,7 @@ func UploadPoster(c *gin.Context) {
 	}
 
 	// Verificar si la película existe
-	movie, err := services.GetMovieByID(uint(id))
-	if err != nil {
+	if _, err := services.GetMovieByID(uint(id)); err != nil {
 		utils.ErrorResponse(c, http.StatusNotFound, "Película no encontrada")
 		return
 	}
@@ -264,16 +365,24 @@ func UploadPoster(c *gin.Context) {
 		return
 	}
 
-	url, err := services.UploadPoster(uint(id), fileHeader)
+	claims, _ := c.Get("claims")
+	userID := claims.(*utils.Claims).UserID
+
+	movie, err := services.UploadPoster(uint(id), fileHeader, userID)
 	if err != nil {
+		if errors.Is(err, imaging.ErrInvalidImage) {
+			utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+			return
+		}
 		utils.ErrorResponse(c, http.StatusInternalServerError, err.Error())
 		return
 	}
 
 	// Devolver respuesta con la película actualizada
 	c.JSON(http.StatusOK, gin.H{
-		"message":    "Póster subido correctamente",
-		"poster_url": url,
-		"movie":      movie,
+		"message":         "Póster subido correctamente",
+		"poster_url":      movie.PosterURL,
+		"poster_variants": movie.PosterVariants,
+		"movie":           movie,
 	})
 }
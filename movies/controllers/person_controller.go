@@ -0,0 +1,303 @@
+package controllers
+
+import (
+	"cine_conecta_backend/auth/utils"
+	"cine_conecta_backend/movies/models"
+	"cine_conecta_backend/movies/services"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Estructura para recibir los datos de creación/actualización de personas
+type PersonInput struct {
+	Name      string `json:"name"`
+	Biography string `json:"biography"`
+	BirthDate string `json:"birth_date"`
+	PhotoURL  string `json:"photo_url"`
+}
+
+// GetPeople lista las personas registradas
+// GET /api/people?name=
+func GetPeople(c *gin.Context) {
+	people, err := services.GetPeople(c.Query("name"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Error al obtener personas")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"people": people,
+		"count":  len(people),
+	})
+}
+
+// GetPerson devuelve una persona con su filmografía
+// GET /api/people/:id
+func GetPerson(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "ID de persona inválido")
+		return
+	}
+
+	person, err := services.GetPersonWithFilmography(uint(id), contentRestrictions(c))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Persona no encontrada")
+		return
+	}
+
+	// Agrupar la filmografía por rol para facilitar su presentación
+	filmography := make(map[models.CreditRole][]models.MovieCredit)
+	for _, credit := range person.Credits {
+		filmography[credit.Role] = append(filmography[credit.Role], credit)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"person":      person,
+		"filmography": filmography,
+		"movie_count": len(person.Credits),
+	})
+}
+
+// CreatePerson registra una nueva persona
+// POST /api/people (restringido a admin)
+func CreatePerson(c *gin.Context) {
+	var input PersonInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Datos inválidos")
+		return
+	}
+
+	person := models.Person{
+		Name:      input.Name,
+		Biography: input.Biography,
+		PhotoURL:  input.PhotoURL,
+	}
+
+	if input.BirthDate != "" {
+		birthDate, err := utils.ParseDate(input.BirthDate)
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		person.BirthDate = &birthDate
+	}
+
+	if err := services.CreatePerson(&person); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusCreated, person)
+}
+
+// UpdatePerson actualiza los datos de una persona
+// PUT /api/people/:id (restringido a admin)
+func UpdatePerson(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "ID de persona inválido")
+		return
+	}
+
+	person, err := services.GetPersonByID(uint(id))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Persona no encontrada")
+		return
+	}
+
+	var input PersonInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Datos inválidos")
+		return
+	}
+
+	// Actualizar solo los campos enviados
+	if input.Name != "" {
+		person.Name = input.Name
+	}
+	if input.Biography != "" {
+		person.Biography = input.Biography
+	}
+	if input.PhotoURL != "" {
+		person.PhotoURL = input.PhotoURL
+	}
+	if input.BirthDate != "" {
+		birthDate, err := utils.ParseDate(input.BirthDate)
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		person.BirthDate = &birthDate
+	}
+
+	if err := services.UpdatePerson(&person); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, person)
+}
+
+// DeletePerson elimina una persona y sus créditos
+// DELETE /api/people/:id (restringido a admin)
+func DeletePerson(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "ID de persona inválido")
+		return
+	}
+
+	if err := services.DeletePerson(uint(id)); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.ErrorResponse(c, http.StatusNotFound, "Persona no encontrada")
+			return
+		}
+		utils.ErrorResponse(c, http.StatusInternalServerError, "No se pudo eliminar la persona")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Persona eliminada correctamente"})
+}
+
+// GetPersonMovies devuelve las películas de una persona, opcionalmente filtradas por rol
+// GET /api/people/:id/movies?role=
+func GetPersonMovies(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "ID de persona inválido")
+		return
+	}
+
+	role := c.Query("role")
+	if role != "" && !models.IsValidCreditRole(models.CreditRole(role)) {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Rol inválido")
+		return
+	}
+
+	movies, err := services.GetMoviesByPerson(uint(id), role, contentRestrictions(c))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Error al obtener películas de la persona")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"results": movies,
+		"count":   len(movies),
+	})
+}
+
+// MigrateDirectors convierte los directores guardados como texto en personas
+// POST /api/people/migrate-directors (restringido a admin)
+func MigrateDirectors(c *gin.Context) {
+	migrated, err := services.MigrateDirectorsToPeople()
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Error al migrar directores: "+err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":         "Directores migrados correctamente",
+		"movies_migrated": migrated,
+		"time":            time.Now().Format(time.RFC3339),
+	})
+}
+
+// GetMovieCredits devuelve el reparto y equipo técnico de una película
+// GET /api/movies/:movieId/credits
+func GetMovieCredits(c *gin.Context) {
+	movieID, err := strconv.ParseUint(c.Param("movieId"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "ID de película inválido")
+		return
+	}
+
+	credits, err := services.GetMovieCredits(uint(movieID))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Error al obtener créditos de la película")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"credits": credits,
+		"count":   len(credits),
+	})
+}
+
+// AddMovieCredit asocia una persona a una película
+// POST /api/movies/:movieId/credits (restringido a admin)
+func AddMovieCredit(c *gin.Context) {
+	movieID, err := strconv.ParseUint(c.Param("movieId"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "ID de película inválido")
+		return
+	}
+
+	var input struct {
+		PersonID     uint   `json:"person_id" binding:"required"`
+		Role         string `json:"role" binding:"required"`
+		Character    string `json:"character"`
+		BillingOrder int    `json:"billing_order"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Datos inválidos")
+		return
+	}
+
+	credit := models.MovieCredit{
+		MovieID:      uint(movieID),
+		PersonID:     input.PersonID,
+		Role:         models.CreditRole(strings.ToLower(input.Role)),
+		Character:    input.Character,
+		BillingOrder: input.BillingOrder,
+	}
+
+	if err := services.AddCreditToMovie(&credit); err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidCreditRole):
+			utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		case errors.Is(err, services.ErrCreditMovieNotFound), errors.Is(err, services.ErrCreditPersonNotFound):
+			utils.ErrorResponse(c, http.StatusNotFound, err.Error())
+		case errors.Is(err, services.ErrDuplicateCredit):
+			utils.ErrorResponse(c, http.StatusConflict, err.Error())
+		default:
+			utils.ErrorResponse(c, http.StatusInternalServerError, "Error al añadir el crédito")
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, credit)
+}
+
+// RemoveMovieCredit elimina un crédito de una película
+// DELETE /api/movies/:movieId/credits/:creditId (restringido a admin)
+func RemoveMovieCredit(c *gin.Context) {
+	movieID, err := strconv.ParseUint(c.Param("movieId"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "ID de película inválido")
+		return
+	}
+
+	creditID, err := strconv.ParseUint(c.Param("creditId"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "ID de crédito inválido")
+		return
+	}
+
+	if err := services.RemoveCreditFromMovie(uint(movieID), uint(creditID)); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.ErrorResponse(c, http.StatusNotFound, "Crédito no encontrado")
+			return
+		}
+		utils.ErrorResponse(c, http.StatusInternalServerError, "No se pudo eliminar el crédito")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Crédito eliminado correctamente"})
+}
@@ -0,0 +1,99 @@
+package controllers
+
+import (
+	"cine_conecta_backend/auth/utils"
+	"cine_conecta_backend/movies/imaging"
+	"cine_conecta_backend/movies/services"
+	"cine_conecta_backend/storage"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PosterPresignInput contiene los datos del archivo que el cliente va a subir
+type PosterPresignInput struct {
+	ContentType string `json:"content_type" binding:"required"`
+	Size        int64  `json:"size" binding:"required"`
+}
+
+// PosterCompleteInput identifica el archivo subido con la URL firmada
+type PosterCompleteInput struct {
+	Key string `json:"key" binding:"required"`
+}
+
+// PresignPosterUpload genera una URL firmada para subir el póster directamente al almacenamiento.
+// POST /api/movies/:movieId/poster/presign (restringido a admin)
+func PresignPosterUpload(c *gin.Context) {
+	movieID, err := strconv.ParseUint(c.Param("movieId"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "ID de película inválido")
+		return
+	}
+
+	var input PosterPresignInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Se requieren content_type y size")
+		return
+	}
+
+	upload, err := services.PresignPosterUpload(uint(movieID), input.ContentType, input.Size)
+	if err != nil {
+		respondPosterError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"upload":       upload,
+		"complete_url": "/api/movies/" + c.Param("movieId") + "/poster/complete",
+	})
+}
+
+// CompletePosterUpload verifica el archivo subido y actualiza el póster de la película.
+// POST /api/movies/:movieId/poster/complete (restringido a admin)
+func CompletePosterUpload(c *gin.Context) {
+	movieID, err := strconv.ParseUint(c.Param("movieId"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "ID de película inválido")
+		return
+	}
+
+	var input PosterCompleteInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Se requiere la clave (key) del archivo subido")
+		return
+	}
+
+	claims, _ := c.Get("claims")
+	userID := claims.(*utils.Claims).UserID
+
+	movie, err := services.CompletePosterUpload(uint(movieID), input.Key, userID)
+	if err != nil {
+		respondPosterError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":         "Póster subido correctamente",
+		"poster_url":      movie.PosterURL,
+		"poster_variants": movie.PosterVariants,
+		"movie":           movie,
+	})
+}
+
+// respondPosterError traduce los errores de subida de pósters a códigos HTTP
+func respondPosterError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, storage.ErrNotConfigured):
+		utils.ErrorResponse(c, http.StatusServiceUnavailable, err.Error())
+	case errors.Is(err, storage.ErrObjectNotFound):
+		utils.ErrorResponse(c, http.StatusNotFound, "El archivo aún no se ha subido al almacenamiento")
+	case errors.Is(err, imaging.ErrInvalidImage):
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+	case err.Error() == "película no encontrada":
+		utils.ErrorResponse(c, http.StatusNotFound, "Película no encontrada")
+	default:
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+	}
+}
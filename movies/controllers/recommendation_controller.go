@@ -15,7 +15,7 @@ func GetMyRecommendations(c *gin.Context) {
 	claims, _ := c.Get("claims")
 	userID := claims.(*utils.Claims).UserID
 
-	recommendations, err := services.GetRecommendedMovies(userID)
+	recommendations, err := services.GetRecommendedMovies(userID, contentRestrictions(c))
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Error al obtener recomendaciones")
 		return
@@ -28,6 +28,7 @@ func GetMyRecommendations(c *gin.Context) {
 	type RecommendationItem struct {
 		Movie       services.MovieWithDetails `json:"movie"`
 		Explanation string                    `json:"explanation"`
+		Source      string                    `json:"source"`
 	}
 
 	var response struct {
@@ -37,21 +38,34 @@ func GetMyRecommendations(c *gin.Context) {
 	}
 
 	// Generar explicaciones para cada recomendación
-	for _, movie := range recommendations {
+	for _, recommendation := range recommendations {
+		movie := recommendation.Movie
 		explanation := "Recomendado porque "
 
+		// Las recomendaciones colaborativas se explican por la película valorada que más aporta
+		explained := false
+		switch {
+		case recommendation.Source == services.RecommendationSourceCollaborative && recommendation.BecauseOf != "":
+			explanation += "te gustó " + recommendation.BecauseOf
+			explained = true
+		case recommendation.Source == services.RecommendationSourceFactorization:
+			explanation += "encaja con tus gustos según las películas que valoraste"
+			explained = true
+		}
+
 		// Comprobar si el género coincide con los favoritos
-		isGenreFavorite := false
-		for _, genre := range favoriteGenres {
-			if movie.Genre == genre {
-				explanation += "te gustan películas de " + genre
-				isGenreFavorite = true
-				break
+		if !explained {
+			for _, genre := range favoriteGenres {
+				if movie.Genre == genre {
+					explanation += "te gustan películas de " + genre
+					explained = true
+					break
+				}
 			}
 		}
 
 		// Si no es un género favorito, explicar por valoración
-		if !isGenreFavorite {
+		if !explained {
 			explanation += "es una película bien valorada"
 		}
 
@@ -63,6 +77,7 @@ func GetMyRecommendations(c *gin.Context) {
 		response.Recommendations = append(response.Recommendations, RecommendationItem{
 			Movie:       services.EnrichMovie(movie),
 			Explanation: explanation,
+			Source:      recommendation.Source,
 		})
 	}
 
@@ -70,7 +85,7 @@ func GetMyRecommendations(c *gin.Context) {
 
 	// Personalizar mensaje según si tenemos o no recomendaciones
 	if len(recommendations) > 0 {
-		response.Message = "Hemos encontrado " + strconv.Itoa(len(recommendations)) + " películas que podrían interesarte basadas en tus comentarios"
+		response.Message = "Hemos encontrado " + strconv.Itoa(len(recommendations)) + " películas que podrían interesarte basadas en tus comentarios y tus me gusta"
 	} else {
 		response.Message = "Aún no tenemos suficiente información para recomendarte películas personalizadas. Comenta más películas para mejorar tus recomendaciones."
 	}
@@ -86,7 +101,7 @@ func GetPopularRecommendations(c *gin.Context) {
 		limit = l
 	}
 
-	recommendations, err := services.GetMoviesByPositiveSentiment(limit)
+	recommendations, err := services.GetMoviesByPositiveSentiment(limit, contentRestrictions(c))
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Error al obtener recomendaciones populares")
 		return
@@ -132,3 +147,18 @@ func GetPopularRecommendations(c *gin.Context) {
 
 	c.JSON(http.StatusOK, response)
 }
+
+// RebuildMovieSimilarities recalcula la tabla de similitudes entre películas del filtrado colaborativo
+// POST /api/recommendations/similarities/rebuild (restringido a admin)
+func RebuildMovieSimilarities(c *gin.Context) {
+	report, err := services.BuildItemSimilarities()
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Error al calcular las similitudes: "+err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Similitudes entre películas recalculadas correctamente",
+		"report":  report,
+	})
+}
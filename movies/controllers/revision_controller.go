@@ -0,0 +1,69 @@
+package controllers
+
+import (
+	"cine_conecta_backend/auth/utils"
+	"cine_conecta_backend/movies/services"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// GetMovieRevisions devuelve el historial de cambios de una película
+// GET /api/movies/:movieId/revisions (restringido a admin)
+func GetMovieRevisions(c *gin.Context) {
+	movieID, ok := parseMovieIDParam(c)
+	if !ok {
+		return
+	}
+
+	revisions, err := services.GetMovieRevisions(movieID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.ErrorResponse(c, http.StatusNotFound, "Película no encontrada")
+			return
+		}
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Error al obtener el historial de cambios")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"movie_id":  movieID,
+		"revisions": revisions,
+		"count":     len(revisions),
+	})
+}
+
+// RevertMovieRevision devuelve una película al estado de una revisión anterior
+// POST /api/movies/:movieId/revisions/:revisionId/revert (restringido a admin)
+func RevertMovieRevision(c *gin.Context) {
+	movieID, ok := parseMovieIDParam(c)
+	if !ok {
+		return
+	}
+	revisionID, err := strconv.ParseUint(c.Param("revisionId"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "ID de revisión inválido")
+		return
+	}
+
+	claims, _ := c.Get("claims")
+	userID := claims.(*utils.Claims).UserID
+
+	movie, err := services.RevertMovieRevision(movieID, uint(revisionID), userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.ErrorResponse(c, http.StatusNotFound, "Revisión no encontrada")
+			return
+		}
+		utils.ErrorResponse(c, http.StatusInternalServerError, "No se pudo revertir la película")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Película revertida correctamente",
+		"movie":   movie,
+	})
+}
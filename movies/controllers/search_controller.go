@@ -38,6 +38,10 @@ func SearchMovies(c *gin.Context) {
 		Title:  title,
 		Genre:  genre,
 		Rating: rating,
+		Person: c.Query("person"),
+		Role:   c.Query("role"),
+
+		Restrictions: contentRestrictions(c),
 	}
 
 	// Realizar la búsqueda
@@ -49,6 +53,8 @@ func SearchMovies(c *gin.Context) {
 	}
 
 	fmt.Printf("[DEBUG-CONTROLLER] Búsqueda completada. Encontradas %d películas.\n", len(movies))
+	markWatchlist(c, movies)
+	localizeMovies(c, movies)
 
 	c.JSON(http.StatusOK, gin.H{
 		"results": movies,
@@ -0,0 +1,61 @@
+package controllers
+
+import (
+	"cine_conecta_backend/auth/utils"
+	"cine_conecta_backend/movies/models"
+	"cine_conecta_backend/movies/services"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// GetSimilarMovies devuelve las películas más parecidas a una dada con los motivos de cada una
+// GET /api/movies/:movieId/similar?limit=10
+func GetSimilarMovies(c *gin.Context) {
+	movieID, ok := parseMovieIDParam(c)
+	if !ok {
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil || limit < 1 || limit > 50 {
+		utils.ErrorResponse(c, http.StatusBadRequest, "El límite debe ser un número entre 1 y 50")
+		return
+	}
+
+	// Las películas no aptas para el usuario se tratan como inexistentes
+	restrictions := contentRestrictions(c)
+	if !services.IsMovieAllowed(restrictions, movieID) {
+		utils.ErrorResponse(c, http.StatusNotFound, "Película no encontrada")
+		return
+	}
+
+	similar, err := services.GetSimilarMovies(movieID, limit, restrictions)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.ErrorResponse(c, http.StatusNotFound, "Película no encontrada")
+			return
+		}
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Error al obtener películas similares")
+		return
+	}
+
+	movies := make([]models.Movie, len(similar))
+	for i := range similar {
+		movies[i] = similar[i].Movie
+	}
+	markWatchlist(c, movies)
+	localizeMovies(c, movies)
+	for i := range similar {
+		similar[i].Movie = movies[i]
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"movie_id": movieID,
+		"count":    len(similar),
+		"similar":  similar,
+	})
+}
@@ -0,0 +1,54 @@
+package controllers
+
+import (
+	"cine_conecta_backend/auth/utils"
+	"cine_conecta_backend/movies/services"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetMovieBySlug devuelve una película pública por su slug. Los slugs antiguos redirigen al actual.
+// Con sesión se aplican las restricciones de contenido del usuario y se marca su lista de pendientes.
+// GET /api/movies/by-slug/:slug
+func GetMovieBySlug(c *gin.Context) {
+	movie, redirected, err := services.ResolveMovieSlug(c.Param("slug"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Película no encontrada")
+		return
+	}
+	if redirected {
+		// Las películas no aptas para el usuario no revelan su slug actual
+		if !services.IsMovieAllowed(contentRestrictions(c), movie.ID) {
+			utils.ErrorResponse(c, http.StatusNotFound, "Película no encontrada")
+			return
+		}
+		c.Redirect(http.StatusMovedPermanently, "/api/movies/by-slug/"+movie.Slug)
+		return
+	}
+
+	movie, ok := loadMovieDetail(c, movie.ID)
+	if !ok {
+		return
+	}
+
+	c.Header("Link", `</api/movies/by-slug/`+movie.Slug+`>; rel="canonical"`)
+	c.JSON(http.StatusOK, movie)
+}
+
+// MigrateMovieSlugs genera el slug de las películas que todavía no lo tienen
+// POST /api/movies/migrate-slugs (restringido a admin)
+func MigrateMovieSlugs(c *gin.Context) {
+	migrated, err := services.MigrateMovieSlugs()
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Error al generar los slugs: "+err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":         "Slugs generados correctamente",
+		"movies_migrated": migrated,
+		"time":            time.Now().Format(time.RFC3339),
+	})
+}
@@ -1,17 +1,20 @@
 package controllers
 
 import (
+	"cine_conecta_backend/auth/utils"
 	movieServices "cine_conecta_backend/movies/services"
+	"errors"
 	"net/http"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
-// GetTopRatedMovies devuelve las 5 películas mejor valoradas según los comentarios
+// GetTopRatedMovies devuelve las 5 películas con mejor valoración ponderada según los comentarios
 func GetTopRatedMovies(c *gin.Context) {
 	// Obtener películas mejor valoradas
-	movies, err := movieServices.GetTopRatedMovies()
+	movies, err := movieServices.GetTopRatedMovies(contentRestrictions(c))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": err.Error(),
@@ -62,3 +65,24 @@ func GetMovieWithAverageScore(c *gin.Context) {
 		"movie":   movie,
 	})
 }
+
+// GetMovieRatingHistogram devuelve la distribución de las puntuaciones de los comentarios de una película
+// GET /api/movies/:movieId/rating-histogram
+func GetMovieRatingHistogram(c *gin.Context) {
+	movieID, ok := parseMovieIDParam(c)
+	if !ok {
+		return
+	}
+
+	histogram, err := movieServices.GetRatingHistogram(movieID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.ErrorResponse(c, http.StatusNotFound, "Película no encontrada")
+			return
+		}
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Error al obtener el histograma de puntuaciones")
+		return
+	}
+
+	c.JSON(http.StatusOK, histogram)
+}
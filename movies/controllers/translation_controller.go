@@ -0,0 +1,106 @@
+package controllers
+
+import (
+	"cine_conecta_backend/auth/utils"
+	"cine_conecta_backend/movies/models"
+	"cine_conecta_backend/movies/services"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Estructura para recibir la traducción de una película
+type TranslationInput struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+}
+
+// GetMovieTranslations devuelve las traducciones de una película
+// GET /api/movies/:movieId/translations
+func GetMovieTranslations(c *gin.Context) {
+	movieID, err := strconv.ParseUint(c.Param("movieId"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "ID de película inválido")
+		return
+	}
+
+	translations, err := services.GetMovieTranslations(uint(movieID))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.ErrorResponse(c, http.StatusNotFound, "Película no encontrada")
+			return
+		}
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Error al obtener las traducciones")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"default_locale": services.DefaultLocale(),
+		"translations":   translations,
+		"count":          len(translations),
+	})
+}
+
+// SaveMovieTranslation crea o reemplaza la traducción de una película a un idioma
+// PUT /api/movies/:movieId/translations/:locale (restringido a admin)
+func SaveMovieTranslation(c *gin.Context) {
+	movieID, err := strconv.ParseUint(c.Param("movieId"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "ID de película inválido")
+		return
+	}
+
+	var input TranslationInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Datos inválidos")
+		return
+	}
+
+	claims, _ := c.Get("claims")
+	userID := claims.(*utils.Claims).UserID
+
+	translation, err := services.SaveMovieTranslation(uint(movieID), c.Param("locale"), input.Title, input.Description, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.ErrorResponse(c, http.StatusNotFound, "Película no encontrada")
+			return
+		}
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, translation)
+}
+
+// DeleteMovieTranslation elimina la traducción de una película a un idioma
+// DELETE /api/movies/:movieId/translations/:locale (restringido a admin)
+func DeleteMovieTranslation(c *gin.Context) {
+	movieID, err := strconv.ParseUint(c.Param("movieId"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "ID de película inválido")
+		return
+	}
+
+	claims, _ := c.Get("claims")
+	userID := claims.(*utils.Claims).UserID
+
+	if err := services.DeleteMovieTranslation(uint(movieID), c.Param("locale"), userID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.ErrorResponse(c, http.StatusNotFound, "Traducción no encontrada")
+			return
+		}
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Traducción eliminada correctamente"})
+}
+
+// localizeMovies traduce las películas al idioma pedido con ?lang= o con la cabecera Accept-Language
+func localizeMovies(c *gin.Context, movies []models.Movie) {
+	c.Header("Vary", "Accept-Language")
+	services.LocalizeMovies(services.LocaleCandidates(c.Query("lang"), c.GetHeader("Accept-Language")), movies)
+}
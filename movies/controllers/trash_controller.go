@@ -0,0 +1,101 @@
+package controllers
+
+import (
+	"cine_conecta_backend/auth/utils"
+	"cine_conecta_backend/movies/services"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// GetTrashedMovies devuelve las películas de la papelera
+// GET /api/movies/trash (restringido a admin)
+func GetTrashedMovies(c *gin.Context) {
+	movies, err := services.GetTrashedMovies()
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Error al obtener la papelera")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"movies":         movies,
+		"count":          len(movies),
+		"retention_days": int(services.DefaultTrashRetention.Hours() / 24),
+	})
+}
+
+// RestoreMovie saca una película de la papelera
+// POST /api/movies/:movieId/restore (restringido a admin)
+func RestoreMovie(c *gin.Context) {
+	movieID, ok := parseMovieIDParam(c)
+	if !ok {
+		return
+	}
+
+	claims, _ := c.Get("claims")
+	userID := claims.(*utils.Claims).UserID
+
+	movie, err := services.RestoreMovie(movieID, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.ErrorResponse(c, http.StatusNotFound, "La película no está en la papelera")
+			return
+		}
+		utils.ErrorResponse(c, http.StatusInternalServerError, "No se pudo restaurar la película")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Película restaurada correctamente",
+		"movie":   movie,
+	})
+}
+
+// PurgeMovie elimina definitivamente una película de la papelera
+// DELETE /api/movies/:movieId/purge (restringido a admin)
+func PurgeMovie(c *gin.Context) {
+	movieID, ok := parseMovieIDParam(c)
+	if !ok {
+		return
+	}
+
+	if err := services.PurgeMovie(movieID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.ErrorResponse(c, http.StatusNotFound, "La película no está en la papelera")
+			return
+		}
+		if errors.Is(err, services.ErrMovieHasBookings) {
+			utils.ErrorResponse(c, http.StatusConflict, "La película tiene entradas vendidas; cancela las reservas antes de eliminarla")
+			return
+		}
+		utils.ErrorResponse(c, http.StatusInternalServerError, "No se pudo eliminar definitivamente la película")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Película eliminada definitivamente"})
+}
+
+// PurgeExpiredTrash elimina definitivamente las películas que superan el tiempo de retención
+// DELETE /api/movies/trash?older_than=720h&dry_run=true (restringido a admin)
+func PurgeExpiredTrash(c *gin.Context) {
+	olderThan := services.DefaultTrashRetention
+	if value := c.Query("older_than"); value != "" {
+		duration, err := time.ParseDuration(value)
+		if err != nil || duration < 0 {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Duración inválida (por ejemplo 720h)")
+			return
+		}
+		olderThan = duration
+	}
+
+	report, err := services.PurgeExpiredTrash(olderThan, c.Query("dry_run") == "true")
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Error al purgar la papelera")
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,60 @@
+package controllers
+
+import (
+	"cine_conecta_backend/auth/utils"
+	"cine_conecta_backend/movies/models"
+	"cine_conecta_backend/movies/services"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetTrendingMovies devuelve las películas en tendencia de una ventana (24h, 7d o 30d) con su movimiento
+// respecto a la instantánea anterior
+// GET /api/movies/trending?window=7d&limit=20
+func GetTrendingMovies(c *gin.Context) {
+	window := c.DefaultQuery("window", models.TrendingWindowWeek)
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil || limit < 1 || limit > 100 {
+		utils.ErrorResponse(c, http.StatusBadRequest, "El límite debe ser un número entre 1 y 100")
+		return
+	}
+
+	result, err := services.GetTrendingMovies(window, limit, contentRestrictions(c))
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidTrendingWindow) {
+			utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Error al obtener las películas en tendencia")
+		return
+	}
+
+	movies := make([]models.Movie, len(result.Movies))
+	for i := range result.Movies {
+		movies[i] = result.Movies[i].Movie
+	}
+	localizeMovies(c, movies)
+	for i := range result.Movies {
+		result.Movies[i].Movie = movies[i]
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// CreateTrendingSnapshots guarda una instantánea de las tendencias de cada ventana
+// POST /api/movies/trending/snapshots (restringido a admin)
+func CreateTrendingSnapshots(c *gin.Context) {
+	report, err := services.CreateTrendingSnapshots()
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Error al guardar las instantáneas de tendencias: "+err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Instantáneas de tendencias guardadas correctamente",
+		"report":  report,
+	})
+}
@@ -0,0 +1,179 @@
+package controllers
+
+import (
+	"cine_conecta_backend/auth/utils"
+	"cine_conecta_backend/movies/models"
+	"cine_conecta_backend/movies/services"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Estructura para recibir los datos de una película de la lista de pendientes
+type WatchlistInput struct {
+	MovieID  uint    `json:"movie_id"`
+	Notes    *string `json:"notes"`
+	Priority *int    `json:"priority"` // 1 = baja, 2 = media, 3 = alta
+}
+
+// GetWatchlist devuelve la lista de pendientes del usuario
+// GET /api/watchlist?genre=&priority=&sort=position|priority|added|title|release_date&order=asc|desc
+func GetWatchlist(c *gin.Context) {
+	claims, _ := c.Get("claims")
+	userID := claims.(*utils.Claims).UserID
+
+	filter := services.WatchlistFilter{
+		Genre: c.Query("genre"),
+		Sort:  c.Query("sort"),
+		Order: c.Query("order"),
+	}
+	if priority := c.Query("priority"); priority != "" {
+		value, err := strconv.Atoi(priority)
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Prioridad inválida")
+			return
+		}
+		filter.Priority = value
+	}
+
+	items, err := services.GetWatchlist(userID, filter)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"items": items,
+		"count": len(items),
+	})
+}
+
+// AddToWatchlist añade una película a la lista de pendientes
+// POST /api/watchlist
+func AddToWatchlist(c *gin.Context) {
+	var input WatchlistInput
+	if err := c.ShouldBindJSON(&input); err != nil || input.MovieID == 0 {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Se requiere el movie_id de la película")
+		return
+	}
+
+	claims, _ := c.Get("claims")
+	userID := claims.(*utils.Claims).UserID
+
+	notes := ""
+	if input.Notes != nil {
+		notes = *input.Notes
+	}
+	priority := 0
+	if input.Priority != nil {
+		priority = *input.Priority
+	}
+
+	item, err := services.AddToWatchlist(userID, input.MovieID, notes, priority)
+	if err != nil {
+		switch err.Error() {
+		case "película no encontrada":
+			utils.ErrorResponse(c, http.StatusNotFound, "Película no encontrada")
+		case "la película ya está en tu lista de pendientes":
+			utils.ErrorResponse(c, http.StatusConflict, "La película ya está en tu lista de pendientes")
+		default:
+			utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, item)
+}
+
+// UpdateWatchlistItem cambia las notas o la prioridad de una película de la lista
+// PUT /api/watchlist/:movieId
+func UpdateWatchlistItem(c *gin.Context) {
+	movieID, err := strconv.ParseUint(c.Param("movieId"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "ID de película inválido")
+		return
+	}
+
+	var input WatchlistInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Datos inválidos")
+		return
+	}
+
+	claims, _ := c.Get("claims")
+	userID := claims.(*utils.Claims).UserID
+
+	item, err := services.UpdateWatchlistItem(userID, uint(movieID), input.Notes, input.Priority)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.ErrorResponse(c, http.StatusNotFound, "La película no está en tu lista de pendientes")
+			return
+		}
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, item)
+}
+
+// RemoveFromWatchlist quita una película de la lista de pendientes
+// DELETE /api/watchlist/:movieId
+func RemoveFromWatchlist(c *gin.Context) {
+	movieID, err := strconv.ParseUint(c.Param("movieId"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "ID de película inválido")
+		return
+	}
+
+	claims, _ := c.Get("claims")
+	userID := claims.(*utils.Claims).UserID
+
+	if err := services.RemoveFromWatchlist(userID, uint(movieID)); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.ErrorResponse(c, http.StatusNotFound, "La película no está en tu lista de pendientes")
+			return
+		}
+		utils.ErrorResponse(c, http.StatusInternalServerError, "No se pudo quitar la película de la lista")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Película quitada de tu lista de pendientes"})
+}
+
+// ReorderWatchlist cambia el orden manual de la lista de pendientes
+// POST /api/watchlist/reorder
+func ReorderWatchlist(c *gin.Context) {
+	var input struct {
+		MovieIDs []uint `json:"movie_ids" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Se requiere la lista ordenada de movie_ids")
+		return
+	}
+
+	claims, _ := c.Get("claims")
+	userID := claims.(*utils.Claims).UserID
+
+	if err := services.ReorderWatchlist(userID, input.MovieIDs); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	items, _ := services.GetWatchlist(userID, services.WatchlistFilter{})
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Orden actualizado correctamente",
+		"items":   items,
+	})
+}
+
+// markWatchlist marca las películas que están en la lista de pendientes del usuario autenticado
+func markWatchlist(c *gin.Context, movies []models.Movie) {
+	claims, exists := c.Get("claims")
+	if !exists {
+		return
+	}
+	services.MarkWatchlisted(claims.(*utils.Claims).UserID, movies)
+}
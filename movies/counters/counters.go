@@ -0,0 +1,106 @@
+package counters
+
+import (
+	commentModels "cine_conecta_backend/comments/models"
+	"cine_conecta_backend/movies/models"
+
+	"gorm.io/gorm"
+)
+
+// avgScoreExpr calcula la media a partir de la suma y del número de comentarios ya actualizados
+const avgScoreExpr = "CASE WHEN comment_count + ? > 0 THEN (score_sum + ?) / (comment_count + ?) ELSE 0 END"
+
+// CommentAdded suma un comentario nuevo a los contadores de su película
+func CommentAdded(tx *gorm.DB, movieID uint, sentiment commentModels.SentimentType, score float64) error {
+	return applyCommentDelta(tx, movieID, 1, score, map[commentModels.SentimentType]int{sentiment: 1})
+}
+
+// CommentRemoved descuenta un comentario eliminado de los contadores de su película
+func CommentRemoved(tx *gorm.DB, movieID uint, sentiment commentModels.SentimentType, score float64) error {
+	return applyCommentDelta(tx, movieID, -1, -score, map[commentModels.SentimentType]int{sentiment: -1})
+}
+
+// CommentChanged actualiza los contadores cuando cambia el sentimiento o la puntuación de un comentario
+func CommentChanged(tx *gorm.DB, movieID uint, oldSentiment commentModels.SentimentType, oldScore float64,
+	newSentiment commentModels.SentimentType, newScore float64) error {
+	if oldSentiment == newSentiment && oldScore == newScore {
+		return nil
+	}
+
+	deltas := map[commentModels.SentimentType]int{oldSentiment: -1}
+	deltas[newSentiment]++
+	return applyCommentDelta(tx, movieID, 0, newScore-oldScore, deltas)
+}
+
+// LikeAdded suma un me gusta a la película
+func LikeAdded(tx *gorm.DB, movieID uint) error {
+	return applyLikeDelta(tx, movieID, 1)
+}
+
+// LikeRemoved descuenta un me gusta de la película
+func LikeRemoved(tx *gorm.DB, movieID uint) error {
+	return applyLikeDelta(tx, movieID, -1)
+}
+
+// Recompute recalcula desde cero los contadores de una película a partir de sus comentarios y me gusta
+func Recompute(tx *gorm.DB, movieID uint) error {
+	err := tx.Exec(`UPDATE movies SET
+			like_count = (SELECT COUNT(*) FROM movie_likes WHERE movie_likes.movie_id = movies.id AND movie_likes.deleted_at IS NULL),
+			comment_count = (SELECT COUNT(*) FROM comments WHERE comments.movie_id = movies.id),
+			score_sum = (SELECT COALESCE(SUM(sentiment_score), 0) FROM comments WHERE comments.movie_id = movies.id),
+			positive_count = (SELECT COUNT(*) FROM comments WHERE comments.movie_id = movies.id AND sentiment = ?),
+			neutral_count = (SELECT COUNT(*) FROM comments WHERE comments.movie_id = movies.id AND sentiment = ?),
+			negative_count = (SELECT COUNT(*) FROM comments WHERE comments.movie_id = movies.id AND sentiment = ?)
+		WHERE id = ?`,
+		commentModels.SentimentPositive, commentModels.SentimentNeutral, commentModels.SentimentNegative, movieID).Error
+	if err != nil {
+		return err
+	}
+	return applyCommentDelta(tx, movieID, 0, 0, nil)
+}
+
+// ResetAllComments deja a cero los contadores de comentarios de todas las películas
+func ResetAllComments(tx *gorm.DB) error {
+	return tx.Exec(`UPDATE movies SET comment_count = 0, score_sum = 0, avg_score = 0, rating = 0,
+		positive_count = 0, neutral_count = 0, negative_count = 0`).Error
+}
+
+// applyCommentDelta aplica en una sola sentencia los cambios de comentarios y recalcula la media.
+// La valoración (rating) de la película es la media de las puntuaciones de sus comentarios.
+func applyCommentDelta(tx *gorm.DB, movieID uint, count int, score float64, sentiments map[commentModels.SentimentType]int) error {
+	avg := gorm.Expr(avgScoreExpr, count, score, count)
+	columns := map[string]interface{}{
+		"comment_count": gorm.Expr("comment_count + ?", count),
+		"score_sum":     gorm.Expr("score_sum + ?", score),
+		"avg_score":     avg,
+		"rating":        avg,
+	}
+	for sentiment, delta := range sentiments {
+		if column := sentimentColumn(sentiment); column != "" && delta != 0 {
+			columns[column] = gorm.Expr(column+" + ?", delta)
+		}
+	}
+
+	// Se actualizan también las películas en la papelera para que sus contadores sigan siendo exactos
+	return tx.Unscoped().Model(&models.Movie{}).Where("id = ?", movieID).UpdateColumns(columns).Error
+}
+
+// applyLikeDelta suma o resta me gusta sin bajar de cero
+func applyLikeDelta(tx *gorm.DB, movieID uint, delta int) error {
+	return tx.Unscoped().Model(&models.Movie{}).Where("id = ?", movieID).
+		UpdateColumn("like_count", gorm.Expr("GREATEST(like_count + ?, 0)", delta)).Error
+}
+
+// sentimentColumn devuelve la columna del contador de un sentimiento
+func sentimentColumn(sentiment commentModels.SentimentType) string {
+	switch sentiment {
+	case commentModels.SentimentPositive:
+		return "positive_count"
+	case commentModels.SentimentNeutral:
+		return "neutral_count"
+	case commentModels.SentimentNegative:
+		return "negative_count"
+	default:
+		return ""
+	}
+}
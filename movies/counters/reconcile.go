@@ -0,0 +1,119 @@
+package counters
+
+import (
+	commentModels "cine_conecta_backend/comments/models"
+	"fmt"
+	"math"
+
+	"gorm.io/gorm"
+)
+
+// Mismatch describe una película cuyos contadores no coinciden con los comentarios y me gusta reales
+type Mismatch struct {
+	MovieID  uint                `json:"movie_id"`
+	Title    string              `json:"title"`
+	Fields   map[string][2]int64 `json:"fields"`              // Columna -> [guardado, real]
+	AvgScore *[2]float64         `json:"avg_score,omitempty"` // [guardada, real]
+}
+
+// ReconcileReport resume la verificación de los contadores
+type ReconcileReport struct {
+	DryRun     bool       `json:"dry_run"`
+	Checked    int        `json:"checked"`
+	Mismatches []Mismatch `json:"mismatches"`
+	Repaired   int        `json:"repaired"`
+	Errors     []string   `json:"errors,omitempty"`
+}
+
+// Reconcile compara los contadores de todas las películas (incluidas las de la papelera) con los
+// comentarios y me gusta reales. Salvo en modo dryRun, recalcula las películas que no coinciden.
+// Recibe la conexión para poder usarse también al migrar, antes de que config.DB esté disponible.
+func Reconcile(db *gorm.DB, dryRun bool) (*ReconcileReport, error) {
+	var rows []struct {
+		ID                    uint
+		Title                 string
+		LikeCount             int64
+		CommentCount          int64
+		ScoreSum              float64
+		AvgScore              float64
+		Rating                float64
+		PositiveCount         int64
+		NeutralCount          int64
+		NegativeCount         int64
+		ExpectedLikes         int64
+		ExpectedComments      int64
+		ExpectedScoreSum      float64
+		ExpectedPositiveCount int64
+		ExpectedNeutralCount  int64
+		ExpectedNegativeCount int64
+	}
+	err := db.Raw(`SELECT m.id, m.title, m.like_count, m.comment_count, m.score_sum, m.avg_score, m.rating,
+			m.positive_count, m.neutral_count, m.negative_count,
+			COALESCE(l.total, 0) AS expected_likes,
+			COALESCE(c.total, 0) AS expected_comments,
+			COALESCE(c.score_sum, 0) AS expected_score_sum,
+			COALESCE(c.positive, 0) AS expected_positive_count,
+			COALESCE(c.neutral, 0) AS expected_neutral_count,
+			COALESCE(c.negative, 0) AS expected_negative_count
+		FROM movies m
+		LEFT JOIN (
+			SELECT movie_id, COUNT(*) AS total FROM movie_likes WHERE deleted_at IS NULL GROUP BY movie_id
+		) l ON l.movie_id = m.id
+		LEFT JOIN (
+			SELECT movie_id, COUNT(*) AS total, SUM(sentiment_score) AS score_sum,
+				COUNT(*) FILTER (WHERE sentiment = ?) AS positive,
+				COUNT(*) FILTER (WHERE sentiment = ?) AS neutral,
+				COUNT(*) FILTER (WHERE sentiment = ?) AS negative
+			FROM comments GROUP BY movie_id
+		) c ON c.movie_id = m.id
+		ORDER BY m.id`,
+		commentModels.SentimentPositive, commentModels.SentimentNeutral, commentModels.SentimentNegative).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	report := &ReconcileReport{DryRun: dryRun, Checked: len(rows), Mismatches: []Mismatch{}}
+	for _, row := range rows {
+		mismatch := Mismatch{MovieID: row.ID, Title: row.Title, Fields: map[string][2]int64{}}
+		compare := func(column string, stored, expected int64) {
+			if stored != expected {
+				mismatch.Fields[column] = [2]int64{stored, expected}
+			}
+		}
+		compare("like_count", row.LikeCount, row.ExpectedLikes)
+		compare("comment_count", row.CommentCount, row.ExpectedComments)
+		compare("positive_count", row.PositiveCount, row.ExpectedPositiveCount)
+		compare("neutral_count", row.NeutralCount, row.ExpectedNeutralCount)
+		compare("negative_count", row.NegativeCount, row.ExpectedNegativeCount)
+
+		expectedAvg := 0.0
+		if row.ExpectedComments > 0 {
+			expectedAvg = row.ExpectedScoreSum / float64(row.ExpectedComments)
+		}
+		// La valoración se guarda en precisión simple, así que se compara con más margen
+		if math.Abs(row.ScoreSum-row.ExpectedScoreSum) > 1e-6 || math.Abs(row.AvgScore-expectedAvg) > 1e-6 ||
+			math.Abs(row.Rating-expectedAvg) > 1e-3 {
+			mismatch.AvgScore = &[2]float64{row.AvgScore, expectedAvg}
+		}
+
+		if len(mismatch.Fields) == 0 && mismatch.AvgScore == nil {
+			continue
+		}
+		report.Mismatches = append(report.Mismatches, mismatch)
+
+		if dryRun {
+			continue
+		}
+		if err := Recompute(db, row.ID); err != nil {
+			report.Errors = append(report.Errors, fmt.Sprintf("película %d: %v", row.ID, err))
+			continue
+		}
+		report.Repaired++
+	}
+
+	fmt.Printf("[DEBUG-COUNTERS] Contadores verificados (dry_run=%t): %d películas, %d con diferencias, %d reparadas\n",
+		dryRun, report.Checked, len(report.Mismatches), report.Repaired)
+
+	return report, nil
+}
@@ -0,0 +1,24 @@
+package movies
+
+import (
+	"cine_conecta_backend/config"
+	"cine_conecta_backend/movies/counters"
+	"encoding/json"
+	"fmt"
+)
+
+// ReconcileCounters verifica los contadores de las películas, los repara salvo en modo dryRun y muestra el reporte
+func ReconcileCounters(dryRun bool) error {
+	report, err := counters.Reconcile(config.DB, dryRun)
+	if err != nil {
+		return err
+	}
+
+	output, _ := json.MarshalIndent(report, "", "  ")
+	fmt.Println(string(output))
+
+	if len(report.Errors) > 0 {
+		return fmt.Errorf("%d películas no se pudieron reparar", len(report.Errors))
+	}
+	return nil
+}
@@ -0,0 +1,248 @@
+package factorization
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"runtime"
+	"sync"
+)
+
+// Interaction es la preferencia de un usuario (fila) por una película (columna) de la matriz.
+// Un valor positivo indica que le gusta y uno negativo que no; el valor absoluto es la intensidad.
+type Interaction struct {
+	User  int
+	Item  int
+	Value float64
+}
+
+// Config son los parámetros del entrenamiento
+type Config struct {
+	Factors        int     `json:"factors"`        // Dimensión de los vectores latentes
+	Iterations     int     `json:"iterations"`     // Pasadas de mínimos cuadrados alternos
+	Regularization float64 `json:"regularization"` // Penalización de la norma de los vectores (lambda)
+	Alpha          float64 `json:"alpha"`          // Confianza que aporta cada unidad de preferencia
+	Seed           int64   `json:"seed"`           // Semilla de la inicialización aleatoria
+}
+
+// DefaultConfig devuelve los parámetros por defecto del entrenamiento
+func DefaultConfig() Config {
+	return Config{Factors: 32, Iterations: 15, Regularization: 0.1, Alpha: 40, Seed: 42}
+}
+
+// Validate comprueba que los parámetros sean utilizables
+func (c Config) Validate() error {
+	switch {
+	case c.Factors < 1 || c.Factors > 256:
+		return errors.New("el número de factores debe estar entre 1 y 256")
+	case c.Iterations < 1:
+		return errors.New("el número de iteraciones debe ser al menos 1")
+	case c.Regularization <= 0:
+		return errors.New("la regularización debe ser mayor que 0")
+	case c.Alpha < 0:
+		return errors.New("alpha no puede ser negativo")
+	}
+	return nil
+}
+
+// Model son los vectores latentes de usuarios y películas. La afinidad de un usuario con una
+// película es el producto escalar de sus vectores.
+type Model struct {
+	UserFactors [][]float64
+	ItemFactors [][]float64
+	Loss        []float64 // Función de coste tras cada iteración
+}
+
+// entry es una celda observada de la matriz vista desde una fila o una columna
+type entry struct {
+	index      int
+	confidence float64
+	preference float64
+}
+
+// Train factoriza la matriz de interacciones implícitas con mínimos cuadrados alternos (ALS) según
+// Hu, Koren y Volinsky: cada celda observada tiene preferencia 1 si el valor es positivo y 0 si no,
+// con confianza 1 + alpha·|valor|; las no observadas tienen preferencia 0 y confianza 1.
+func Train(users, items int, interactions []Interaction, cfg Config) (*Model, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	if users == 0 || items == 0 || len(interactions) == 0 {
+		return nil, errors.New("no hay interacciones para entrenar el modelo")
+	}
+
+	byUser := make([][]entry, users)
+	byItem := make([][]entry, items)
+	for _, interaction := range interactions {
+		if interaction.User < 0 || interaction.User >= users || interaction.Item < 0 || interaction.Item >= items {
+			return nil, errors.New("interacción fuera de los límites de la matriz")
+		}
+		preference := 0.0
+		if interaction.Value > 0 {
+			preference = 1
+		}
+		confidence := 1 + cfg.Alpha*math.Abs(interaction.Value)
+		byUser[interaction.User] = append(byUser[interaction.User], entry{interaction.Item, confidence, preference})
+		byItem[interaction.Item] = append(byItem[interaction.Item], entry{interaction.User, confidence, preference})
+	}
+
+	rng := rand.New(rand.NewSource(cfg.Seed))
+	model := &Model{
+		UserFactors: randomFactors(rng, users, cfg.Factors),
+		ItemFactors: randomFactors(rng, items, cfg.Factors),
+	}
+
+	for i := 0; i < cfg.Iterations; i++ {
+		solveFactors(model.UserFactors, model.ItemFactors, byUser, cfg)
+		solveFactors(model.ItemFactors, model.UserFactors, byItem, cfg)
+		model.Loss = append(model.Loss, loss(model, byUser, cfg))
+	}
+
+	return model, nil
+}
+
+// Dot calcula el producto escalar de dos vectores
+func Dot(a, b []float64) float64 {
+	var sum float64
+	for i := range a {
+		sum += a[i] * b[i]
+	}
+	return sum
+}
+
+// randomFactors inicializa los vectores con valores pequeños
+func randomFactors(rng *rand.Rand, rows, factors int) [][]float64 {
+	matrix := make([][]float64, rows)
+	for i := range matrix {
+		matrix[i] = make([]float64, factors)
+		for j := range matrix[i] {
+			matrix[i][j] = rng.NormFloat64() * 0.01
+		}
+	}
+	return matrix
+}
+
+// solveFactors recalcula cada vector de target con los de fixed fijos resolviendo
+// (YᵀY + Yᵀ(Cu − I)Y + λI)·x = YᵀCu·p. YᵀY se calcula una vez y cada fila solo suma sus celdas observadas.
+func solveFactors(target, fixed [][]float64, rows [][]entry, cfg Config) {
+	k := cfg.Factors
+	gram := gramMatrix(fixed, k)
+
+	workers := runtime.NumCPU()
+	var wg sync.WaitGroup
+	next := make(chan int, len(target))
+	for row := range target {
+		next <- row
+	}
+	close(next)
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			a := make([]float64, k*k)
+			b := make([]float64, k)
+			for row := range next {
+				copy(a, gram)
+				for i := 0; i < k; i++ {
+					a[i*k+i] += cfg.Regularization
+					b[i] = 0
+				}
+				for _, cell := range rows[row] {
+					y := fixed[cell.index]
+					extra := cell.confidence - 1
+					for i := 0; i < k; i++ {
+						for j := 0; j < k; j++ {
+							a[i*k+j] += extra * y[i] * y[j]
+						}
+						b[i] += cell.confidence * cell.preference * y[i]
+					}
+				}
+				choleskySolve(a, b, k, target[row])
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// gramMatrix calcula YᵀY (k×k, por filas)
+func gramMatrix(factors [][]float64, k int) []float64 {
+	gram := make([]float64, k*k)
+	for _, y := range factors {
+		for i := 0; i < k; i++ {
+			for j := i; j < k; j++ {
+				gram[i*k+j] += y[i] * y[j]
+			}
+		}
+	}
+	for i := 0; i < k; i++ {
+		for j := 0; j < i; j++ {
+			gram[i*k+j] = gram[j*k+i]
+		}
+	}
+	return gram
+}
+
+// choleskySolve resuelve a·x = b para una matriz simétrica definida positiva (a se sobrescribe)
+func choleskySolve(a, b []float64, k int, x []float64) {
+	// Descomposición a = L·Lᵀ guardando L en la parte inferior de a
+	for i := 0; i < k; i++ {
+		for j := 0; j <= i; j++ {
+			sum := a[i*k+j]
+			for p := 0; p < j; p++ {
+				sum -= a[i*k+p] * a[j*k+p]
+			}
+			if i == j {
+				a[i*k+i] = math.Sqrt(math.Max(sum, 1e-12))
+			} else {
+				a[i*k+j] = sum / a[j*k+j]
+			}
+		}
+	}
+
+	// L·z = b
+	for i := 0; i < k; i++ {
+		sum := b[i]
+		for p := 0; p < i; p++ {
+			sum -= a[i*k+p] * x[p]
+		}
+		x[i] = sum / a[i*k+i]
+	}
+	// Lᵀ·x = z
+	for i := k - 1; i >= 0; i-- {
+		sum := x[i]
+		for p := i + 1; p < k; p++ {
+			sum -= a[p*k+i] * x[p]
+		}
+		x[i] = sum / a[i*k+i]
+	}
+}
+
+// loss calcula Σ c·(p − xᵀy)² sobre toda la matriz más la regularización. Las celdas no observadas
+// se suman de golpe con Σ xᵀ(YᵀY)x y las observadas corrigen ese término.
+func loss(model *Model, byUser [][]entry, cfg Config) float64 {
+	k := cfg.Factors
+	gram := gramMatrix(model.ItemFactors, k)
+
+	var total, norms float64
+	for u, x := range model.UserFactors {
+		for i := 0; i < k; i++ {
+			var row float64
+			for j := 0; j < k; j++ {
+				row += gram[i*k+j] * x[j]
+			}
+			total += x[i] * row
+		}
+		for _, cell := range byUser[u] {
+			score := Dot(x, model.ItemFactors[cell.index])
+			diff := cell.preference - score
+			total += cell.confidence*diff*diff - score*score
+		}
+		norms += Dot(x, x)
+	}
+	for _, y := range model.ItemFactors {
+		norms += Dot(y, y)
+	}
+
+	return total + cfg.Regularization*norms
+}
@@ -0,0 +1,193 @@
+package factorization
+
+import (
+	"math"
+	"testing"
+)
+
+// blockInteractions genera dos grupos de usuarios con gustos opuestos: los del primer grupo ven las
+// primeras películas y los del segundo las últimas. Cada usuario deja sin ver una película de su grupo.
+func blockInteractions(users, items int) []Interaction {
+	var interactions []Interaction
+	for u := 0; u < users; u++ {
+		first, last := 0, items/2
+		if u >= users/2 {
+			first, last = items/2, items
+		}
+		for i := first; i < last; i++ {
+			if i == first+u%(last-first) {
+				continue
+			}
+			interactions = append(interactions, Interaction{User: u, Item: i, Value: 1})
+		}
+	}
+	return interactions
+}
+
+func TestTrainLossDoesNotIncrease(t *testing.T) {
+	cfg := Config{Factors: 4, Iterations: 10, Regularization: 0.1, Alpha: 10, Seed: 1}
+	model, err := Train(8, 8, blockInteractions(8, 8), cfg)
+	if err != nil {
+		t.Fatalf("Train: %v", err)
+	}
+	if len(model.Loss) != cfg.Iterations {
+		t.Fatalf("len(Loss) = %d; se esperaban %d", len(model.Loss), cfg.Iterations)
+	}
+
+	for i := 1; i < len(model.Loss); i++ {
+		// Margen para los errores de redondeo cuando el coste ya ha convergido
+		if model.Loss[i] > model.Loss[i-1]*(1+1e-9) {
+			t.Fatalf("el coste aumentó en la iteración %d: %g -> %g", i, model.Loss[i-1], model.Loss[i])
+		}
+	}
+	if model.Loss[len(model.Loss)-1] >= model.Loss[0] {
+		t.Fatalf("el coste no bajó: %v", model.Loss)
+	}
+}
+
+func TestTrainObservedPositivesScoreHigher(t *testing.T) {
+	const users, items = 8, 8
+	interactions := blockInteractions(users, items)
+	model, err := Train(users, items, interactions, Config{Factors: 4, Iterations: 15, Regularization: 0.1, Alpha: 10, Seed: 7})
+	if err != nil {
+		t.Fatalf("Train: %v", err)
+	}
+
+	observed := make(map[[2]int]bool)
+	for _, interaction := range interactions {
+		observed[[2]int{interaction.User, interaction.Item}] = true
+	}
+
+	for u := 0; u < users; u++ {
+		minObserved, maxUnobserved := math.Inf(1), math.Inf(-1)
+		for i := 0; i < items; i++ {
+			score := Dot(model.UserFactors[u], model.ItemFactors[i])
+			if observed[[2]int{u, i}] {
+				minObserved = math.Min(minObserved, score)
+			} else if (u < users/2) != (i < items/2) {
+				// Películas del otro grupo: ningún usuario parecido las ha visto
+				maxUnobserved = math.Max(maxUnobserved, score)
+			}
+		}
+		if minObserved <= maxUnobserved {
+			t.Errorf("usuario %d: la menor puntuación observada (%.3f) no supera a la mayor no observada (%.3f)",
+				u, minObserved, maxUnobserved)
+		}
+	}
+}
+
+func TestTrainRanksHeldOutItemAboveOtherGroup(t *testing.T) {
+	const users, items = 8, 8
+	model, err := Train(users, items, blockInteractions(users, items), Config{Factors: 4, Iterations: 15, Regularization: 0.1, Alpha: 10, Seed: 3})
+	if err != nil {
+		t.Fatalf("Train: %v", err)
+	}
+
+	// La película que cada usuario no vio de su grupo debe puntuar más que las del otro grupo
+	for u := 0; u < users; u++ {
+		first, last, otherFirst, otherLast := 0, items/2, items/2, items
+		if u >= users/2 {
+			first, last, otherFirst, otherLast = items/2, items, 0, items/2
+		}
+		heldOut := first + u%(last-first)
+		heldOutScore := Dot(model.UserFactors[u], model.ItemFactors[heldOut])
+		for i := otherFirst; i < otherLast; i++ {
+			if score := Dot(model.UserFactors[u], model.ItemFactors[i]); score >= heldOutScore {
+				t.Errorf("usuario %d: la película %d del otro grupo (%.3f) puntúa más que la no vista %d (%.3f)",
+					u, i, score, heldOut, heldOutScore)
+			}
+		}
+	}
+}
+
+func TestTrainIsDeterministic(t *testing.T) {
+	cfg := Config{Factors: 3, Iterations: 5, Regularization: 0.1, Alpha: 10, Seed: 11}
+	first, err := Train(8, 8, blockInteractions(8, 8), cfg)
+	if err != nil {
+		t.Fatalf("Train: %v", err)
+	}
+	second, err := Train(8, 8, blockInteractions(8, 8), cfg)
+	if err != nil {
+		t.Fatalf("Train: %v", err)
+	}
+
+	for u := range first.UserFactors {
+		for f := range first.UserFactors[u] {
+			if first.UserFactors[u][f] != second.UserFactors[u][f] {
+				t.Fatalf("con la misma semilla los factores difieren en el usuario %d", u)
+			}
+		}
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	if err := DefaultConfig().Validate(); err != nil {
+		t.Fatalf("la configuración por defecto no es válida: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		cfg  Config
+	}{
+		{"sin factores", Config{Factors: 0, Iterations: 1, Regularization: 0.1}},
+		{"demasiados factores", Config{Factors: 257, Iterations: 1, Regularization: 0.1}},
+		{"sin iteraciones", Config{Factors: 2, Iterations: 0, Regularization: 0.1}},
+		{"regularización cero", Config{Factors: 2, Iterations: 1, Regularization: 0}},
+		{"alpha negativo", Config{Factors: 2, Iterations: 1, Regularization: 0.1, Alpha: -1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.cfg.Validate(); err == nil {
+				t.Fatalf("se esperaba un error con %+v", tt.cfg)
+			}
+			if _, err := Train(2, 2, []Interaction{{User: 0, Item: 0, Value: 1}}, tt.cfg); err == nil {
+				t.Fatal("Train debería rechazar la configuración")
+			}
+		})
+	}
+
+	// Alpha cero es válido: todas las celdas observadas tienen confianza 1
+	if err := (Config{Factors: 1, Iterations: 1, Regularization: 0.1}).Validate(); err != nil {
+		t.Fatalf("alpha cero debería ser válido: %v", err)
+	}
+}
+
+func TestTrainRejectsInvalidInput(t *testing.T) {
+	cfg := Config{Factors: 2, Iterations: 1, Regularization: 0.1, Alpha: 1}
+
+	tests := []struct {
+		name         string
+		users, items int
+		interactions []Interaction
+	}{
+		{"sin interacciones", 2, 2, nil},
+		{"sin usuarios", 0, 2, []Interaction{{User: 0, Item: 0, Value: 1}}},
+		{"sin películas", 2, 0, []Interaction{{User: 0, Item: 0, Value: 1}}},
+		{"usuario negativo", 2, 2, []Interaction{{User: -1, Item: 0, Value: 1}}},
+		{"usuario fuera de rango", 2, 2, []Interaction{{User: 2, Item: 0, Value: 1}}},
+		{"película negativa", 2, 2, []Interaction{{User: 0, Item: -1, Value: 1}}},
+		{"película fuera de rango", 2, 2, []Interaction{{User: 0, Item: 2, Value: 1}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Train(tt.users, tt.items, tt.interactions, cfg); err == nil {
+				t.Fatal("se esperaba un error")
+			}
+		})
+	}
+}
+
+func TestCholeskySolve(t *testing.T) {
+	// a = [[4, 2, 0], [2, 5, 1], [0, 1, 3]] y x = [1, -1, 2] dan b = a·x = [2, -1, 5]
+	a := []float64{4, 2, 0, 2, 5, 1, 0, 1, 3}
+	b := []float64{2, -1, 5}
+	want := []float64{1, -1, 2}
+
+	x := make([]float64, 3)
+	choleskySolve(a, b, 3, x)
+	for i := range want {
+		if math.Abs(x[i]-want[i]) > 1e-9 {
+			t.Fatalf("x = %v; se esperaba %v", x, want)
+		}
+	}
+}
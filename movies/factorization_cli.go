@@ -0,0 +1,42 @@
+package movies
+
+import (
+	"cine_conecta_backend/movies/factorization"
+	"cine_conecta_backend/movies/services"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// ExportInteractions exporta la matriz de interacciones usuario×película a un archivo CSV
+func ExportInteractions(path string) error {
+	rows, err := services.LoadInteractionMatrix()
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("no se pudo crear %s: %w", path, err)
+	}
+	defer file.Close()
+
+	if err := services.WriteInteractionsCSV(file, rows); err != nil {
+		return err
+	}
+
+	fmt.Printf("✅ %d interacciones exportadas a %s\n", len(rows), path)
+	return nil
+}
+
+// TrainFactors entrena el modelo de factores latentes, guarda los vectores y muestra el reporte
+func TrainFactors(cfg factorization.Config) error {
+	report, err := services.TrainFactorModel(cfg)
+	if err != nil {
+		return err
+	}
+
+	output, _ := json.MarshalIndent(report, "", "  ")
+	fmt.Println(string(output))
+	return nil
+}
@@ -0,0 +1,209 @@
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	_ "image/jpeg" // Registrar decodificador JPEG
+	_ "image/png"  // Registrar decodificador PNG
+	"io"
+
+	"github.com/HugoSmits86/nativewebp"
+	"github.com/buckket/go-blurhash"
+	xdraw "golang.org/x/image/draw"
+	_ "golang.org/x/image/webp" // Registrar decodificador WEBP
+)
+
+// Anchos estándar de las variantes del póster (en píxeles)
+var PosterWidths = []int{185, 342, 500, 780}
+
+// Anchos estándar de las variantes de fondos y fotogramas (en píxeles)
+var BackdropWidths = []int{300, 780, 1280}
+
+const (
+	// Límite de píxeles para evitar imágenes que agoten la memoria al decodificarlas
+	maxPosterPixels = 50_000_000
+	// Ancho máximo de la variante "original"
+	maxOriginalWidth = 2000
+	// Tamaño de la miniatura usada para el blurhash y el color dominante
+	placeholderWidth = 32
+)
+
+// ErrInvalidImage indica que el archivo no es una imagen válida o tiene un formato no soportado
+var ErrInvalidImage = errors.New("el archivo no es una imagen válida (solo JPEG, PNG o WEBP)")
+
+// Variant es una versión redimensionada de la imagen codificada en WebP
+type Variant struct {
+	Name   string // w185, w342, w500... u original
+	Width  int
+	Height int
+	Data   []byte
+}
+
+// ProcessedImage contiene las variantes generadas y los datos para los placeholders
+type ProcessedImage struct {
+	Format        string // Formato detectado del archivo original
+	Width         int
+	Height        int
+	Variants      []Variant
+	Blurhash      string
+	DominantColor string // Color en formato #rrggbb
+}
+
+// ProcessPoster procesa un póster con los anchos estándar de pósters
+func ProcessPoster(r io.Reader) (*ProcessedImage, error) {
+	return ProcessImage(r, PosterWidths)
+}
+
+// ProcessImage decodifica la imagen, genera las variantes WebP con los anchos indicados
+// y calcula el blurhash y el color dominante
+func ProcessImage(r io.Reader, widths []int) (*ProcessedImage, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("error al leer la imagen: %w", err)
+	}
+
+	// Comprobar cabecera y dimensiones antes de decodificar la imagen completa
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil || !isSupportedFormat(format) {
+		return nil, ErrInvalidImage
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 || cfg.Width*cfg.Height > maxPosterPixels {
+		return nil, errors.New("dimensiones de la imagen no permitidas")
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrInvalidImage
+	}
+
+	bounds := img.Bounds()
+	result := &ProcessedImage{
+		Format: format,
+		Width:  bounds.Dx(),
+		Height: bounds.Dy(),
+	}
+
+	for _, width := range widths {
+		// No se generan variantes más grandes que el original
+		if width > result.Width {
+			continue
+		}
+		variant, err := encodeVariant(img, fmt.Sprintf("w%d", width), width)
+		if err != nil {
+			return nil, err
+		}
+		result.Variants = append(result.Variants, *variant)
+	}
+
+	originalWidth := result.Width
+	if originalWidth > maxOriginalWidth {
+		originalWidth = maxOriginalWidth
+	}
+	original, err := encodeVariant(img, "original", originalWidth)
+	if err != nil {
+		return nil, err
+	}
+	result.Variants = append(result.Variants, *original)
+
+	thumb := resize(img, placeholderWidth)
+	hash, err := blurhash.Encode(4, 3, thumb)
+	if err != nil {
+		return nil, fmt.Errorf("error al calcular blurhash: %w", err)
+	}
+	result.Blurhash = hash
+	result.DominantColor = dominantColor(thumb)
+
+	return result, nil
+}
+
+// encodeVariant redimensiona la imagen al ancho indicado y la codifica en WebP
+func encodeVariant(img image.Image, name string, width int) (*Variant, error) {
+	resized := resize(img, width)
+
+	var buf bytes.Buffer
+	if err := nativewebp.Encode(&buf, resized, nil); err != nil {
+		return nil, fmt.Errorf("error al codificar variante %s: %w", name, err)
+	}
+
+	return &Variant{
+		Name:   name,
+		Width:  resized.Bounds().Dx(),
+		Height: resized.Bounds().Dy(),
+		Data:   buf.Bytes(),
+	}, nil
+}
+
+// resize escala la imagen al ancho indicado manteniendo la proporción
+func resize(img image.Image, width int) *image.NRGBA {
+	bounds := img.Bounds()
+	height := bounds.Dy() * width / bounds.Dx()
+	if height < 1 {
+		height = 1
+	}
+
+	dst := image.NewNRGBA(image.Rect(0, 0, width, height))
+	if width == bounds.Dx() && height == bounds.Dy() {
+		draw.Draw(dst, dst.Bounds(), img, bounds.Min, draw.Src)
+		return dst
+	}
+	xdraw.CatmullRom.Scale(dst, dst.Bounds(), img, bounds, xdraw.Src, nil)
+	return dst
+}
+
+// dominantColor agrupa los píxeles en cubetas de color y devuelve la media de la más poblada
+func dominantColor(img *image.NRGBA) string {
+	type bucket struct {
+		count   int
+		r, g, b int
+	}
+	buckets := make(map[int]*bucket)
+	var best *bucket
+
+	bounds := img.Bounds()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			c := img.NRGBAAt(x, y)
+			if c.A < 128 {
+				continue // Ignorar píxeles transparentes
+			}
+			key := int(c.R>>4)<<8 | int(c.G>>4)<<4 | int(c.B>>4)
+			b, ok := buckets[key]
+			if !ok {
+				b = &bucket{}
+				buckets[key] = b
+			}
+			b.count++
+			b.r += int(c.R)
+			b.g += int(c.G)
+			b.b += int(c.B)
+			if best == nil || b.count > best.count {
+				best = b
+			}
+		}
+	}
+
+	if best == nil {
+		return "#000000"
+	}
+	return fmt.Sprintf("#%02x%02x%02x", best.r/best.count, best.g/best.count, best.b/best.count)
+}
+
+// isSupportedFormat indica si el formato detectado está permitido
+func isSupportedFormat(format string) bool {
+	return format == "jpeg" || format == "png" || format == "webp"
+}
+
+// MimeForFormat devuelve el tipo MIME correspondiente al formato detectado
+func MimeForFormat(format string) string {
+	switch format {
+	case "png":
+		return "image/png"
+	case "webp":
+		return "image/webp"
+	default:
+		return "image/jpeg"
+	}
+}
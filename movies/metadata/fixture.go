@@ -0,0 +1,77 @@
+package metadata
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// FixtureProvider obtiene metadatos de un archivo JSON local, útil para desarrollo sin conexión
+type FixtureProvider struct {
+	movies []MovieMetadata
+}
+
+// NewFixtureProvider carga los metadatos desde un archivo JSON con un arreglo de películas
+func NewFixtureProvider(path string) (*FixtureProvider, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("no se pudo leer el fixture de metadatos %s: %w", path, err)
+	}
+
+	var movies []MovieMetadata
+	if err := json.Unmarshal(content, &movies); err != nil {
+		return nil, fmt.Errorf("fixture de metadatos inválido: %w", err)
+	}
+
+	for i := range movies {
+		movies[i].Source = "fixture"
+	}
+
+	return &FixtureProvider{movies: movies}, nil
+}
+
+// Name devuelve el nombre del proveedor
+func (p *FixtureProvider) Name() string {
+	return "fixture"
+}
+
+// Lookup busca la película por identificador externo o por título y año
+func (p *FixtureProvider) Lookup(_ context.Context, query Query) (*MovieMetadata, error) {
+	for i := range p.movies {
+		movie := &p.movies[i]
+		if query.ImdbID != "" && strings.EqualFold(movie.ImdbID, query.ImdbID) {
+			return copyMetadata(movie), nil
+		}
+		if query.TmdbID != "" && movie.TmdbID == query.TmdbID {
+			return copyMetadata(movie), nil
+		}
+	}
+
+	if query.Title == "" {
+		return nil, ErrNotFound
+	}
+
+	for i := range p.movies {
+		movie := &p.movies[i]
+		if !strings.EqualFold(strings.TrimSpace(movie.Title), strings.TrimSpace(query.Title)) {
+			continue
+		}
+		if query.Year != 0 && !strings.HasPrefix(movie.ReleaseDate, strconv.Itoa(query.Year)) {
+			continue
+		}
+		return copyMetadata(movie), nil
+	}
+
+	return nil, ErrNotFound
+}
+
+// copyMetadata evita que quien llama modifique los datos cargados del fixture
+func copyMetadata(movie *MovieMetadata) *MovieMetadata {
+	result := *movie
+	result.Genres = append([]string(nil), movie.Genres...)
+	result.Cast = append([]CastMember(nil), movie.Cast...)
+	return &result
+}
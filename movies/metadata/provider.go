@@ -0,0 +1,81 @@
+package metadata
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ErrNotFound indica que el proveedor no encontró la película solicitada
+var ErrNotFound = errors.New("película no encontrada en el proveedor de metadatos")
+
+// CastMember representa a una persona del reparto o del equipo técnico según el proveedor
+type CastMember struct {
+	Name      string `json:"name"`
+	Role      string `json:"role"` // director, actor, writer o composer
+	Character string `json:"character,omitempty"`
+	Order     int    `json:"order"`
+}
+
+// MovieMetadata contiene los datos de una película obtenidos de una fuente externa
+type MovieMetadata struct {
+	Source         string       `json:"source"`
+	ImdbID         string       `json:"imdb_id"`
+	TmdbID         string       `json:"tmdb_id"`
+	Title          string       `json:"title"`
+	Description    string       `json:"description"`
+	ReleaseDate    string       `json:"release_date"` // Formato YYYY-MM-DD
+	RuntimeMinutes int          `json:"runtime_minutes"`
+	PosterURL      string       `json:"poster_url"`
+	Genres         []string     `json:"genres"`
+	Cast           []CastMember `json:"cast"`
+}
+
+// Query define los criterios con los que se busca una película en el proveedor.
+// Los identificadores externos tienen prioridad sobre el título y el año.
+type Query struct {
+	ImdbID string
+	TmdbID string
+	Title  string
+	Year   int
+}
+
+// MetadataProvider es la interfaz que deben implementar las fuentes de metadatos
+type MetadataProvider interface {
+	// Name devuelve el nombre del proveedor (por ejemplo "tmdb" o "fixture")
+	Name() string
+	// Lookup busca una película y devuelve sus metadatos o ErrNotFound
+	Lookup(ctx context.Context, query Query) (*MovieMetadata, error)
+}
+
+// NewProviderFromEnv crea el proveedor configurado en METADATA_PROVIDER.
+// Si no se indica, se usa TMDB cuando hay TMDB_API_KEY y el fixture local en caso contrario.
+func NewProviderFromEnv() (MetadataProvider, error) {
+	name := strings.ToLower(strings.TrimSpace(os.Getenv("METADATA_PROVIDER")))
+	if name == "" {
+		if os.Getenv("TMDB_API_KEY") != "" {
+			name = "tmdb"
+		} else {
+			name = "fixture"
+		}
+	}
+
+	switch name {
+	case "tmdb":
+		apiKey := strings.TrimSpace(os.Getenv("TMDB_API_KEY"))
+		if apiKey == "" {
+			return nil, errors.New("la variable TMDB_API_KEY no está configurada")
+		}
+		return NewTMDBProvider(apiKey), nil
+	case "fixture":
+		path := os.Getenv("METADATA_FIXTURE_PATH")
+		if path == "" {
+			path = "movies/metadata/fixtures/movies.json"
+		}
+		return NewFixtureProvider(path)
+	default:
+		return nil, fmt.Errorf("proveedor de metadatos desconocido: %s", name)
+	}
+}
@@ -0,0 +1,233 @@
+package metadata
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultTMDBBaseURL  = "https://api.themoviedb.org/3"
+	defaultTMDBImageURL = "https://image.tmdb.org/t/p/w500"
+	defaultTMDBLanguage = "es-ES"
+	tmdbMaxCast         = 15
+)
+
+// TMDBProvider obtiene metadatos de la API de The Movie Database
+type TMDBProvider struct {
+	apiKey   string
+	baseURL  string
+	imageURL string
+	language string
+	client   *http.Client
+}
+
+// NewTMDBProvider crea un cliente de TMDB. Acepta tanto la API key (v3) como el token de lectura (v4).
+func NewTMDBProvider(apiKey string) *TMDBProvider {
+	provider := &TMDBProvider{
+		apiKey:   apiKey,
+		baseURL:  defaultTMDBBaseURL,
+		imageURL: defaultTMDBImageURL,
+		language: defaultTMDBLanguage,
+		client:   &http.Client{Timeout: 10 * time.Second},
+	}
+	if base := strings.TrimSpace(os.Getenv("TMDB_BASE_URL")); base != "" {
+		provider.baseURL = strings.TrimRight(base, "/")
+	}
+	if lang := strings.TrimSpace(os.Getenv("TMDB_LANGUAGE")); lang != "" {
+		provider.language = lang
+	}
+	return provider
+}
+
+// Name devuelve el nombre del proveedor
+func (p *TMDBProvider) Name() string {
+	return "tmdb"
+}
+
+// Estructuras de respuesta de la API de TMDB
+type tmdbSearchResponse struct {
+	Results []struct {
+		ID int `json:"id"`
+	} `json:"results"`
+}
+
+type tmdbFindResponse struct {
+	MovieResults []struct {
+		ID int `json:"id"`
+	} `json:"movie_results"`
+}
+
+type tmdbMovie struct {
+	ID          int    `json:"id"`
+	ImdbID      string `json:"imdb_id"`
+	Title       string `json:"title"`
+	Overview    string `json:"overview"`
+	ReleaseDate string `json:"release_date"`
+	Runtime     int    `json:"runtime"`
+	PosterPath  string `json:"poster_path"`
+	Genres      []struct {
+		Name string `json:"name"`
+	} `json:"genres"`
+	Credits struct {
+		Cast []struct {
+			Name      string `json:"name"`
+			Character string `json:"character"`
+			Order     int    `json:"order"`
+		} `json:"cast"`
+		Crew []struct {
+			Name string `json:"name"`
+			Job  string `json:"job"`
+		} `json:"crew"`
+	} `json:"credits"`
+}
+
+// Lookup busca la película por identificador de TMDB, de IMDb o por título y año
+func (p *TMDBProvider) Lookup(ctx context.Context, query Query) (*MovieMetadata, error) {
+	tmdbID := query.TmdbID
+
+	if tmdbID == "" && query.ImdbID != "" {
+		var found tmdbFindResponse
+		params := url.Values{"external_source": {"imdb_id"}}
+		if err := p.get(ctx, "/find/"+url.PathEscape(query.ImdbID), params, &found); err != nil {
+			return nil, err
+		}
+		if len(found.MovieResults) > 0 {
+			tmdbID = strconv.Itoa(found.MovieResults[0].ID)
+		}
+	}
+
+	if tmdbID == "" && query.Title != "" {
+		var search tmdbSearchResponse
+		params := url.Values{"query": {query.Title}}
+		if query.Year != 0 {
+			params.Set("year", strconv.Itoa(query.Year))
+		}
+		if err := p.get(ctx, "/search/movie", params, &search); err != nil {
+			return nil, err
+		}
+		if len(search.Results) > 0 {
+			tmdbID = strconv.Itoa(search.Results[0].ID)
+		}
+	}
+
+	if tmdbID == "" {
+		return nil, ErrNotFound
+	}
+
+	var movie tmdbMovie
+	params := url.Values{"append_to_response": {"credits"}}
+	if err := p.get(ctx, "/movie/"+url.PathEscape(tmdbID), params, &movie); err != nil {
+		return nil, err
+	}
+
+	return p.toMetadata(movie), nil
+}
+
+// toMetadata convierte la respuesta de TMDB al formato común de metadatos
+func (p *TMDBProvider) toMetadata(movie tmdbMovie) *MovieMetadata {
+	result := &MovieMetadata{
+		Source:         p.Name(),
+		ImdbID:         movie.ImdbID,
+		TmdbID:         strconv.Itoa(movie.ID),
+		Title:          movie.Title,
+		Description:    movie.Overview,
+		ReleaseDate:    movie.ReleaseDate,
+		RuntimeMinutes: movie.Runtime,
+	}
+
+	if movie.PosterPath != "" {
+		result.PosterURL = p.imageURL + movie.PosterPath
+	}
+
+	for _, genre := range movie.Genres {
+		result.Genres = append(result.Genres, genre.Name)
+	}
+
+	// Equipo técnico: solo los trabajos que se corresponden con los roles del catálogo
+	crewOrder := make(map[string]int)
+	for _, member := range movie.Credits.Crew {
+		role := crewJobToRole(member.Job)
+		if role == "" {
+			continue
+		}
+		result.Cast = append(result.Cast, CastMember{
+			Name:  member.Name,
+			Role:  role,
+			Order: crewOrder[role],
+		})
+		crewOrder[role]++
+	}
+
+	for i, member := range movie.Credits.Cast {
+		if i >= tmdbMaxCast {
+			break
+		}
+		result.Cast = append(result.Cast, CastMember{
+			Name:      member.Name,
+			Role:      "actor",
+			Character: member.Character,
+			Order:     member.Order,
+		})
+	}
+
+	return result
+}
+
+// crewJobToRole traduce el trabajo de TMDB al rol usado en los créditos
+func crewJobToRole(job string) string {
+	switch job {
+	case "Director":
+		return "director"
+	case "Screenplay", "Writer":
+		return "writer"
+	case "Original Music Composer", "Music":
+		return "composer"
+	default:
+		return ""
+	}
+}
+
+// get realiza una petición GET autenticada a la API y decodifica la respuesta
+func (p *TMDBProvider) get(ctx context.Context, path string, params url.Values, out interface{}) error {
+	params.Set("language", p.language)
+
+	// Los tokens v4 son JWT; las API keys v3 se envían como parámetro
+	useBearer := strings.Count(p.apiKey, ".") == 2
+	if !useBearer {
+		params.Set("api_key", p.apiKey)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.baseURL+path+"?"+params.Encode(), nil)
+	if err != nil {
+		return fmt.Errorf("error al crear solicitud a TMDB: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if useBearer {
+		req.Header.Set("Authorization", "Bearer "+p.apiKey)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error en la solicitud a TMDB: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return ErrNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("TMDB respondió con código %d", resp.StatusCode)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("error al decodificar respuesta de TMDB: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package models
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// MovieCertification es la clasificación por edades de una película en un país
+type MovieCertification struct {
+	ID      uint   `gorm:"primaryKey" json:"id"`
+	MovieID uint   `gorm:"not null;index" json:"movie_id"`
+	Country string `gorm:"type:varchar(2);not null" json:"country"` // Código ISO 3166-1 (US, ES, CO...)
+	Rating  string `gorm:"type:varchar(20);not null" json:"rating"` // Etiqueta oficial (PG-13, +18, TP...)
+	MinAge  int    `gorm:"not null;default:0" json:"min_age"`       // Edad mínima recomendada
+}
+
+// Avisos de contenido admitidos en las películas
+const (
+	AdvisoryViolence       = "violence"
+	AdvisoryGore           = "gore"
+	AdvisorySexualContent  = "sexual_content"
+	AdvisoryNudity         = "nudity"
+	AdvisoryLanguage       = "language"
+	AdvisoryDrugs          = "drugs"
+	AdvisoryAlcohol        = "alcohol"
+	AdvisorySmoking        = "smoking"
+	AdvisoryHorror         = "horror"
+	AdvisorySelfHarm       = "self_harm"
+	AdvisoryDiscrimination = "discrimination"
+)
+
+// IsValidAdvisory verifica si un aviso de contenido está entre los permitidos
+func IsValidAdvisory(tag string) bool {
+	switch tag {
+	case AdvisoryViolence, AdvisoryGore, AdvisorySexualContent, AdvisoryNudity, AdvisoryLanguage,
+		AdvisoryDrugs, AdvisoryAlcohol, AdvisorySmoking, AdvisoryHorror, AdvisorySelfHarm, AdvisoryDiscrimination:
+		return true
+	default:
+		return false
+	}
+}
+
+// Edad mínima de las clasificaciones sin número en la etiqueta
+var certificationAges = map[string]int{
+	"G":   0,
+	"PG":  0,
+	"U":   0,
+	"TP":  0,
+	"ATP": 0,
+	"A":   0,
+	"R":   17,
+	"X":   18,
+}
+
+var certificationDigits = regexp.MustCompile(`\d+`)
+
+// CertificationMinAge deduce la edad mínima de una etiqueta de clasificación (PG-13 → 13, +18 → 18, R → 17)
+func CertificationMinAge(rating string) (int, bool) {
+	rating = strings.ToUpper(strings.TrimSpace(rating))
+	if age, ok := certificationAges[rating]; ok {
+		return age, true
+	}
+	if digits := certificationDigits.FindString(rating); digits != "" {
+		age, err := strconv.Atoi(digits)
+		return age, err == nil
+	}
+	return 0, false
+}
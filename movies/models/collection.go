@@ -0,0 +1,48 @@
+package models
+
+import "time"
+
+// Collection agrupa las películas de una saga o franquicia (por ejemplo una trilogía)
+type Collection struct {
+	ID          uint   `gorm:"primaryKey" json:"id"`
+	Name        string `gorm:"unique;not null" json:"name"`
+	Description string `gorm:"type:text" json:"description"`
+	PosterURL   string `json:"poster_url"`
+
+	// Películas de la colección en orden
+	Movies []CollectionMovie `gorm:"foreignKey:CollectionID" json:"movies,omitempty"`
+
+	// Cantidad de películas (calculada en las consultas)
+	MovieCount int `gorm:"->;-:migration" json:"movie_count"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// CollectionMovie representa la pertenencia ordenada de una película a una colección
+type CollectionMovie struct {
+	ID           uint `gorm:"primaryKey" json:"id"`
+	CollectionID uint `gorm:"not null;index" json:"collection_id"`
+	MovieID      uint `gorm:"not null;index" json:"movie_id"`
+	Position     int  `gorm:"not null" json:"position"`
+
+	Movie *Movie `gorm:"foreignKey:MovieID" json:"movie,omitempty"`
+}
+
+// SeriesMovie es el resumen de una película vecina dentro de una colección
+type SeriesMovie struct {
+	ID        uint   `json:"id"`
+	Title     string `json:"title"`
+	PosterURL string `json:"poster_url"`
+	Position  int    `json:"position"`
+}
+
+// SeriesHint indica el lugar de una película dentro de una colección y sus vecinas
+type SeriesHint struct {
+	CollectionID   uint         `json:"collection_id"`
+	CollectionName string       `json:"collection_name"`
+	Position       int          `json:"position"`
+	Total          int          `json:"total"`
+	Previous       *SeriesMovie `json:"previous,omitempty"`
+	Next           *SeriesMovie `json:"next,omitempty"`
+}
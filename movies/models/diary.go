@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// DiaryEntry registra el día en que un usuario vio una película
+type DiaryEntry struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	UserID    uint      `gorm:"not null;index" json:"user_id"`
+	MovieID   uint      `gorm:"not null;index" json:"movie_id"`
+	WatchedOn time.Time `gorm:"type:date;not null;index" json:"watched_on"`
+	Rewatch   bool      `gorm:"not null;default:false" json:"rewatch"`
+	Rating    *float64  `json:"rating,omitempty"`     // Valoración opcional de 1 a 10
+	CommentID *uint     `json:"comment_id,omitempty"` // Comentario del usuario asociado a esta visualización
+
+	Movie *Movie `gorm:"foreignKey:MovieID" json:"movie,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// FactorModel registra un entrenamiento del modelo de factores latentes (factorización de matrices)
+type FactorModel struct {
+	ID             uint      `gorm:"primaryKey" json:"id"`
+	Factors        int       `gorm:"not null" json:"factors"`
+	Iterations     int       `gorm:"not null" json:"iterations"`
+	Regularization float64   `gorm:"not null" json:"regularization"`
+	Alpha          float64   `gorm:"not null" json:"alpha"`
+	Users          int       `gorm:"not null" json:"users"`
+	Movies         int       `gorm:"not null" json:"movies"`
+	Interactions   int       `gorm:"not null" json:"interactions"`
+	Loss           float64   `json:"loss"` // Función de coste tras la última iteración
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// UserFactor es el vector latente de un usuario en el último modelo entrenado
+type UserFactor struct {
+	UserID  uint      `gorm:"primaryKey;autoIncrement:false" json:"user_id"`
+	ModelID uint      `gorm:"not null;index" json:"model_id"`
+	Vector  []float64 `gorm:"type:jsonb;serializer:json;not null" json:"vector"`
+}
+
+// MovieFactor es el vector latente de una película en el último modelo entrenado
+type MovieFactor struct {
+	MovieID uint      `gorm:"primaryKey;autoIncrement:false" json:"movie_id"`
+	ModelID uint      `gorm:"not null;index" json:"model_id"`
+	Vector  []float64 `gorm:"type:jsonb;serializer:json;not null" json:"vector"`
+}
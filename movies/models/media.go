@@ -0,0 +1,59 @@
+package models
+
+import "time"
+
+// MediaKind representa el tipo de contenido multimedia de una película
+type MediaKind string
+
+const (
+	MediaTrailer  MediaKind = "trailer"
+	MediaBackdrop MediaKind = "backdrop"
+	MediaStill    MediaKind = "still"
+)
+
+// IsValidMediaKind verifica si un tipo de contenido está entre los permitidos
+func IsValidMediaKind(kind MediaKind) bool {
+	switch kind {
+	case MediaTrailer, MediaBackdrop, MediaStill:
+		return true
+	default:
+		return false
+	}
+}
+
+// Origen del contenido multimedia
+const (
+	MediaProviderYouTube = "youtube"
+	MediaProviderVimeo   = "vimeo"
+	MediaProviderUpload  = "upload" // Archivo subido a nuestro almacenamiento
+	MediaProviderURL     = "url"    // Enlace externo directo
+)
+
+// MovieMedia representa un tráiler, fondo o fotograma de una película
+type MovieMedia struct {
+	ID       uint      `gorm:"primaryKey" json:"id"`
+	MovieID  uint      `gorm:"not null;index" json:"movie_id"`
+	Kind     MediaKind `gorm:"type:varchar(20);not null;index" json:"kind"`
+	Provider string    `gorm:"type:varchar(20);not null" json:"provider"`
+
+	// Identificador del vídeo en YouTube/Vimeo (solo tráileres incrustados)
+	ExternalID string `json:"external_id,omitempty"`
+	URL        string `gorm:"not null" json:"url"`
+
+	// Variantes redimensionadas de las imágenes subidas
+	Variants map[string]string `gorm:"type:jsonb;serializer:json" json:"variants,omitempty"`
+	Blurhash string            `json:"blurhash,omitempty"`
+	Width    int               `json:"width,omitempty"`
+	Height   int               `json:"height,omitempty"`
+
+	Caption   string `json:"caption"`
+	SortOrder int    `gorm:"default:0" json:"sort_order"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TableName especifica el nombre de la tabla en la base de datos
+func (MovieMedia) TableName() string {
+	return "movie_media"
+}
@@ -3,25 +3,92 @@ package models
 import (
 	"strings"
 	"time"
+
+	"gorm.io/gorm"
 )
 
+// ActiveMovieIDs es la subconsulta de las películas que no están en la papelera.
+// Se usa para ocultar los registros que apuntan a películas eliminadas.
+const ActiveMovieIDs = "SELECT id FROM movies WHERE deleted_at IS NULL"
+
 type Movie struct {
 	ID          uint      `gorm:"primaryKey" json:"id"`
 	Title       string    `gorm:"not null" json:"title"`
+	Slug        string    `gorm:"type:varchar(160);not null;default:''" json:"slug"` // Identificador legible y estable para URLs (título-año)
 	Description string    `json:"description"`
 	Director    string    `json:"director"`
 	ReleaseDate time.Time `json:"release_date"`
 	Rating      float32   `json:"rating"`
 	PosterURL   string    `json:"poster_url"`
 
+	// Variantes redimensionadas del póster (w185, w342, w500, w780, original) y datos para placeholders
+	PosterVariants map[string]string `gorm:"type:jsonb;serializer:json" json:"poster_variants,omitempty"`
+	PosterBlurhash string            `json:"poster_blurhash,omitempty"`
+	PosterColor    string            `json:"poster_color,omitempty"`
+
+	// Identificador del catálogo de origen (importaciones masivas)
+	ExternalID string `gorm:"index" json:"external_id,omitempty"`
+
+	// Identificadores en bases de datos externas y duración
+	ImdbID         string `gorm:"index" json:"imdb_id,omitempty"`
+	TmdbID         string `gorm:"index" json:"tmdb_id,omitempty"`
+	RuntimeMinutes int    `json:"runtime_minutes,omitempty"`
+
 	// Campo para el género como texto (para facilidad de uso)
 	Genre string `json:"genre" gorm:"column:genre"`
 
 	// Relación muchos a muchos con géneros
 	Genres []Genre `gorm:"many2many:movie_genres;" json:"genres"`
 
+	// Reparto y equipo técnico de la película
+	Credits []MovieCredit `gorm:"foreignKey:MovieID" json:"credits,omitempty"`
+
+	// Tráileres, fondos y fotogramas
+	Media []MovieMedia `gorm:"foreignKey:MovieID" json:"media,omitempty"`
+
+	// Clasificación por edades en cada país y avisos de contenido (violence, language...)
+	Certifications    []MovieCertification `gorm:"foreignKey:MovieID" json:"certifications,omitempty"`
+	ContentAdvisories []string             `gorm:"type:jsonb;serializer:json" json:"content_advisories,omitempty"`
+
+	// Contadores desnormalizados que se actualizan al escribir comentarios y me gusta
+	LikeCount     int     `gorm:"not null;default:0" json:"like_count"`
+	CommentCount  int     `gorm:"not null;default:0" json:"comment_count"`
+	ScoreSum      float64 `gorm:"not null;default:0" json:"-"` // Suma de las puntuaciones, para calcular la media sin perder precisión
+	AvgScore      float64 `gorm:"not null;default:0" json:"avg_score"`
+	PositiveCount int     `gorm:"not null;default:0" json:"positive_count"`
+	NeutralCount  int     `gorm:"not null;default:0" json:"neutral_count"`
+	NegativeCount int     `gorm:"not null;default:0" json:"negative_count"`
+
+	// Valoración ponderada con la media global (media bayesiana); se calcula al consultar
+	WeightedRating float64 `gorm:"-" json:"weighted_rating"`
+
+	// Indica si la película está en la lista de pendientes del usuario que consulta
+	InWatchlist bool `gorm:"-" json:"in_watchlist"`
+
+	// Idioma en el que se devuelven el título y la descripción
+	Locale string `gorm:"-" json:"locale,omitempty"`
+
+	// Colecciones a las que pertenece, con la película anterior y la siguiente
+	Series []SeriesHint `gorm:"-" json:"series,omitempty"`
+
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
+
+	// Fecha en que se envió a la papelera (nulo si está activa)
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"deleted_at"`
+}
+
+// MovieCounterColumns son las columnas de los contadores, que solo se modifican al escribir comentarios
+// y me gusta. Se omiten al guardar la película completa para no pisar incrementos simultáneos.
+var MovieCounterColumns = []string{"like_count", "comment_count", "score_sum", "avg_score", "rating",
+	"positive_count", "neutral_count", "negative_count"}
+
+// SetExternalPoster asigna un póster externo y descarta las variantes procesadas del anterior
+func (m *Movie) SetExternalPoster(url string) {
+	m.PosterURL = url
+	m.PosterVariants = nil
+	m.PosterBlurhash = ""
+	m.PosterColor = ""
 }
 
 // ParseGenresString convierte una cadena de géneros en una lista de nombres de géneros
@@ -0,0 +1,64 @@
+package models
+
+import "time"
+
+// CreditRole representa el rol de una persona en una película
+type CreditRole string
+
+const (
+	RoleDirector CreditRole = "director"
+	RoleActor    CreditRole = "actor"
+	RoleWriter   CreditRole = "writer"
+	RoleComposer CreditRole = "composer"
+)
+
+// IsValidCreditRole verifica si un rol está entre los permitidos
+func IsValidCreditRole(role CreditRole) bool {
+	switch role {
+	case RoleDirector, RoleActor, RoleWriter, RoleComposer:
+		return true
+	default:
+		return false
+	}
+}
+
+// Person representa a un miembro del reparto o del equipo técnico
+type Person struct {
+	ID        uint       `gorm:"primaryKey" json:"id"`
+	Name      string     `gorm:"not null;index" json:"name"`
+	Biography string     `json:"biography"`
+	BirthDate *time.Time `json:"birth_date,omitempty"`
+	PhotoURL  string     `json:"photo_url"`
+
+	// Participaciones de la persona en películas
+	Credits []MovieCredit `gorm:"foreignKey:PersonID" json:"credits,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TableName especifica el nombre de la tabla en la base de datos
+func (Person) TableName() string {
+	return "people"
+}
+
+// MovieCredit relaciona una persona con una película y su rol en ella
+type MovieCredit struct {
+	ID           uint       `gorm:"primaryKey" json:"id"`
+	MovieID      uint       `gorm:"not null;index" json:"movie_id"`
+	PersonID     uint       `gorm:"not null;index" json:"person_id"`
+	Role         CreditRole `gorm:"not null" json:"role"`
+	Character    string     `json:"character,omitempty"` // Nombre del personaje (solo actores)
+	BillingOrder int        `json:"billing_order"`       // Orden de aparición en los créditos
+
+	Person *Person `gorm:"foreignKey:PersonID" json:"person,omitempty"`
+	Movie  *Movie  `gorm:"foreignKey:MovieID" json:"movie,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TableName especifica el nombre de la tabla en la base de datos
+func (MovieCredit) TableName() string {
+	return "movie_credits"
+}
@@ -0,0 +1,160 @@
+package models
+
+import (
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Origen de un cambio en el catálogo
+const (
+	RevisionSourceManual = "manual" // Edición desde la API
+	RevisionSourceImport = "import" // Importación masiva
+	RevisionSourceEnrich = "enrich" // Proveedor externo de metadatos
+	RevisionSourceRevert = "revert" // Restauración de una revisión anterior
+	RevisionSourceSeed   = "seed"   // Datos de prueba
+	RevisionSourceMerge  = "merge"  // Fusión de una película duplicada
+)
+
+// MovieSnapshot es el estado de los datos editables de una película tras un cambio
+type MovieSnapshot struct {
+	Title          string    `json:"title"`
+	Description    string    `json:"description"`
+	Director       string    `json:"director"`
+	ReleaseDate    time.Time `json:"release_date"`
+	PosterURL      string    `json:"poster_url"`
+	ImdbID         string    `json:"imdb_id"`
+	TmdbID         string    `json:"tmdb_id"`
+	RuntimeMinutes int       `json:"runtime_minutes"`
+	Genre          string    `json:"genre"`
+	Genres         []string  `json:"genres"` // Géneros asociados en movie_genres
+	Rating         float32   `json:"rating"`
+
+	// Avisos, clasificaciones por país y traducciones por idioma. Son nulos en las revisiones
+	// registradas antes de que formaran parte del historial.
+	ContentAdvisories []string                         `json:"content_advisories"`
+	Certifications    map[string]CertificationSnapshot `json:"certifications"`
+	Translations      map[string]TranslationSnapshot   `json:"translations"`
+
+	// Créditos del reparto y el equipo salvo los directores, que ya figuran en Director,
+	// con el formato "rol: nombre (personaje)". Son nulos en las revisiones anteriores.
+	Credits []string `json:"credits"`
+
+	// Indica si la película estaba en la papelera
+	Deleted bool `json:"deleted"`
+}
+
+// CertificationSnapshot es la clasificación de una película en un país
+type CertificationSnapshot struct {
+	Rating string `json:"rating"`
+	MinAge int    `json:"min_age"`
+}
+
+// TranslationSnapshot es la traducción de una película a un idioma
+type TranslationSnapshot struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+}
+
+// RevisionChange es el valor anterior y el nuevo de un campo
+type RevisionChange struct {
+	From interface{} `json:"from"`
+	To   interface{} `json:"to"`
+}
+
+// MovieRevision registra quién cambió una película, cuándo y qué campos
+type MovieRevision struct {
+	ID       uint   `gorm:"primaryKey" json:"id"`
+	MovieID  uint   `gorm:"not null;index" json:"movie_id"`
+	UserID   *uint  `gorm:"index" json:"user_id"` // Nulo para los procesos del sistema
+	Source   string `gorm:"type:varchar(20);not null" json:"source"`
+	RevertOf *uint  `json:"revert_of,omitempty"` // Revisión restaurada
+
+	Changes  map[string]RevisionChange `gorm:"type:jsonb;serializer:json" json:"changes"`
+	Snapshot MovieSnapshot             `gorm:"type:jsonb;serializer:json" json:"snapshot"`
+
+	// Nombre del autor (calculado en las consultas)
+	UserName string `gorm:"->;-:migration" json:"user_name,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// NewMovieSnapshot toma los datos editables de una película, sus géneros, clasificaciones y traducciones
+func NewMovieSnapshot(movie Movie, genres []string, translations []MovieTranslation) MovieSnapshot {
+	if genres == nil {
+		genres = []string{}
+	}
+	advisories := movie.ContentAdvisories
+	if advisories == nil {
+		advisories = []string{}
+	}
+	certifications := make(map[string]CertificationSnapshot, len(movie.Certifications))
+	for _, certification := range movie.Certifications {
+		certifications[certification.Country] = CertificationSnapshot{Rating: certification.Rating, MinAge: certification.MinAge}
+	}
+	localized := make(map[string]TranslationSnapshot, len(translations))
+	for _, translation := range translations {
+		localized[translation.Locale] = TranslationSnapshot{Title: translation.Title, Description: translation.Description}
+	}
+
+	return MovieSnapshot{
+		Title:          movie.Title,
+		Description:    movie.Description,
+		Director:       movie.Director,
+		ReleaseDate:    movie.ReleaseDate,
+		PosterURL:      movie.PosterURL,
+		ImdbID:         movie.ImdbID,
+		TmdbID:         movie.TmdbID,
+		RuntimeMinutes: movie.RuntimeMinutes,
+		Genre:          movie.Genre,
+		Genres:         genres,
+		Rating:         movie.Rating,
+
+		ContentAdvisories: advisories,
+		Certifications:    certifications,
+		Translations:      localized,
+		Deleted:           movie.DeletedAt.Valid,
+	}
+}
+
+// Diff devuelve los campos que cambian respecto al estado anterior
+func (s MovieSnapshot) Diff(previous MovieSnapshot) map[string]RevisionChange {
+	changes := make(map[string]RevisionChange)
+	compare := func(field string, from, to interface{}) {
+		if from != to {
+			changes[field] = RevisionChange{From: from, To: to}
+		}
+	}
+
+	compare("title", previous.Title, s.Title)
+	compare("description", previous.Description, s.Description)
+	compare("director", previous.Director, s.Director)
+	if !previous.ReleaseDate.Equal(s.ReleaseDate) {
+		changes["release_date"] = RevisionChange{From: previous.ReleaseDate, To: s.ReleaseDate}
+	}
+	compare("poster_url", previous.PosterURL, s.PosterURL)
+	compare("imdb_id", previous.ImdbID, s.ImdbID)
+	compare("tmdb_id", previous.TmdbID, s.TmdbID)
+	compare("runtime_minutes", previous.RuntimeMinutes, s.RuntimeMinutes)
+	compare("genre", previous.Genre, s.Genre)
+	if strings.Join(previous.Genres, "\x00") != strings.Join(s.Genres, "\x00") {
+		changes["genres"] = RevisionChange{From: previous.Genres, To: s.Genres}
+	}
+	compare("rating", previous.Rating, s.Rating)
+	if strings.Join(previous.ContentAdvisories, "\x00") != strings.Join(s.ContentAdvisories, "\x00") {
+		changes["content_advisories"] = RevisionChange{From: previous.ContentAdvisories, To: s.ContentAdvisories}
+	}
+	// Un mapa vacío y uno nulo equivalen: ninguno tiene entradas
+	if (len(previous.Certifications) > 0 || len(s.Certifications) > 0) && !reflect.DeepEqual(previous.Certifications, s.Certifications) {
+		changes["certifications"] = RevisionChange{From: previous.Certifications, To: s.Certifications}
+	}
+	if (len(previous.Translations) > 0 || len(s.Translations) > 0) && !reflect.DeepEqual(previous.Translations, s.Translations) {
+		changes["translations"] = RevisionChange{From: previous.Translations, To: s.Translations}
+	}
+	if (len(previous.Credits) > 0 || len(s.Credits) > 0) && strings.Join(previous.Credits, "\x00") != strings.Join(s.Credits, "\x00") {
+		changes["credits"] = RevisionChange{From: previous.Credits, To: s.Credits}
+	}
+	compare("deleted", previous.Deleted, s.Deleted)
+
+	return changes
+}
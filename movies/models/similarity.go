@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// MovieSimilarity es la similitud entre dos películas según los usuarios que interactuaron con ambas
+// (filtrado colaborativo ítem-ítem). La calcula periódicamente un proceso en segundo plano.
+type MovieSimilarity struct {
+	MovieID        uint      `gorm:"primaryKey;autoIncrement:false" json:"movie_id"`
+	SimilarMovieID uint      `gorm:"primaryKey;autoIncrement:false;index" json:"similar_movie_id"`
+	Similarity     float64   `gorm:"not null" json:"similarity"`
+	Support        int       `gorm:"not null" json:"support"` // Usuarios que interactuaron con las dos películas
+	ComputedAt     time.Time `gorm:"not null" json:"computed_at"`
+}
@@ -0,0 +1,63 @@
+package models
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MovieSlugRedirect guarda un slug anterior de una película para redirigir al actual
+type MovieSlugRedirect struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	Slug      string    `gorm:"type:varchar(160);not null" json:"slug"`
+	MovieID   uint      `gorm:"not null;index" json:"movie_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+var accentReplacer = strings.NewReplacer(
+	"á", "a", "à", "a", "ä", "a", "â", "a", "é", "e", "è", "e", "ë", "e", "ê", "e",
+	"í", "i", "ì", "i", "ï", "i", "î", "i", "ó", "o", "ò", "o", "ö", "o", "ô", "o",
+	"ú", "u", "ù", "u", "ü", "u", "û", "u", "ñ", "n", "ç", "c", "&", " and ",
+)
+
+// FoldText pasa un texto a minúsculas sin acentos y sustituye los signos de puntuación por espacios
+func FoldText(text string) string {
+	text = accentReplacer.Replace(strings.ToLower(text))
+	var b strings.Builder
+	for _, r := range text {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+		} else {
+			b.WriteRune(' ')
+		}
+	}
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+// MovieSlugBase genera el slug de una película a partir del título y el año ("el-padrino-1972")
+func MovieSlugBase(title string, releaseDate time.Time) string {
+	slug := strings.ReplaceAll(FoldText(title), " ", "-")
+	if len(slug) > 140 {
+		slug = strings.TrimRight(slug[:140], "-")
+	}
+	if slug == "" {
+		slug = "pelicula"
+	}
+	if !releaseDate.IsZero() {
+		slug += "-" + strconv.Itoa(releaseDate.Year())
+	}
+	return slug
+}
+
+// MatchesSlugBase indica si un slug es la base o la base con un sufijo numérico ("el-padrino-1972-2")
+func MatchesSlugBase(slug, base string) bool {
+	if slug == base {
+		return true
+	}
+	suffix, ok := strings.CutPrefix(slug, base+"-")
+	if !ok || suffix == "" {
+		return false
+	}
+	_, err := strconv.Atoi(suffix)
+	return err == nil
+}
@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// MovieTranslation contiene el título y la descripción de una película en otro idioma
+type MovieTranslation struct {
+	ID          uint   `gorm:"primaryKey" json:"id"`
+	MovieID     uint   `gorm:"not null;index" json:"movie_id"`
+	Locale      string `gorm:"type:varchar(10);not null" json:"locale"` // Etiqueta BCP 47 normalizada (es, en, pt-BR...)
+	Title       string `gorm:"not null" json:"title"`
+	Description string `json:"description"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -0,0 +1,42 @@
+package models
+
+import "time"
+
+// Ventanas de tiempo de las películas en tendencia
+const (
+	TrendingWindowDay   = "24h"
+	TrendingWindowWeek  = "7d"
+	TrendingWindowMonth = "30d"
+)
+
+// TrendingWindows son las ventanas admitidas, en el orden en que se calculan las instantáneas
+var TrendingWindows = []string{TrendingWindowDay, TrendingWindowWeek, TrendingWindowMonth}
+
+// MovieViewStat cuenta las visitas a la ficha de una película agrupadas por hora
+type MovieViewStat struct {
+	ID      uint      `gorm:"primaryKey" json:"id"`
+	MovieID uint      `gorm:"not null" json:"movie_id"`
+	Hour    time.Time `gorm:"not null;index" json:"hour"` // Inicio de la hora (UTC)
+	Views   int       `gorm:"not null;default:0" json:"views"`
+}
+
+// TrendingSnapshot es el ranking de tendencias de una ventana calculado en un momento dado
+type TrendingSnapshot struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	Window    string    `gorm:"column:time_window;type:varchar(10);not null;index:idx_trending_snapshots_window_created" json:"window"` // window es palabra reservada en SQL
+	CreatedAt time.Time `gorm:"index:idx_trending_snapshots_window_created" json:"created_at"`
+
+	Entries []TrendingSnapshotEntry `gorm:"foreignKey:SnapshotID" json:"entries,omitempty"`
+}
+
+// TrendingSnapshotEntry es la posición de una película en una instantánea de tendencias
+type TrendingSnapshotEntry struct {
+	ID         uint    `gorm:"primaryKey" json:"id"`
+	SnapshotID uint    `gorm:"not null;index" json:"snapshot_id"`
+	MovieID    uint    `gorm:"not null;index" json:"movie_id"`
+	Rank       int     `gorm:"not null" json:"rank"`
+	Score      float64 `gorm:"not null" json:"score"`
+	Likes      int     `gorm:"not null;default:0" json:"likes"`
+	Comments   int     `gorm:"not null;default:0" json:"comments"`
+	Views      int     `gorm:"not null;default:0" json:"views"`
+}
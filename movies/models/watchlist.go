@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// Prioridades de una película en la lista de pendientes
+const (
+	WatchlistPriorityLow    = 1
+	WatchlistPriorityMedium = 2
+	WatchlistPriorityHigh   = 3
+)
+
+// WatchlistItem representa una película que un usuario quiere ver
+type WatchlistItem struct {
+	ID       uint   `gorm:"primaryKey" json:"id"`
+	UserID   uint   `gorm:"not null;index" json:"user_id"`
+	MovieID  uint   `gorm:"not null" json:"movie_id"`
+	Notes    string `gorm:"type:text" json:"notes"`
+	Priority int    `gorm:"not null;default:2" json:"priority"` // 1 = baja, 2 = media, 3 = alta
+	Position int    `gorm:"not null;default:0" json:"position"` // Orden manual dentro de la lista
+
+	Movie *Movie `gorm:"foreignKey:MovieID" json:"movie,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
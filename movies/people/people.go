@@ -0,0 +1,123 @@
+package people
+
+import (
+	"cine_conecta_backend/movies/models"
+	"errors"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// MigrateDirectors convierte el texto libre del campo director en personas con crédito de dirección,
+// también en las películas de la papelera. Devuelve la cantidad de películas procesadas.
+func MigrateDirectors(db *gorm.DB) (int, error) {
+	var movies []models.Movie
+	if err := db.Unscoped().Where("director <> ''").Find(&movies).Error; err != nil {
+		return 0, err
+	}
+
+	migrated := 0
+	for i := range movies {
+		tx := db.Begin()
+		if err := SyncDirectorCredits(tx, &movies[i]); err != nil {
+			tx.Rollback()
+			fmt.Printf("[DEBUG-PEOPLE] Error al migrar director de la película %d: %v\n", movies[i].ID, err)
+			continue
+		}
+		if err := tx.Commit().Error; err != nil {
+			return migrated, err
+		}
+		migrated++
+	}
+
+	fmt.Printf("[DEBUG-PEOPLE] Directores migrados para %d películas\n", migrated)
+	return migrated, nil
+}
+
+// SyncDirectorCredits asegura que cada nombre del campo director tenga su crédito de dirección
+// y elimina los créditos de dirección que ya no aparecen en el campo.
+func SyncDirectorCredits(tx *gorm.DB, movie *models.Movie) error {
+	names := SplitNames(movie.Director)
+
+	var existing []models.MovieCredit
+	if err := tx.Preload("Person").
+		Where("movie_id = ? AND role = ?", movie.ID, models.RoleDirector).
+		Find(&existing).Error; err != nil {
+		return err
+	}
+
+	wanted := make(map[string]bool)
+	for _, name := range names {
+		wanted[strings.ToLower(name)] = true
+	}
+
+	linked := make(map[string]bool)
+	for _, credit := range existing {
+		if credit.Person == nil || !wanted[strings.ToLower(credit.Person.Name)] {
+			if err := tx.Delete(&credit).Error; err != nil {
+				return err
+			}
+			continue
+		}
+		linked[strings.ToLower(credit.Person.Name)] = true
+	}
+
+	for i, name := range names {
+		if linked[strings.ToLower(name)] {
+			continue
+		}
+		person, err := FindOrCreate(tx, name)
+		if err != nil {
+			return err
+		}
+		credit := models.MovieCredit{
+			MovieID:      movie.ID,
+			PersonID:     person.ID,
+			Role:         models.RoleDirector,
+			BillingOrder: i,
+		}
+		if err := tx.Create(&credit).Error; err != nil {
+			return err
+		}
+		linked[strings.ToLower(name)] = true
+	}
+
+	return nil
+}
+
+// FindOrCreate busca una persona por nombre (sin distinguir mayúsculas) o la crea si no existe
+func FindOrCreate(tx *gorm.DB, name string) (*models.Person, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, errors.New("nombre de persona vacío")
+	}
+
+	var person models.Person
+	if err := tx.Where("LOWER(name) = LOWER(?)", name).First(&person).Error; err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+		person = models.Person{Name: name}
+		if err := tx.Create(&person).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	return &person, nil
+}
+
+// SplitNames divide una cadena de nombres separados por comas
+func SplitNames(value string) []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, part := range strings.Split(value, ",") {
+		name := strings.TrimSpace(part)
+		if name == "" || seen[strings.ToLower(name)] {
+			continue
+		}
+		seen[strings.ToLower(name)] = true
+		names = append(names, name)
+	}
+	return names
+}
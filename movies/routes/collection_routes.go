@@ -0,0 +1,25 @@
+package routes
+
+import (
+	"cine_conecta_backend/auth/middlewares"
+	"cine_conecta_backend/movies/controllers"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterCollectionRoutes(r *gin.Engine) {
+	collections := r.Group("/api/collections")
+	{
+		// Lectura con autenticación básica
+		collections.GET("/", middlewares.AuthRequired(), controllers.GetCollections)
+		collections.GET("/:collectionId", middlewares.AuthRequired(), controllers.GetCollection)
+
+		// Rutas restringidas a admin
+		collections.POST("/", middlewares.AdminRequired(), controllers.CreateCollection)
+		collections.PUT("/:collectionId", middlewares.AdminRequired(), controllers.UpdateCollection)
+		collections.DELETE("/:collectionId", middlewares.AdminRequired(), controllers.DeleteCollection)
+		collections.POST("/:collectionId/movies", middlewares.AdminRequired(), controllers.AddMovieToCollection)
+		collections.POST("/:collectionId/reorder", middlewares.AdminRequired(), controllers.ReorderCollection)
+		collections.DELETE("/:collectionId/movies/:movieId", middlewares.AdminRequired(), controllers.RemoveMovieFromCollection)
+	}
+}
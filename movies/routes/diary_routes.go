@@ -0,0 +1,23 @@
+package routes
+
+import (
+	"cine_conecta_backend/auth/middlewares"
+	"cine_conecta_backend/movies/controllers"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterDiaryRoutes(r *gin.Engine) {
+	diary := r.Group("/api/diary")
+	{
+		// Diario de películas vistas del usuario autenticado
+		diary.GET("/", middlewares.AuthRequired(), controllers.GetDiary)
+		diary.POST("/", middlewares.AuthRequired(), controllers.CreateDiaryEntry)
+		diary.PUT("/:entryId", middlewares.AuthRequired(), controllers.UpdateDiaryEntry)
+		diary.DELETE("/:entryId", middlewares.AuthRequired(), controllers.DeleteDiaryEntry)
+
+		// Estadísticas anuales y mensuales
+		diary.GET("/stats/:year", middlewares.AuthRequired(), controllers.GetDiaryYearStats)
+		diary.GET("/stats/:year/:month", middlewares.AuthRequired(), controllers.GetDiaryMonthStats)
+	}
+}
@@ -16,9 +16,20 @@ func RegisterMovieRoutes(r *gin.Engine) {
 		movies.GET("/sorted", middlewares.AuthRequired(), controllers.GetMoviesSorted)
 		movies.GET("/recent", middlewares.AuthRequired(), controllers.GetRecentMovies)
 
+		// Ficha pública de una película por su slug (los slugs antiguos redirigen al actual)
+		movies.GET("/by-slug/:slug", middlewares.OptionalAuth(), controllers.GetMovieBySlug)
+
 		// Películas mejor valoradas
 		movies.GET("/top-rated", middlewares.AuthRequired(), controllers.GetTopRatedMovies)
 		movies.GET("/average-score", middlewares.AuthRequired(), controllers.GetMovieWithAverageScore)
+		movies.GET("/:movieId/rating-histogram", middlewares.AuthRequired(), controllers.GetMovieRatingHistogram)
+
+		// Películas en tendencia (las instantáneas se guardan periódicamente)
+		movies.GET("/trending", middlewares.AuthRequired(), controllers.GetTrendingMovies)
+		movies.POST("/trending/snapshots", middlewares.AdminRequired(), controllers.CreateTrendingSnapshots)
+
+		// Películas parecidas ("más como esta")
+		movies.GET("/:movieId/similar", middlewares.AuthRequired(), controllers.GetSimilarMovies)
 
 		// Búsqueda avanzada
 		movies.GET("/search", middlewares.AuthRequired(), controllers.SearchMovies)
@@ -43,13 +54,78 @@ func RegisterMovieRoutes(r *gin.Engine) {
 		movies.PUT("/:movieId/genres", middlewares.AdminRequired(), controllers.UpdateMovieGenre)
 		movies.DELETE("/:movieId/genres/:genre", middlewares.AdminRequired(), controllers.RemoveGenreFromMovie)
 
+		// Rutas para reparto y equipo técnico
+		movies.GET("/:movieId/credits", middlewares.AuthRequired(), controllers.GetMovieCredits)
+		movies.POST("/:movieId/credits", middlewares.AdminRequired(), controllers.AddMovieCredit)
+		movies.DELETE("/:movieId/credits/:creditId", middlewares.AdminRequired(), controllers.RemoveMovieCredit)
+
+		// Tráileres, fondos y fotogramas
+		movies.GET("/:movieId/media", middlewares.AuthRequired(), controllers.GetMovieMedia)
+		movies.POST("/:movieId/media", middlewares.AdminRequired(), controllers.AddMovieMedia)
+		movies.POST("/:movieId/media/upload", middlewares.AdminRequired(), controllers.UploadMovieMedia)
+		movies.POST("/:movieId/media/reorder", middlewares.AdminRequired(), controllers.ReorderMovieMedia)
+		movies.PUT("/:movieId/media/:mediaId", middlewares.AdminRequired(), controllers.UpdateMovieMedia)
+		movies.DELETE("/:movieId/media/:mediaId", middlewares.AdminRequired(), controllers.DeleteMovieMedia)
+
+		// Traducciones del título y la descripción
+		movies.GET("/:movieId/translations", middlewares.AuthRequired(), controllers.GetMovieTranslations)
+		movies.PUT("/:movieId/translations/:locale", middlewares.AdminRequired(), controllers.SaveMovieTranslation)
+		movies.DELETE("/:movieId/translations/:locale", middlewares.AdminRequired(), controllers.DeleteMovieTranslation)
+
+		// Clasificación por edades y avisos de contenido
+		movies.GET("/:movieId/certifications", middlewares.AuthRequired(), controllers.GetMovieCertifications)
+		movies.PUT("/:movieId/certifications/:country", middlewares.AdminRequired(), controllers.SaveMovieCertification)
+		movies.DELETE("/:movieId/certifications/:country", middlewares.AdminRequired(), controllers.DeleteMovieCertification)
+		movies.PUT("/:movieId/advisories", middlewares.AdminRequired(), controllers.UpdateMovieAdvisories)
+
+		// Historial de cambios del catálogo (admin)
+		movies.GET("/:movieId/revisions", middlewares.AdminRequired(), controllers.GetMovieRevisions)
+		movies.POST("/:movieId/revisions/:revisionId/revert", middlewares.AdminRequired(), controllers.RevertMovieRevision)
+
 		// Rutas restringidas a admin
 		movies.POST("/", middlewares.AdminRequired(), controllers.CreateMovie)
 		movies.PUT("/:movieId", middlewares.AdminRequired(), controllers.UpdateMovie)
 		movies.DELETE("/:movieId", middlewares.AdminRequired(), controllers.DeleteMovie)
 		movies.POST("/:movieId/poster", middlewares.AdminRequired(), controllers.UploadPoster)
+
+		// Papelera: las películas eliminadas se pueden restaurar hasta que se purgan (admin)
+		movies.GET("/trash", middlewares.AdminRequired(), controllers.GetTrashedMovies)
+		movies.DELETE("/trash", middlewares.AdminRequired(), controllers.PurgeExpiredTrash)
+		movies.POST("/:movieId/restore", middlewares.AdminRequired(), controllers.RestoreMovie)
+		movies.DELETE("/:movieId/purge", middlewares.AdminRequired(), controllers.PurgeMovie)
+
+		// Detección y fusión de películas duplicadas (admin)
+		movies.GET("/duplicates", middlewares.AdminRequired(), controllers.FindDuplicateMovies)
+		movies.POST("/:movieId/merge", middlewares.AdminRequired(), controllers.MergeMovies)
+
+		// Generar los slugs de las películas existentes (admin)
+		movies.POST("/migrate-slugs", middlewares.AdminRequired(), controllers.MigrateMovieSlugs)
+
+		// Subida directa del póster al almacenamiento con URL firmada (admin)
+		movies.POST("/:movieId/poster/presign", middlewares.AdminRequired(), controllers.PresignPosterUpload)
+		movies.POST("/:movieId/poster/complete", middlewares.AdminRequired(), controllers.CompletePosterUpload)
+
+		// Enriquecimiento con proveedores externos de metadatos (admin)
+		movies.GET("/:movieId/enrich", middlewares.AdminRequired(), controllers.PreviewMovieEnrichment)
+		movies.POST("/:movieId/enrich", middlewares.AdminRequired(), controllers.ApplyMovieEnrichment)
+
+		// Importación y exportación masiva del catálogo (admin)
+		movies.POST("/import", middlewares.AdminRequired(), controllers.ImportMovies)
+		movies.GET("/export", middlewares.AdminRequired(), controllers.ExportMovies)
 	}
 
 	// Registrar las rutas de recomendaciones
 	RegisterRecommendationRoutes(r)
+
+	// Registrar las rutas de reparto y equipo técnico
+	RegisterPeopleRoutes(r)
+
+	// Registrar las rutas de la lista de pendientes
+	RegisterWatchlistRoutes(r)
+
+	// Registrar las rutas del diario de películas vistas
+	RegisterDiaryRoutes(r)
+
+	// Registrar las rutas de colecciones y sagas
+	RegisterCollectionRoutes(r)
 }
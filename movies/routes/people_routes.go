@@ -0,0 +1,24 @@
+package routes
+
+import (
+	"cine_conecta_backend/auth/middlewares"
+	"cine_conecta_backend/movies/controllers"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterPeopleRoutes(r *gin.Engine) {
+	people := r.Group("/api/people")
+	{
+		// Lectura con autenticación básica
+		people.GET("/", middlewares.AuthRequired(), controllers.GetPeople)
+		people.GET("/:id", middlewares.AuthRequired(), controllers.GetPerson)
+		people.GET("/:id/movies", middlewares.AuthRequired(), controllers.GetPersonMovies)
+
+		// Rutas restringidas a admin
+		people.POST("/", middlewares.AdminRequired(), controllers.CreatePerson)
+		people.PUT("/:id", middlewares.AdminRequired(), controllers.UpdatePerson)
+		people.DELETE("/:id", middlewares.AdminRequired(), controllers.DeletePerson)
+		people.POST("/migrate-directors", middlewares.AdminRequired(), controllers.MigrateDirectors)
+	}
+}
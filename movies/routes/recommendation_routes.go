@@ -17,6 +17,9 @@ func RegisterRecommendationRoutes(r *gin.Engine) {
 		// Recomendaciones populares basadas en el análisis de sentimientos (disponible para todos)
 		recommendations.GET("/popular", middlewares.AuthRequired(), controllers.GetPopularRecommendations)
 
+		// Recalcular las similitudes del filtrado colaborativo (también con -build-similarities)
+		recommendations.POST("/similarities/rebuild", middlewares.AdminRequired(), controllers.RebuildMovieSimilarities)
+
 		// Rutas para el dataset de recomendaciones
 		recommendations.POST("/dataset", middlewares.AuthRequired(), commentsControllers.SaveRecommendationsToDataset)
 		recommendations.GET("/dataset", middlewares.AuthRequired(), commentsControllers.GetMyRecommendationDatasets)
@@ -0,0 +1,20 @@
+package routes
+
+import (
+	"cine_conecta_backend/auth/middlewares"
+	"cine_conecta_backend/movies/controllers"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterWatchlistRoutes(r *gin.Engine) {
+	watchlist := r.Group("/api/watchlist")
+	{
+		// Lista de pendientes del usuario autenticado
+		watchlist.GET("/", middlewares.AuthRequired(), controllers.GetWatchlist)
+		watchlist.POST("/", middlewares.AuthRequired(), controllers.AddToWatchlist)
+		watchlist.POST("/reorder", middlewares.AuthRequired(), controllers.ReorderWatchlist)
+		watchlist.PUT("/:movieId", middlewares.AuthRequired(), controllers.UpdateWatchlistItem)
+		watchlist.DELETE("/:movieId", middlewares.AuthRequired(), controllers.RemoveFromWatchlist)
+	}
+}
@@ -29,7 +29,6 @@ func SeedMovies() {
 				Description: "La historia de la familia mafiosa Corleone liderada por Don Vito Corleone.",
 				Director:    "Francis Ford Coppola",
 				ReleaseDate: parseDate("1972-03-24"),
-				Rating:      9.2,
 				PosterURL:   "https://m.media-amazon.com/images/M/MV5BM2MyNjYxNmUtYTAwNi00MTYxLWJmNWYtYzZlODY3ZTk3OTFlXkEyXkFqcGdeQXVyNzkwMjQ5NzM@._V1_.jpg",
 			},
 			GenreNames: []string{"Drama", "Crimen"},
@@ -40,7 +39,6 @@ func SeedMovies() {
 				Description: "Un pianista de jazz y una aspirante a actriz se enamoran mientras persiguen sus sueños.",
 				Director:    "Damien Chazelle",
 				ReleaseDate: parseDate("2016-12-09"),
-				Rating:      8.0,
 				PosterURL:   "https://m.media-amazon.com/images/M/MV5BMzUzNDM2NzM2MV5BMl5BanBnXkFtZTgwNTM3NTg4OTE@._V1_.jpg",
 			},
 			GenreNames: []string{"Musical", "Romance", "Drama"},
@@ -51,7 +49,6 @@ func SeedMovies() {
 				Description: "Un grupo de astronautas viaja a través de un agujero de gusano en busca de un nuevo hogar para la humanidad.",
 				Director:    "Christopher Nolan",
 				ReleaseDate: parseDate("2014-11-07"),
-				Rating:      8.6,
 				PosterURL:   "https://m.media-amazon.com/images/M/MV5BZjdkOTU3MDktN2IxOS00OGEyLWFmMjktY2FiMmZkNWIyODZiXkEyXkFqcGdeQXVyMTMxODk2OTU@._V1_.jpg",
 			},
 			GenreNames: []string{"Ciencia ficción", "Aventura", "Drama"},
@@ -62,7 +59,6 @@ func SeedMovies() {
 				Description: "La familia Kim, que vive en la pobreza, se infiltra en la vida de una familia adinerada.",
 				Director:    "Bong Joon Ho",
 				ReleaseDate: parseDate("2019-05-30"),
-				Rating:      8.5,
 				PosterURL:   "https://m.media-amazon.com/images/M/MV5BYWZjMjk3ZTItODQ2ZC00NTY5LWE0ZDYtZTI3MjcwN2Q5NTVkXkEyXkFqcGdeQXVyODk4OTc3MTY@._V1_.jpg",
 			},
 			GenreNames: []string{"Drama", "Comedia", "Thriller"},
@@ -73,7 +69,6 @@ func SeedMovies() {
 				Description: "Miguel sueña con ser músico, pero su familia se lo prohíbe. Desesperado por demostrar su talento, se encuentra en la Tierra de los Muertos.",
 				Director:    "Lee Unkrich",
 				ReleaseDate: parseDate("2017-11-22"),
-				Rating:      8.4,
 				PosterURL:   "https://m.media-amazon.com/images/M/MV5BYjQ5NjM0Y2YtNjZkNC00ZDhkLWJjMWItN2QyNzFkMDE3ZjAxXkEyXkFqcGdeQXVyODIxMzk5NjA@._V1_.jpg",
 			},
 			GenreNames: []string{"Animación", "Aventura", "Familia"},
@@ -82,7 +77,7 @@ func SeedMovies() {
 
 	// Insertar las películas
 	for _, item := range movies {
-		if err := services.CreateMovieWithGenres(&item.Movie, item.GenreNames); err != nil {
+		if err := services.CreateMovieWithGenres(&item.Movie, item.GenreNames, services.RevisionInfo{Source: models.RevisionSourceSeed}); err != nil {
 			fmt.Printf("❌ Error al crear la película %s: %v\n", item.Movie.Title, err)
 		} else {
 			fmt.Printf("✅ Película creada: %s con géneros: %v\n", item.Movie.Title, item.GenreNames)
@@ -0,0 +1,474 @@
+package services
+
+import (
+	"cine_conecta_backend/auth/utils"
+	"cine_conecta_backend/config"
+	"cine_conecta_backend/movies/models"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// CatalogRow representa una película en los archivos de importación/exportación
+type CatalogRow struct {
+	ExternalID     string   `json:"external_id"`
+	ImdbID         string   `json:"imdb_id"`
+	TmdbID         string   `json:"tmdb_id"`
+	Title          string   `json:"title"`
+	Description    string   `json:"description"`
+	Director       string   `json:"director"`
+	ReleaseDate    string   `json:"release_date"` // Formato YYYY-MM-DD
+	RuntimeMinutes int      `json:"runtime_minutes"`
+	PosterURL      string   `json:"poster_url"`
+	Genres         []string `json:"genres"`
+
+	invalidRuntime string // Duración del CSV que no es un número, se informa al validar la fila
+}
+
+// ImportRowResult describe el resultado de procesar una fila del archivo
+type ImportRowResult struct {
+	Row     int      `json:"row"`
+	Title   string   `json:"title"`
+	Action  string   `json:"action"` // created, updated o error
+	MovieID uint     `json:"movie_id,omitempty"`
+	Errors  []string `json:"errors,omitempty"`
+}
+
+// ImportReport resume el resultado de una importación
+type ImportReport struct {
+	DryRun  bool              `json:"dry_run"`
+	Total   int               `json:"total"`
+	Created int               `json:"created"`
+	Updated int               `json:"updated"`
+	Failed  int               `json:"failed"`
+	Rows    []ImportRowResult `json:"rows"`
+}
+
+// catalogColumns define el orden de las columnas en los archivos CSV
+var catalogColumns = []string{"external_id", "imdb_id", "tmdb_id", "title", "description", "director", "release_date", "runtime_minutes", "poster_url", "genres"}
+
+// genreAliases traduce nombres de géneros habituales en otros idiomas a los usados en el catálogo
+var genreAliases = map[string]string{
+	"action":          "Acción",
+	"adventure":       "Aventura",
+	"animation":       "Animación",
+	"comedy":          "Comedia",
+	"crime":           "Crimen",
+	"documentary":     "Documental",
+	"family":          "Familia",
+	"fantasy":         "Fantasía",
+	"horror":          "Terror",
+	"music":           "Musical",
+	"mystery":         "Misterio",
+	"romance":         "Romance",
+	"science fiction": "Ciencia ficción",
+	"sci-fi":          "Ciencia ficción",
+	"war":             "Bélica",
+	"western":         "Western",
+}
+
+// ParseCatalogCSV lee un catálogo en formato CSV. La primera fila debe contener los nombres de columna.
+func ParseCatalogCSV(r io.Reader) ([]CatalogRow, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("no se pudo leer la cabecera del CSV: %w", err)
+	}
+
+	columns := make(map[string]int)
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	if _, ok := columns["title"]; !ok {
+		return nil, errors.New("el CSV debe incluir la columna 'title'")
+	}
+
+	field := func(record []string, name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	var rows []CatalogRow
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error al leer el CSV: %w", err)
+		}
+
+		row := CatalogRow{
+			ExternalID:  field(record, "external_id"),
+			ImdbID:      field(record, "imdb_id"),
+			TmdbID:      field(record, "tmdb_id"),
+			Title:       field(record, "title"),
+			Description: field(record, "description"),
+			Director:    field(record, "director"),
+			ReleaseDate: field(record, "release_date"),
+			PosterURL:   field(record, "poster_url"),
+			Genres:      models.ParseGenresString(field(record, "genres")),
+		}
+		if runtime := field(record, "runtime_minutes"); runtime != "" {
+			value, err := strconv.Atoi(runtime)
+			if err != nil {
+				row.invalidRuntime = runtime
+			}
+			row.RuntimeMinutes = value
+		}
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+// ParseCatalogJSON lee un catálogo en formato JSON (un arreglo de películas)
+func ParseCatalogJSON(r io.Reader) ([]CatalogRow, error) {
+	var rows []CatalogRow
+	if err := json.NewDecoder(r).Decode(&rows); err != nil {
+		return nil, fmt.Errorf("JSON inválido: %w", err)
+	}
+	return rows, nil
+}
+
+// ImportCatalog crea o actualiza películas a partir de las filas recibidas.
+// En modo dryRun solo valida y reporta lo que ocurriría, sin escribir en la base de datos.
+func ImportCatalog(rows []CatalogRow, dryRun bool, userID uint) (*ImportReport, error) {
+	info := RevisionInfo{UserID: userID, Source: models.RevisionSourceImport}
+	report := &ImportReport{DryRun: dryRun, Total: len(rows)}
+	seenKeys := make(map[string]int)
+
+	for i, row := range rows {
+		result := ImportRowResult{Row: i + 1, Title: row.Title}
+
+		movie, errs := buildMovieFromRow(row)
+
+		// Detectar filas repetidas dentro del mismo archivo
+		key := catalogRowKey(movie)
+		if previous, exists := seenKeys[key]; exists && key != "" {
+			errs = append(errs, fmt.Sprintf("fila duplicada de la fila %d", previous))
+		} else if key != "" {
+			seenKeys[key] = result.Row
+		}
+
+		if len(errs) > 0 {
+			result.Action = "error"
+			result.Errors = errs
+			report.Failed++
+			report.Rows = append(report.Rows, result)
+			continue
+		}
+
+		existing, err := findCatalogMatch(movie)
+		if err != nil {
+			return nil, err
+		}
+
+		if existing != nil && existing.DeletedAt.Valid {
+			result.Action = "error"
+			result.MovieID = existing.ID
+			result.Errors = []string{fmt.Sprintf("coincide con la película %d, que está en la papelera; restáurela antes de importarla", existing.ID)}
+			report.Failed++
+			report.Rows = append(report.Rows, result)
+			continue
+		}
+
+		genreNames, err := mapGenreNames(row.Genres)
+		if err != nil {
+			return nil, err
+		}
+
+		if existing == nil {
+			result.Action = "created"
+			if !dryRun {
+				if err := CreateMovieWithGenres(&movie, genreNames, info); err != nil {
+					result.Action = "error"
+					result.Errors = []string{err.Error()}
+					report.Failed++
+					report.Rows = append(report.Rows, result)
+					continue
+				}
+				result.MovieID = movie.ID
+			}
+			report.Created++
+		} else {
+			result.Action = "updated"
+			result.MovieID = existing.ID
+			mergeCatalogMovie(existing, movie)
+			if !dryRun {
+				if err := UpdateMovieWithGenres(existing, genreNames, info); err != nil {
+					result.Action = "error"
+					result.Errors = []string{err.Error()}
+					report.Failed++
+					report.Rows = append(report.Rows, result)
+					continue
+				}
+			}
+			report.Updated++
+		}
+
+		report.Rows = append(report.Rows, result)
+	}
+
+	fmt.Printf("[DEBUG-CATALOG] Importación (dry_run=%t): %d filas, %d creadas, %d actualizadas, %d con errores\n",
+		dryRun, report.Total, report.Created, report.Updated, report.Failed)
+
+	return report, nil
+}
+
+// ExportCatalog obtiene el catálogo completo en el formato de importación
+func ExportCatalog() ([]CatalogRow, error) {
+	var movies []models.Movie
+	if err := config.DB.Preload("Genres").Order("id ASC").Find(&movies).Error; err != nil {
+		return nil, err
+	}
+
+	rows := make([]CatalogRow, 0, len(movies))
+	for _, movie := range movies {
+		row := CatalogRow{
+			ExternalID:     movie.ExternalID,
+			ImdbID:         movie.ImdbID,
+			TmdbID:         movie.TmdbID,
+			Title:          movie.Title,
+			Description:    movie.Description,
+			Director:       movie.Director,
+			RuntimeMinutes: movie.RuntimeMinutes,
+			PosterURL:      movie.PosterURL,
+			Genres:         []string{},
+		}
+		if !movie.ReleaseDate.IsZero() {
+			row.ReleaseDate = movie.ReleaseDate.Format("2006-01-02")
+		}
+		for _, genre := range movie.Genres {
+			row.Genres = append(row.Genres, genre.Name)
+		}
+		if len(row.Genres) == 0 && movie.Genre != "" {
+			row.Genres = models.ParseGenresString(movie.Genre)
+		}
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+// WriteCatalogCSV escribe el catálogo en formato CSV
+func WriteCatalogCSV(w io.Writer, rows []CatalogRow) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(catalogColumns); err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		record := []string{
+			row.ExternalID,
+			row.ImdbID,
+			row.TmdbID,
+			row.Title,
+			row.Description,
+			row.Director,
+			row.ReleaseDate,
+			strconv.Itoa(row.RuntimeMinutes),
+			row.PosterURL,
+			strings.Join(row.Genres, ", "),
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// WriteCatalogJSON escribe el catálogo en formato JSON
+func WriteCatalogJSON(w io.Writer, rows []CatalogRow) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(rows)
+}
+
+// buildMovieFromRow valida una fila y construye la película correspondiente
+func buildMovieFromRow(row CatalogRow) (models.Movie, []string) {
+	var errs []string
+
+	movie := models.Movie{
+		ExternalID:     strings.TrimSpace(row.ExternalID),
+		ImdbID:         strings.TrimSpace(row.ImdbID),
+		TmdbID:         strings.TrimSpace(row.TmdbID),
+		Title:          strings.TrimSpace(row.Title),
+		Description:    strings.TrimSpace(row.Description),
+		Director:       strings.TrimSpace(row.Director),
+		RuntimeMinutes: row.RuntimeMinutes,
+		PosterURL:      strings.TrimSpace(row.PosterURL),
+	}
+
+	if movie.Title == "" {
+		errs = append(errs, "el título es obligatorio")
+	}
+
+	if row.ReleaseDate != "" {
+		date, err := utils.ParseDate(strings.TrimSpace(row.ReleaseDate))
+		if err != nil {
+			errs = append(errs, "fecha de estreno inválida: "+row.ReleaseDate)
+		} else {
+			movie.ReleaseDate = date
+		}
+	}
+
+	if row.invalidRuntime != "" {
+		errs = append(errs, fmt.Sprintf("duración inválida: %q no es un número de minutos", row.invalidRuntime))
+	} else if movie.RuntimeMinutes < 0 {
+		errs = append(errs, "la duración debe ser un número de minutos positivo")
+	}
+
+	if movie.PosterURL != "" && !strings.HasPrefix(movie.PosterURL, "http://") && !strings.HasPrefix(movie.PosterURL, "https://") {
+		errs = append(errs, "la URL del póster debe comenzar por http:// o https://")
+	}
+
+	return movie, errs
+}
+
+// catalogRowKey genera la clave con la que se identifica una película dentro del archivo
+func catalogRowKey(movie models.Movie) string {
+	if movie.ExternalID != "" {
+		return "ext:" + strings.ToLower(movie.ExternalID)
+	}
+	if movie.ImdbID != "" {
+		return "imdb:" + strings.ToLower(movie.ImdbID)
+	}
+	if movie.TmdbID != "" {
+		return "tmdb:" + movie.TmdbID
+	}
+	if movie.Title == "" {
+		return ""
+	}
+	year := ""
+	if !movie.ReleaseDate.IsZero() {
+		year = strconv.Itoa(movie.ReleaseDate.Year())
+	}
+	return "title:" + strings.ToLower(movie.Title) + "|" + year
+}
+
+// findCatalogMatch busca una película existente por ID externo (catálogo, IMDb o TMDB) o por título y año de estreno.
+// También busca en la papelera: los IDs externos siguen siendo únicos aunque la película esté eliminada.
+func findCatalogMatch(movie models.Movie) (*models.Movie, error) {
+	var existing models.Movie
+
+	identifiers := []struct {
+		column string
+		value  string
+	}{
+		{"external_id", movie.ExternalID},
+		{"imdb_id", movie.ImdbID},
+		{"tmdb_id", movie.TmdbID},
+	}
+	for _, id := range identifiers {
+		if id.value == "" {
+			continue
+		}
+		// Las películas activas tienen preferencia sobre las de la papelera
+		err := config.DB.Unscoped().Where(id.column+" = ?", id.value).Order("deleted_at IS NOT NULL").First(&existing).Error
+		if err == nil {
+			return &existing, nil
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+	}
+
+	query := config.DB.Unscoped().Where("LOWER(title) = LOWER(?)", movie.Title)
+	if !movie.ReleaseDate.IsZero() {
+		query = query.Where("EXTRACT(YEAR FROM release_date) = ?", movie.ReleaseDate.Year())
+	}
+	// No reutilizar películas que ya tienen otro ID externo asignado
+	if movie.ExternalID != "" {
+		query = query.Where("(external_id IS NULL OR external_id = '')")
+	}
+
+	err := query.Order("deleted_at IS NOT NULL").First(&existing).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &existing, nil
+}
+
+// mergeCatalogMovie copia sobre la película existente los campos informados en la fila
+func mergeCatalogMovie(existing *models.Movie, incoming models.Movie) {
+	if incoming.ExternalID != "" {
+		existing.ExternalID = incoming.ExternalID
+	}
+	if incoming.ImdbID != "" {
+		existing.ImdbID = incoming.ImdbID
+	}
+	if incoming.TmdbID != "" {
+		existing.TmdbID = incoming.TmdbID
+	}
+	if incoming.RuntimeMinutes != 0 {
+		existing.RuntimeMinutes = incoming.RuntimeMinutes
+	}
+	existing.Title = incoming.Title
+	if incoming.Description != "" {
+		existing.Description = incoming.Description
+	}
+	if incoming.Director != "" {
+		existing.Director = incoming.Director
+	}
+	if !incoming.ReleaseDate.IsZero() {
+		existing.ReleaseDate = incoming.ReleaseDate
+	}
+	if incoming.PosterURL != "" && incoming.PosterURL != existing.PosterURL {
+		existing.SetExternalPoster(incoming.PosterURL)
+	}
+}
+
+// mapGenreNames normaliza los géneros recibidos contra los existentes y los alias conocidos
+func mapGenreNames(names []string) ([]string, error) {
+	var existing []models.Genre
+	if err := config.DB.Find(&existing).Error; err != nil {
+		return nil, err
+	}
+
+	known := make(map[string]string)
+	for _, genre := range existing {
+		known[strings.ToLower(genre.Name)] = genre.Name
+	}
+
+	var mapped []string
+	seen := make(map[string]bool)
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		lower := strings.ToLower(name)
+		if alias, ok := genreAliases[lower]; ok {
+			name = alias
+			lower = strings.ToLower(alias)
+		}
+		if canonical, ok := known[lower]; ok {
+			name = canonical
+		}
+		if seen[lower] {
+			continue
+		}
+		seen[lower] = true
+		mapped = append(mapped, name)
+	}
+
+	return mapped, nil
+}
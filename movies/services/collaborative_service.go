@@ -0,0 +1,148 @@
+package services
+
+import (
+	"cine_conecta_backend/config"
+	movieModels "cine_conecta_backend/movies/models"
+	"fmt"
+	"time"
+)
+
+// Parámetros del filtrado colaborativo ítem-ítem
+const (
+	itemNeighbors  = 50  // Películas similares que se guardan por película
+	itemMinSupport = 2   // Usuarios en común necesarios para relacionar dos películas
+	itemShrinkage  = 5.0 // Reduce la similitud de las parejas con pocos usuarios en común
+)
+
+// Preferencia de un usuario por una película: un me gusta suma 1 y un comentario suma entre -1 y 1
+// según su puntuación (1..5, donde 3 es neutral)
+const (
+	likePreference       = 1.0
+	neutralCommentScore  = 3.0
+	commentPreferenceGap = 2.0
+)
+
+// SimilarityBuildReport resume el cálculo de la tabla de similitudes
+type SimilarityBuildReport struct {
+	Pairs    int64  `json:"pairs"`
+	Movies   int64  `json:"movies"`
+	Duration string `json:"duration"`
+}
+
+// collaborativeRecommendation es una película recomendada por el filtrado colaborativo y la película
+// valorada por el usuario que más aporta a la recomendación
+type collaborativeRecommendation struct {
+	MovieID        uint
+	Score          float64
+	BecauseOfTitle string
+}
+
+// preferencesQuery calcula la preferencia de cada usuario por cada película activa
+const preferencesQuery = `SELECT p.user_id, p.movie_id, SUM(p.value) AS value
+	FROM (
+		SELECT user_id, movie_id, CAST(@like AS double precision) AS value FROM movie_likes WHERE deleted_at IS NULL
+		UNION ALL
+		SELECT user_id, movie_id, (sentiment_score - CAST(@neutral AS double precision)) / CAST(@gap AS double precision) FROM comments
+	) p
+	JOIN movies m ON m.id = p.movie_id AND m.deleted_at IS NULL`
+
+// BuildItemSimilarities recalcula la tabla de similitudes entre películas a partir de los me gusta y de
+// la puntuación de los comentarios. La similitud es el coseno entre los vectores de preferencias de los
+// usuarios, reducida para las parejas con pocos usuarios en común; se guardan las más parecidas de cada película.
+func BuildItemSimilarities() (*SimilarityBuildReport, error) {
+	start := time.Now()
+
+	tx := config.DB.Begin()
+	if err := tx.Exec("DELETE FROM movie_similarities").Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	result := tx.Exec(`INSERT INTO movie_similarities (movie_id, similar_movie_id, similarity, support, computed_at)
+		WITH prefs AS (
+			`+preferencesQuery+`
+			GROUP BY p.user_id, p.movie_id
+		),
+		norms AS (
+			SELECT movie_id, SQRT(SUM(value * value)) AS norm FROM prefs GROUP BY movie_id
+		),
+		pairs AS (
+			SELECT a.movie_id, b.movie_id AS similar_movie_id,
+				SUM(a.value * b.value) / (na.norm * nb.norm) * COUNT(*) / (COUNT(*) + CAST(@shrinkage AS double precision)) AS similarity,
+				COUNT(*) AS support
+			FROM prefs a
+			JOIN prefs b ON b.user_id = a.user_id AND b.movie_id <> a.movie_id
+			JOIN norms na ON na.movie_id = a.movie_id
+			JOIN norms nb ON nb.movie_id = b.movie_id
+			WHERE na.norm > 0 AND nb.norm > 0
+			GROUP BY a.movie_id, b.movie_id, na.norm, nb.norm
+			HAVING COUNT(*) >= @min_support AND SUM(a.value * b.value) > 0
+		),
+		ranked AS (
+			SELECT movie_id, similar_movie_id, similarity, support,
+				ROW_NUMBER() OVER (PARTITION BY movie_id ORDER BY similarity DESC, similar_movie_id) AS position
+			FROM pairs
+		)
+		SELECT movie_id, similar_movie_id, similarity, support, NOW() FROM ranked WHERE position <= @neighbors`,
+		map[string]interface{}{
+			"like":        likePreference,
+			"neutral":     neutralCommentScore,
+			"gap":         commentPreferenceGap,
+			"shrinkage":   itemShrinkage,
+			"min_support": itemMinSupport,
+			"neighbors":   itemNeighbors,
+		})
+	if result.Error != nil {
+		tx.Rollback()
+		return nil, result.Error
+	}
+
+	report := &SimilarityBuildReport{Pairs: result.RowsAffected}
+	if err := tx.Raw("SELECT COUNT(DISTINCT movie_id) FROM movie_similarities").Scan(&report.Movies).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+
+	report.Duration = time.Since(start).Round(time.Millisecond).String()
+	fmt.Printf("[DEBUG-RECOMMENDATION] Similitudes calculadas: %d parejas de %d películas en %s\n", report.Pairs, report.Movies, report.Duration)
+	return report, nil
+}
+
+// getCollaborativeRecommendations puntúa las películas parecidas a las que el usuario valoró: cada película
+// suma la similitud con cada película valorada multiplicada por la preferencia del usuario por ella
+func getCollaborativeRecommendations(userID uint, limit int, excludeIDs []uint, restrictions ContentRestrictions) ([]collaborativeRecommendation, error) {
+	var rows []collaborativeRecommendation
+	query := config.DB.Raw(`WITH prefs AS (
+			`+preferencesQuery+`
+			WHERE p.user_id = @user
+			GROUP BY p.user_id, p.movie_id
+		)
+		SELECT s.similar_movie_id AS movie_id, SUM(s.similarity * prefs.value) AS score,
+			(ARRAY_AGG(source.title ORDER BY s.similarity * prefs.value DESC))[1] AS because_of_title
+		FROM movie_similarities s
+		JOIN prefs ON prefs.movie_id = s.movie_id
+		JOIN movies source ON source.id = s.movie_id
+		WHERE s.similar_movie_id IN (@allowed)
+			AND s.similar_movie_id NOT IN (SELECT movie_id FROM prefs)
+			AND s.similar_movie_id NOT IN (@exclude)
+		GROUP BY s.similar_movie_id
+		HAVING SUM(s.similarity * prefs.value) > 0
+		ORDER BY score DESC, s.similar_movie_id
+		LIMIT @limit`,
+		map[string]interface{}{
+			"like":    likePreference,
+			"neutral": neutralCommentScore,
+			"gap":     commentPreferenceGap,
+			"user":    userID,
+			"allowed": config.DB.Model(&movieModels.Movie{}).Select("movies.id").Scopes(ContentFilter(restrictions)),
+			"exclude": append([]uint{0}, excludeIDs...), // Nunca vacía para que NOT IN sea válido
+			"limit":   limit,
+		})
+	if err := query.Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
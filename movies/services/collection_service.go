@@ -0,0 +1,310 @@
+package services
+
+import (
+	"cine_conecta_backend/config"
+	"cine_conecta_backend/movies/models"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+// ErrCollectionNameTaken indica que ya existe otra colección con el mismo nombre
+var ErrCollectionNameTaken = errors.New("ya existe una colección con ese nombre")
+
+// ErrCollectionNameRequired indica que no se indicó el nombre de la colección
+var ErrCollectionNameRequired = errors.New("el nombre de la colección es obligatorio")
+
+// CollectionPage contiene una colección con sus películas y la valoración conjunta de los comentarios
+type CollectionPage struct {
+	models.Collection
+	AverageScore float64 `json:"average_score"` // Media de sentiment_score de los comentarios (1-5)
+	CommentCount int     `json:"comment_count"`
+	TotalMinutes int     `json:"total_minutes"`
+}
+
+// GetCollections obtiene las colecciones, opcionalmente filtradas por nombre
+func GetCollections(name string) ([]models.Collection, error) {
+	query := config.DB.Model(&models.Collection{}).
+		Select("collections.*, (SELECT COUNT(*) FROM collection_movies WHERE collection_movies.collection_id = collections.id AND collection_movies.movie_id IN (" + models.ActiveMovieIDs + ")) AS movie_count").
+		Order("collections.name ASC")
+
+	if name = strings.TrimSpace(name); name != "" {
+		query = query.Where("collections.name ILIKE ?", "%"+name+"%")
+	}
+
+	var collections []models.Collection
+	err := query.Find(&collections).Error
+	return collections, err
+}
+
+// GetCollectionByID obtiene una colección sin sus películas
+func GetCollectionByID(id uint) (models.Collection, error) {
+	var collection models.Collection
+	err := config.DB.First(&collection, id).Error
+	return collection, err
+}
+
+// GetCollectionPage obtiene una colección con sus películas en orden y la valoración conjunta,
+// sin las películas que no son aptas para el usuario
+func GetCollectionPage(id uint, restrictions ContentRestrictions) (*CollectionPage, error) {
+	allowed := config.DB.Model(&models.Movie{}).Select("movies.id").Scopes(ContentFilter(restrictions))
+
+	var collection models.Collection
+	err := config.DB.
+		Preload("Movies", func(db *gorm.DB) *gorm.DB {
+			return db.Where("movie_id IN (?)", allowed).Order("position ASC, id ASC")
+		}).
+		Preload("Movies.Movie.Genres").
+		First(&collection, id).Error
+	if err != nil {
+		return nil, err
+	}
+
+	page := CollectionPage{Collection: collection}
+	page.MovieCount = len(collection.Movies)
+	for _, member := range collection.Movies {
+		if member.Movie != nil {
+			page.TotalMinutes += member.Movie.RuntimeMinutes
+		}
+	}
+
+	// Valoración conjunta a partir de los comentarios de todas las películas de la colección
+	var aggregate struct {
+		AverageScore float64
+		CommentCount int
+	}
+	err = config.DB.Raw(`SELECT COALESCE(AVG(c.sentiment_score), 0) AS average_score, COUNT(c.id) AS comment_count
+		FROM comments c
+		JOIN collection_movies cm ON cm.movie_id = c.movie_id
+		WHERE cm.collection_id = ? AND c.movie_id IN (?)`, id, allowed).
+		Scan(&aggregate).Error
+	if err != nil {
+		return nil, err
+	}
+	page.AverageScore = math.Round(aggregate.AverageScore*10) / 10
+	page.CommentCount = aggregate.CommentCount
+
+	return &page, nil
+}
+
+// CreateCollection crea una colección vacía
+func CreateCollection(collection *models.Collection) error {
+	if err := validateCollection(collection); err != nil {
+		return err
+	}
+	collection.Movies = nil
+	if err := config.DB.Create(collection).Error; err != nil {
+		if isUniqueViolation(err) {
+			return ErrCollectionNameTaken
+		}
+		return err
+	}
+	return nil
+}
+
+// UpdateCollection actualiza los datos de una colección
+func UpdateCollection(collection *models.Collection) error {
+	if err := validateCollection(collection); err != nil {
+		return err
+	}
+	collection.Movies = nil
+	if err := config.DB.Save(collection).Error; err != nil {
+		if isUniqueViolation(err) {
+			return ErrCollectionNameTaken
+		}
+		return err
+	}
+	return nil
+}
+
+// DeleteCollection elimina una colección (las películas no se eliminan)
+func DeleteCollection(id uint) error {
+	tx := config.DB.Begin()
+
+	if err := tx.Where("collection_id = ?", id).Delete(&models.CollectionMovie{}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	result := tx.Delete(&models.Collection{}, id)
+	if result.Error != nil {
+		tx.Rollback()
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		tx.Rollback()
+		return gorm.ErrRecordNotFound
+	}
+
+	return tx.Commit().Error
+}
+
+// AddMovieToCollection añade una película a la colección en la posición indicada (0 = al final)
+func AddMovieToCollection(collectionID, movieID uint, position int) error {
+	if err := config.DB.First(&models.Collection{}, collectionID).Error; err != nil {
+		return err
+	}
+	if err := config.DB.Select("id").First(&models.Movie{}, movieID).Error; err != nil {
+		return errors.New("película no encontrada")
+	}
+
+	var existing int64
+	config.DB.Model(&models.CollectionMovie{}).Where("collection_id = ? AND movie_id = ?", collectionID, movieID).Count(&existing)
+	if existing > 0 {
+		return errors.New("la película ya pertenece a la colección")
+	}
+
+	tx := config.DB.Begin()
+
+	var count int64
+	if err := tx.Model(&models.CollectionMovie{}).Where("collection_id = ?", collectionID).Count(&count).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	if position <= 0 || position > int(count)+1 {
+		position = int(count) + 1
+	}
+
+	// Desplazar las películas que quedan detrás
+	if err := tx.Model(&models.CollectionMovie{}).
+		Where("collection_id = ? AND position >= ?", collectionID, position).
+		Update("position", gorm.Expr("position + 1")).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	member := models.CollectionMovie{CollectionID: collectionID, MovieID: movieID, Position: position}
+	if err := tx.Create(&member).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// RemoveMovieFromCollection quita una película de la colección y compacta las posiciones
+func RemoveMovieFromCollection(collectionID, movieID uint) error {
+	var member models.CollectionMovie
+	if err := config.DB.Where("collection_id = ? AND movie_id = ?", collectionID, movieID).First(&member).Error; err != nil {
+		return err
+	}
+
+	tx := config.DB.Begin()
+	if err := tx.Delete(&member).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := tx.Model(&models.CollectionMovie{}).
+		Where("collection_id = ? AND position > ?", collectionID, member.Position).
+		Update("position", gorm.Expr("position - 1")).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// ReorderCollection asigna el orden de la colección; deben enviarse todas sus películas
+func ReorderCollection(collectionID uint, movieIDs []uint) error {
+	var count int64
+	config.DB.Model(&models.CollectionMovie{}).Where("collection_id = ?", collectionID).Count(&count)
+	if int(count) != len(movieIDs) {
+		return errors.New("se deben indicar todas las películas de la colección")
+	}
+
+	tx := config.DB.Begin()
+	for i, movieID := range movieIDs {
+		result := tx.Model(&models.CollectionMovie{}).
+			Where("collection_id = ? AND movie_id = ?", collectionID, movieID).
+			Update("position", i+1)
+		if result.Error != nil {
+			tx.Rollback()
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			tx.Rollback()
+			return fmt.Errorf("la película %d no pertenece a la colección", movieID)
+		}
+	}
+
+	return tx.Commit().Error
+}
+
+// GetSeriesHints devuelve las colecciones de una película con la anterior y la siguiente de cada una
+func GetSeriesHints(movieID uint) ([]models.SeriesHint, error) {
+	var memberships []models.CollectionMovie
+	if err := config.DB.Where("movie_id = ?", movieID).Find(&memberships).Error; err != nil {
+		return nil, err
+	}
+	if len(memberships) == 0 {
+		return nil, nil
+	}
+
+	hints := make([]models.SeriesHint, 0, len(memberships))
+	for _, membership := range memberships {
+		var collection models.Collection
+		err := config.DB.
+			Preload("Movies", func(db *gorm.DB) *gorm.DB {
+				return db.Where("movie_id IN (" + models.ActiveMovieIDs + ")").Order("position ASC, id ASC")
+			}).
+			Preload("Movies.Movie", func(db *gorm.DB) *gorm.DB {
+				return db.Select("id", "title", "poster_url")
+			}).
+			First(&collection, membership.CollectionID).Error
+		if err != nil {
+			return nil, err
+		}
+
+		hint := models.SeriesHint{
+			CollectionID:   collection.ID,
+			CollectionName: collection.Name,
+			Total:          len(collection.Movies),
+		}
+		for i, member := range collection.Movies {
+			if member.MovieID != movieID {
+				continue
+			}
+			hint.Position = i + 1
+			if i > 0 {
+				hint.Previous = seriesMovie(collection.Movies[i-1], i)
+			}
+			if i < len(collection.Movies)-1 {
+				hint.Next = seriesMovie(collection.Movies[i+1], i+2)
+			}
+			break
+		}
+		hints = append(hints, hint)
+	}
+
+	return hints, nil
+}
+
+// seriesMovie resume una película de la colección para las pistas de la saga
+func seriesMovie(member models.CollectionMovie, position int) *models.SeriesMovie {
+	summary := models.SeriesMovie{ID: member.MovieID, Position: position}
+	if member.Movie != nil {
+		summary.Title = member.Movie.Title
+		summary.PosterURL = member.Movie.PosterURL
+	}
+	return &summary
+}
+
+// validateCollection comprueba los campos de una colección
+func validateCollection(collection *models.Collection) error {
+	collection.Name = strings.TrimSpace(collection.Name)
+	collection.Description = strings.TrimSpace(collection.Description)
+	if collection.Name == "" {
+		return ErrCollectionNameRequired
+	}
+	return nil
+}
+
+// isUniqueViolation indica si el error de PostgreSQL se debe a un índice único
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
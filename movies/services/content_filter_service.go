@@ -0,0 +1,231 @@
+package services
+
+import (
+	authModels "cine_conecta_backend/auth/models"
+	authServices "cine_conecta_backend/auth/services"
+	"cine_conecta_backend/config"
+	"cine_conecta_backend/movies/models"
+	"errors"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ContentRestrictions define qué películas puede ver un usuario según su edad y su control parental
+type ContentRestrictions struct {
+	MaxAge            int      // Edad mínima más alta permitida (-1 = sin límite)
+	Country           string   // País cuya clasificación se aplica primero
+	BlockedAdvisories []string // Avisos de contenido que ocultan la película
+}
+
+// NoContentRestrictions no oculta ninguna película (administradores y usuarios sin ajustes)
+var NoContentRestrictions = ContentRestrictions{MaxAge: -1}
+
+// Active indica si las restricciones ocultan alguna película
+func (r ContentRestrictions) Active() bool {
+	return r.MaxAge >= 0 || len(r.BlockedAdvisories) > 0
+}
+
+// GetContentRestrictions calcula las restricciones de un usuario. La edad permitida es la del usuario
+// y, con el control parental activo, como mucho la edad máxima configurada.
+func GetContentRestrictions(userID uint, role string) ContentRestrictions {
+	if userID == 0 || role == "admin" {
+		return NoContentRestrictions
+	}
+
+	var user authModels.User
+	if err := config.DB.First(&user, userID).Error; err != nil {
+		return NoContentRestrictions
+	}
+
+	restrictions := ContentRestrictions{
+		MaxAge:            -1,
+		Country:           user.Country,
+		BlockedAdvisories: user.BlockedAdvisories,
+	}
+	if user.BirthDate != nil {
+		restrictions.MaxAge = authServices.UserAge(*user.BirthDate, time.Now())
+	}
+	if user.ParentalControl && (restrictions.MaxAge < 0 || user.ParentalMaxAge < restrictions.MaxAge) {
+		restrictions.MaxAge = user.ParentalMaxAge
+	}
+	return restrictions
+}
+
+// ContentFilter devuelve un scope que excluye las películas no aptas. Si la película no tiene
+// clasificación en el país del usuario se usa la más restrictiva de las demás.
+func ContentFilter(restrictions ContentRestrictions) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if restrictions.MaxAge >= 0 {
+			db = db.Where(`COALESCE(
+				(SELECT mc.min_age FROM movie_certifications mc WHERE mc.movie_id = movies.id AND mc.country = ?),
+				(SELECT MAX(mc.min_age) FROM movie_certifications mc WHERE mc.movie_id = movies.id),
+				0) <= ?`, restrictions.Country, restrictions.MaxAge)
+		}
+		if len(restrictions.BlockedAdvisories) > 0 {
+			db = db.Where(`NOT EXISTS (
+				SELECT 1 FROM jsonb_array_elements_text(CASE WHEN jsonb_typeof(movies.content_advisories) = 'array' THEN movies.content_advisories ELSE '[]'::jsonb END) AS advisory(tag)
+				WHERE advisory.tag IN ?)`, restrictions.BlockedAdvisories)
+		}
+		return db
+	}
+}
+
+// IsMovieAllowed indica si una película es apta según las restricciones
+func IsMovieAllowed(restrictions ContentRestrictions, movieID uint) bool {
+	if !restrictions.Active() {
+		return true
+	}
+	var count int64
+	config.DB.Model(&models.Movie{}).Scopes(ContentFilter(restrictions)).Where("movies.id = ?", movieID).Count(&count)
+	return count > 0
+}
+
+// SaveMovieCertification crea o reemplaza la clasificación de una película en un país
+func SaveMovieCertification(movieID uint, country, rating string, minAge *int, userID uint) (*models.MovieCertification, error) {
+	country = strings.ToUpper(strings.TrimSpace(country))
+	if len(country) != 2 {
+		return nil, errors.New("país inválido (use el código de dos letras, por ejemplo CO)")
+	}
+	rating = strings.TrimSpace(rating)
+	if rating == "" {
+		return nil, errors.New("la clasificación es obligatoria")
+	}
+
+	age, known := models.CertificationMinAge(rating)
+	if minAge != nil {
+		age = *minAge
+	} else if !known {
+		return nil, errors.New("no se pudo deducir la edad mínima de la clasificación, indique min_age")
+	}
+	if age < 0 || age > 21 {
+		return nil, errors.New("la edad mínima debe estar entre 0 y 21")
+	}
+
+	if err := config.DB.Select("id").First(&models.Movie{}, movieID).Error; err != nil {
+		return nil, err
+	}
+
+	tx := config.DB.Begin()
+
+	before, err := snapshotMovie(tx, movieID)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	var certification models.MovieCertification
+	err = tx.Where("movie_id = ? AND country = ?", movieID, country).First(&certification).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		tx.Rollback()
+		return nil, err
+	}
+
+	certification.MovieID = movieID
+	certification.Country = country
+	certification.Rating = rating
+	certification.MinAge = age
+	if err := tx.Save(&certification).Error; err != nil {
+		tx.Rollback()
+		return nil, errors.New("error al guardar la clasificación")
+	}
+
+	info := RevisionInfo{UserID: userID, Source: models.RevisionSourceManual}
+	if err := recordRevision(tx, movieID, info, &before); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+	return &certification, nil
+}
+
+// DeleteMovieCertification elimina la clasificación de una película en un país
+func DeleteMovieCertification(movieID uint, country string, userID uint) error {
+	tx := config.DB.Begin()
+
+	before, err := snapshotMovie(tx, movieID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	result := tx.Where("movie_id = ? AND country = ?", movieID, strings.ToUpper(country)).Delete(&models.MovieCertification{})
+	if result.Error != nil {
+		tx.Rollback()
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		tx.Rollback()
+		return gorm.ErrRecordNotFound
+	}
+
+	info := RevisionInfo{UserID: userID, Source: models.RevisionSourceManual}
+	if err := recordRevision(tx, movieID, info, &before); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// GetMovieCertifications obtiene las clasificaciones y los avisos de contenido de una película
+func GetMovieCertifications(movieID uint) (models.Movie, error) {
+	var movie models.Movie
+	err := config.DB.Select("id", "title", "content_advisories").
+		Preload("Certifications", func(db *gorm.DB) *gorm.DB {
+			return db.Order("country ASC")
+		}).
+		First(&movie, movieID).Error
+	return movie, err
+}
+
+// SetMovieAdvisories reemplaza los avisos de contenido de una película
+func SetMovieAdvisories(movieID uint, advisories []string, userID uint) ([]string, error) {
+	tags := make([]string, 0, len(advisories))
+	seen := make(map[string]bool)
+	for _, tag := range advisories {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if !models.IsValidAdvisory(tag) {
+			return nil, errors.New("aviso de contenido no válido: " + tag)
+		}
+		if !seen[tag] {
+			seen[tag] = true
+			tags = append(tags, tag)
+		}
+	}
+
+	tx := config.DB.Begin()
+
+	before, err := snapshotMovie(tx, movieID)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	result := tx.Model(&models.Movie{ID: movieID}).
+		Select("content_advisories").
+		Updates(&models.Movie{ContentAdvisories: tags})
+	if result.Error != nil {
+		tx.Rollback()
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		tx.Rollback()
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	info := RevisionInfo{UserID: userID, Source: models.RevisionSourceManual}
+	if err := recordRevision(tx, movieID, info, &before); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
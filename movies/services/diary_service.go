@@ -0,0 +1,283 @@
+package services
+
+import (
+	commentModels "cine_conecta_backend/comments/models"
+	"cine_conecta_backend/config"
+	"cine_conecta_backend/movies/models"
+	"errors"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// DiaryFilter define los filtros del diario de un usuario
+type DiaryFilter struct {
+	Year    int
+	Month   int // Solo se aplica junto con el año
+	MovieID uint
+}
+
+// DiaryEntryInput contiene los datos editables de una entrada del diario
+type DiaryEntryInput struct {
+	MovieID   uint     `json:"movie_id"`
+	WatchedOn string   `json:"watched_on"` // Formato YYYY-MM-DD (por defecto hoy)
+	Rewatch   *bool    `json:"rewatch"`    // Si no se indica se deduce de las entradas anteriores
+	Rating    *float64 `json:"rating"`
+	CommentID *uint    `json:"comment_id"`
+}
+
+// DiaryPeriodCount es la cantidad de películas vistas en un mes o un día
+type DiaryPeriodCount struct {
+	Period int `json:"period"` // Mes (1-12) en las estadísticas anuales o día en las mensuales
+	Count  int `json:"count"`
+}
+
+// DiaryGenreCount es la cantidad de visualizaciones de un género
+type DiaryGenreCount struct {
+	Genre string `json:"genre"`
+	Count int    `json:"count"`
+}
+
+// DiaryStats resume la actividad del diario en un año o un mes
+type DiaryStats struct {
+	Year          int                `json:"year"`
+	Month         int                `json:"month,omitempty"`
+	Watches       int                `json:"watches"`
+	UniqueMovies  int                `json:"unique_movies"`
+	Rewatches     int                `json:"rewatches"`
+	RatedCount    int                `json:"rated_count"`
+	AverageRating float64            `json:"average_rating"`
+	TotalMinutes  int                `json:"total_minutes"`
+	Breakdown     []DiaryPeriodCount `json:"breakdown"`
+	TopGenres     []DiaryGenreCount  `json:"top_genres"`
+}
+
+// GetDiary devuelve las entradas del diario de un usuario, las más recientes primero
+func GetDiary(userID uint, filter DiaryFilter) ([]models.DiaryEntry, error) {
+	query := config.DB.Where("user_id = ? AND movie_id IN ("+models.ActiveMovieIDs+")", userID)
+
+	if filter.Year != 0 {
+		from, to := diaryPeriod(filter.Year, filter.Month)
+		query = query.Where("watched_on >= ? AND watched_on < ?", from, to)
+	}
+	if filter.MovieID != 0 {
+		query = query.Where("movie_id = ?", filter.MovieID)
+	}
+
+	var entries []models.DiaryEntry
+	err := query.Preload("Movie").Order("watched_on DESC, id DESC").Find(&entries).Error
+	return entries, err
+}
+
+// CreateDiaryEntry registra que el usuario vio una película
+func CreateDiaryEntry(userID uint, input DiaryEntryInput) (*models.DiaryEntry, error) {
+	if err := config.DB.Select("id").First(&models.Movie{}, input.MovieID).Error; err != nil {
+		return nil, errors.New("película no encontrada")
+	}
+
+	entry := models.DiaryEntry{
+		UserID:    userID,
+		MovieID:   input.MovieID,
+		Rating:    input.Rating,
+		CommentID: input.CommentID,
+	}
+	if err := applyDiaryInput(&entry, input); err != nil {
+		return nil, err
+	}
+
+	// Sin indicación expresa, es un nuevo visionado si ya había una entrada anterior
+	if input.Rewatch != nil {
+		entry.Rewatch = *input.Rewatch
+	} else {
+		var previous int64
+		config.DB.Model(&models.DiaryEntry{}).
+			Where("user_id = ? AND movie_id = ? AND watched_on <= ?", userID, input.MovieID, entry.WatchedOn).
+			Count(&previous)
+		entry.Rewatch = previous > 0
+	}
+
+	if err := config.DB.Create(&entry).Error; err != nil {
+		return nil, errors.New("error al registrar la película en el diario")
+	}
+	return &entry, nil
+}
+
+// UpdateDiaryEntry modifica una entrada del diario del usuario
+func UpdateDiaryEntry(userID, entryID uint, input DiaryEntryInput) (*models.DiaryEntry, error) {
+	var entry models.DiaryEntry
+	if err := config.DB.Where("id = ? AND user_id = ?", entryID, userID).First(&entry).Error; err != nil {
+		return nil, err
+	}
+
+	if input.Rating != nil {
+		entry.Rating = input.Rating
+	}
+	if input.CommentID != nil {
+		entry.CommentID = input.CommentID
+	}
+	if input.Rewatch != nil {
+		entry.Rewatch = *input.Rewatch
+	}
+	if input.WatchedOn == "" {
+		input.WatchedOn = entry.WatchedOn.Format("2006-01-02")
+	}
+	if err := applyDiaryInput(&entry, input); err != nil {
+		return nil, err
+	}
+
+	if err := config.DB.Save(&entry).Error; err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// DeleteDiaryEntry elimina una entrada del diario del usuario
+func DeleteDiaryEntry(userID, entryID uint) error {
+	result := config.DB.Where("id = ? AND user_id = ?", entryID, userID).Delete(&models.DiaryEntry{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// GetDiaryStats calcula las estadísticas del diario de un año (month = 0) o de un mes
+func GetDiaryStats(userID uint, year, month int) (*DiaryStats, error) {
+	if year < 1900 || year > 9999 {
+		return nil, errors.New("año inválido")
+	}
+	if month < 0 || month > 12 {
+		return nil, errors.New("mes inválido")
+	}
+
+	from, to := diaryPeriod(year, month)
+	var entries []models.DiaryEntry
+	if err := config.DB.Preload("Movie").
+		Where("user_id = ? AND watched_on >= ? AND watched_on < ? AND movie_id IN ("+models.ActiveMovieIDs+")", userID, from, to).
+		Find(&entries).Error; err != nil {
+		return nil, err
+	}
+
+	stats := DiaryStats{Year: year, Month: month, Watches: len(entries)}
+
+	// Un periodo por mes del año o por día del mes
+	periods := 12
+	if month != 0 {
+		periods = to.AddDate(0, 0, -1).Day()
+	}
+	counts := make([]int, periods)
+
+	movies := make(map[uint]bool)
+	genres := make(map[string]int)
+	ratingSum := 0.0
+	for _, entry := range entries {
+		if month == 0 {
+			counts[int(entry.WatchedOn.Month())-1]++
+		} else {
+			counts[entry.WatchedOn.Day()-1]++
+		}
+
+		movies[entry.MovieID] = true
+		if entry.Rewatch {
+			stats.Rewatches++
+		}
+		if entry.Rating != nil {
+			stats.RatedCount++
+			ratingSum += *entry.Rating
+		}
+		if entry.Movie != nil {
+			stats.TotalMinutes += entry.Movie.RuntimeMinutes
+			for _, genre := range models.ParseGenresString(entry.Movie.Genre) {
+				genres[genre]++
+			}
+		}
+	}
+
+	stats.UniqueMovies = len(movies)
+	if stats.RatedCount > 0 {
+		stats.AverageRating = math.Round(ratingSum/float64(stats.RatedCount)*10) / 10
+	}
+
+	stats.Breakdown = make([]DiaryPeriodCount, periods)
+	for i, count := range counts {
+		stats.Breakdown[i] = DiaryPeriodCount{Period: i + 1, Count: count}
+	}
+
+	stats.TopGenres = []DiaryGenreCount{}
+	for genre, count := range genres {
+		stats.TopGenres = append(stats.TopGenres, DiaryGenreCount{Genre: genre, Count: count})
+	}
+	sort.Slice(stats.TopGenres, func(i, j int) bool {
+		if stats.TopGenres[i].Count != stats.TopGenres[j].Count {
+			return stats.TopGenres[i].Count > stats.TopGenres[j].Count
+		}
+		return stats.TopGenres[i].Genre < stats.TopGenres[j].Genre
+	})
+	if len(stats.TopGenres) > 5 {
+		stats.TopGenres = stats.TopGenres[:5]
+	}
+
+	return &stats, nil
+}
+
+// GetSeenMovieIDs devuelve las películas que el usuario ya vio, según su diario o sus comentarios
+func GetSeenMovieIDs(userID uint) ([]uint, error) {
+	var ids []uint
+	err := config.DB.Raw(`SELECT movie_id FROM diary_entries WHERE user_id = ?
+		UNION
+		SELECT movie_id FROM comments WHERE user_id = ?`, userID, userID).
+		Scan(&ids).Error
+	return ids, err
+}
+
+// applyDiaryInput valida la fecha, la valoración y el comentario de una entrada
+func applyDiaryInput(entry *models.DiaryEntry, input DiaryEntryInput) error {
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	entry.WatchedOn = today
+	if date := strings.TrimSpace(input.WatchedOn); date != "" {
+		watchedOn, err := time.Parse("2006-01-02", date)
+		if err != nil {
+			return errors.New("fecha inválida, use el formato YYYY-MM-DD")
+		}
+		// Se admite un día de margen por las diferencias de zona horaria
+		if watchedOn.After(today.AddDate(0, 0, 1)) {
+			return errors.New("la fecha no puede ser futura")
+		}
+		entry.WatchedOn = watchedOn
+	}
+
+	if entry.Rating != nil && (*entry.Rating < 1 || *entry.Rating > 10) {
+		return errors.New("la valoración debe estar entre 1 y 10")
+	}
+
+	if entry.CommentID != nil {
+		if *entry.CommentID == 0 {
+			entry.CommentID = nil
+		} else {
+			var count int64
+			config.DB.Model(&commentModels.Comment{}).
+				Where("id = ? AND user_id = ? AND movie_id = ?", *entry.CommentID, entry.UserID, entry.MovieID).
+				Count(&count)
+			if count == 0 {
+				return errors.New("el comentario no existe o no es tuyo sobre esta película")
+			}
+		}
+	}
+
+	return nil
+}
+
+// diaryPeriod devuelve el intervalo [desde, hasta) de un año o de un mes
+func diaryPeriod(year, month int) (time.Time, time.Time) {
+	if month < 1 || month > 12 {
+		from := time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)
+		return from, from.AddDate(1, 0, 0)
+	}
+	from := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC)
+	return from, from.AddDate(0, 1, 0)
+}
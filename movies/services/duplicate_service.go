@@ -0,0 +1,533 @@
+package services
+
+import (
+	"cine_conecta_backend/config"
+	"cine_conecta_backend/movies/counters"
+	"cine_conecta_backend/movies/models"
+	"cine_conecta_backend/movies/slugs"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// DefaultDuplicateThreshold es la puntuación mínima para considerar dos películas como posibles duplicados
+const DefaultDuplicateThreshold = 0.75
+
+// ErrMergeSameMovie indica que se intentó fusionar una película consigo misma
+var ErrMergeSameMovie = errors.New("no se puede fusionar una película consigo misma")
+
+// DuplicateCandidate es una pareja de películas que probablemente son la misma
+type DuplicateCandidate struct {
+	Movie      models.Movie `json:"movie"`     // La más antigua, propuesta como la que se conserva
+	Duplicate  models.Movie `json:"duplicate"` // La que se fusionaría sobre la primera
+	Similarity float64      `json:"title_similarity"`
+	Score      float64      `json:"score"`
+	Reasons    []string     `json:"reasons"`
+}
+
+// MergeReport resume una fusión de películas
+type MergeReport struct {
+	TargetID         uint             `json:"target_id"`
+	SourceID         uint             `json:"source_id"`
+	Moved            map[string]int64 `json:"moved"`             // Registros trasladados por tabla
+	Discarded        map[string]int64 `json:"discarded"`         // Registros repetidos que se descartaron
+	CommentConflicts int              `json:"comment_conflicts"` // Usuarios que habían comentado ambas películas
+	FilledFields     []string         `json:"filled_fields"`     // Campos vacíos completados con los del duplicado
+	Movie            *models.Movie    `json:"movie"`
+}
+
+// Tablas que se trasladan a la película que se conserva. Las columnas indican la clave única junto a
+// movie_id: los registros del duplicado que chocan con uno existente se descartan.
+var mergeTables = []struct {
+	table string
+	keys  []string
+}{
+	{"movie_genres", []string{"genre_id"}},
+	{"movie_credits", []string{"person_id", "role", "character"}},
+	{"movie_media", nil},
+	{"movie_translations", []string{"locale"}},
+	{"movie_certifications", []string{"country"}},
+	{"watchlist_items", []string{"user_id"}},
+	{"diary_entries", nil},
+	{"movie_list_items", []string{"list_id"}},
+	{"collection_movies", []string{"collection_id"}},
+	{"showtimes", nil},
+}
+
+// FindDuplicateMovies busca parejas de películas con título parecido, mismo año y mismo director.
+// threshold es la puntuación mínima (0-1) y limit el número máximo de parejas devueltas.
+func FindDuplicateMovies(threshold float64, limit int) ([]DuplicateCandidate, error) {
+	var movies []models.Movie
+	err := config.DB.
+		Select("id", "title", "director", "release_date", "poster_url", "imdb_id", "tmdb_id", "created_at").
+		Order("id ASC").
+		Find(&movies).Error
+	if err != nil {
+		return nil, err
+	}
+
+	titles := make([]string, len(movies))
+	directors := make([]string, len(movies))
+	// Índices de las películas por año de estreno (0 si es desconocido)
+	byYear := make(map[int][]int)
+	for i, movie := range movies {
+		titles[i] = normalizeTitle(movie.Title)
+		directors[i] = models.FoldText(movie.Director)
+		byYear[releaseYear(movie)] = append(byYear[releaseYear(movie)], i)
+	}
+
+	candidates := []DuplicateCandidate{}
+	compare := func(i, j int) {
+		if candidate, ok := duplicatePair(movies[i], movies[j], titles[i], titles[j], directors[i], directors[j], threshold); ok {
+			candidates = append(candidates, candidate)
+		}
+	}
+
+	// Con más de un año de diferencia no son la misma película: cada una se compara solo con las de su año,
+	// los contiguos y las de año desconocido. Las de año desconocido se comparan con todas.
+	for i := range movies {
+		year := releaseYear(movies[i])
+		if year == 0 {
+			for j := i + 1; j < len(movies); j++ {
+				compare(i, j)
+			}
+			continue
+		}
+		for _, bucket := range [][]int{byYear[year-1], byYear[year], byYear[year+1], byYear[0]} {
+			for _, j := range bucket {
+				if j > i {
+					compare(i, j)
+				}
+			}
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].Score != candidates[j].Score {
+			return candidates[i].Score > candidates[j].Score
+		}
+		if candidates[i].Movie.ID != candidates[j].Movie.ID {
+			return candidates[i].Movie.ID < candidates[j].Movie.ID
+		}
+		return candidates[i].Duplicate.ID < candidates[j].Duplicate.ID
+	})
+	if limit > 0 && len(candidates) > limit {
+		candidates = candidates[:limit]
+	}
+	return candidates, nil
+}
+
+// releaseYear devuelve el año de estreno de una película, o 0 si no se conoce
+func releaseYear(movie models.Movie) int {
+	if movie.ReleaseDate.IsZero() {
+		return 0
+	}
+	return movie.ReleaseDate.Year()
+}
+
+// duplicatePair puntúa una pareja de películas (a es la más antigua) con sus títulos y directores normalizados.
+// Devuelve false si no pueden ser la misma película o la puntuación no llega al umbral.
+func duplicatePair(a, b models.Movie, titleA, titleB, directorA, directorB string, threshold float64) (DuplicateCandidate, bool) {
+	// Identificadores externos distintos indican películas distintas (remakes, homónimas)
+	if a.ImdbID != "" && b.ImdbID != "" && a.ImdbID != b.ImdbID {
+		return DuplicateCandidate{}, false
+	}
+	if a.TmdbID != "" && b.TmdbID != "" && a.TmdbID != b.TmdbID {
+		return DuplicateCandidate{}, false
+	}
+
+	similarity := titleSimilarity(titleA, titleB)
+	if similarity < 0.6 {
+		return DuplicateCandidate{}, false
+	}
+
+	var reasons []string
+	reasons = append(reasons, fmt.Sprintf("título similar (%d%%)", int(math.Round(similarity*100))))
+
+	// Año de estreno: igual suma, desconocido es neutro y con más de un año de diferencia no es la misma
+	yearScore := 0.5
+	if !a.ReleaseDate.IsZero() && !b.ReleaseDate.IsZero() {
+		diff := a.ReleaseDate.Year() - b.ReleaseDate.Year()
+		switch {
+		case diff == 0:
+			yearScore = 1
+			reasons = append(reasons, "mismo año")
+		case diff == 1 || diff == -1:
+			yearScore = 0.5
+			reasons = append(reasons, "año contiguo")
+		default:
+			return DuplicateCandidate{}, false
+		}
+	}
+
+	directorScore := 0.5
+	if directorA != "" && directorB != "" {
+		if directorA == directorB {
+			directorScore = 1
+			reasons = append(reasons, "mismo director")
+		} else {
+			directorScore = 0
+		}
+	}
+
+	if a.ImdbID != "" && a.ImdbID == b.ImdbID {
+		reasons = append(reasons, "mismo IMDb ID")
+	}
+
+	score := 0.6*similarity + 0.25*yearScore + 0.15*directorScore
+	if score < threshold {
+		return DuplicateCandidate{}, false
+	}
+
+	return DuplicateCandidate{
+		Movie:      a,
+		Duplicate:  b,
+		Similarity: math.Round(similarity*100) / 100,
+		Score:      math.Round(score*100) / 100,
+		Reasons:    reasons,
+	}, true
+}
+
+// MergeMovies fusiona la película sourceID sobre targetID: traslada comentarios, me gusta, géneros,
+// multimedia y el resto de registros asociados, completa los campos vacíos y elimina el duplicado.
+// Si un usuario comentó ambas películas se conserva su comentario más reciente.
+func MergeMovies(targetID, sourceID, userID uint) (*MergeReport, error) {
+	if targetID == sourceID {
+		return nil, ErrMergeSameMovie
+	}
+
+	var target, source models.Movie
+	if err := config.DB.First(&target, targetID).Error; err != nil {
+		return nil, err
+	}
+	if err := config.DB.First(&source, sourceID).Error; err != nil {
+		return nil, err
+	}
+
+	report := &MergeReport{
+		TargetID:     targetID,
+		SourceID:     sourceID,
+		Moved:        map[string]int64{},
+		Discarded:    map[string]int64{},
+		FilledFields: []string{},
+	}
+
+	tx := config.DB.Begin()
+
+	before, err := snapshotMovie(tx, targetID)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := mergeComments(tx, targetID, sourceID, report); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := mergeLikes(tx, targetID, sourceID, report); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	for _, entry := range mergeTables {
+		if err := moveMovieRows(tx, entry.table, entry.keys, targetID, sourceID, report); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	// Los enlaces con el slug del duplicado pasan a redirigir a la película que se conserva
+	if err := tx.Exec("UPDATE movie_slug_redirects SET movie_id = ? WHERE movie_id = ?", targetID, sourceID).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if source.Slug != "" {
+		if err := tx.Create(&models.MovieSlugRedirect{Slug: source.Slug, MovieID: targetID}).Error; err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	// Las visitas del duplicado se suman a las de la película que se conserva
+	err = tx.Exec(`INSERT INTO movie_view_stats (movie_id, hour, views)
+		SELECT ?, hour, views FROM movie_view_stats WHERE movie_id = ?
+		ON CONFLICT (movie_id, hour) DO UPDATE SET views = movie_view_stats.views + EXCLUDED.views`, targetID, sourceID).Error
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	// Las revisiones del duplicado, sus visitas, sus posiciones en instantáneas de tendencias
+	// y sus apariciones en datasets guardados desaparecen con él
+	for _, table := range []string{"movie_revisions", "movie_view_stats", "trending_snapshot_entries"} {
+		if err := tx.Exec("DELETE FROM "+table+" WHERE movie_id = ?", sourceID).Error; err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	// Las similitudes y los factores se recalculan con los me gusta y comentarios trasladados en el siguiente cálculo
+	if err := tx.Exec("DELETE FROM movie_similarities WHERE movie_id = ? OR similar_movie_id = ?", sourceID, sourceID).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := tx.Exec("DELETE FROM movie_factors WHERE movie_id = ?", sourceID).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := removeFromRecommendationDatasets(tx, sourceID); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := tx.Unscoped().Delete(&models.Movie{}, sourceID).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	// Completar los datos que faltan en la película que se conserva
+	columns := fillMissingFields(&target, source, report)
+	var genres []string
+	err = tx.Table("genres").
+		Joins("JOIN movie_genres ON movie_genres.genre_id = genres.id").
+		Where("movie_genres.movie_id = ?", targetID).
+		Order("genres.name ASC").
+		Pluck("genres.name", &genres).Error
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if len(genres) > 0 {
+		target.Genre = strings.Join(genres, ", ")
+		columns = append(columns, "genre")
+	}
+	if len(columns) > 0 {
+		if err := tx.Model(&target).Select(columns).Updates(&target).Error; err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	if err := slugs.Sync(tx, &target); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	// Recalcular los contadores y la valoración con los comentarios y me gusta trasladados
+	if err := counters.Recompute(tx, targetID); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	info := RevisionInfo{UserID: userID, Source: models.RevisionSourceMerge}
+	if err := recordRevision(tx, targetID, info, &before); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+
+	var merged models.Movie
+	if err := config.DB.Preload("Genres").Preload("Media").First(&merged, targetID).Error; err != nil {
+		return nil, err
+	}
+	report.Movie = &merged
+
+	// Eliminar los archivos del póster del duplicado que no se hayan conservado
+	deleteReplacedObjects(posterURLs(source), posterURLs(merged))
+
+	fmt.Printf("[DEBUG-MERGE] Película %d fusionada sobre %d: %v\n", sourceID, targetID, report.Moved)
+	return report, nil
+}
+
+// mergeComments traslada los comentarios. Si un usuario comentó ambas películas se conserva el más
+// reciente, ya que solo se permite un comentario por usuario y película.
+func mergeComments(tx *gorm.DB, targetID, sourceID uint, report *MergeReport) error {
+	var conflicts []struct {
+		SourceID    uint
+		TargetID    uint
+		SourceNewer bool
+	}
+	err := tx.Raw(`SELECT s.id AS source_id, t.id AS target_id, s.updated_at > t.updated_at AS source_newer
+		FROM comments s
+		JOIN comments t ON t.user_id = s.user_id AND t.movie_id = ?
+		WHERE s.movie_id = ?`, targetID, sourceID).
+		Scan(&conflicts).Error
+	if err != nil {
+		return err
+	}
+
+	for _, conflict := range conflicts {
+		keep, drop := conflict.TargetID, conflict.SourceID
+		if conflict.SourceNewer {
+			keep, drop = conflict.SourceID, conflict.TargetID
+		}
+		// Las entradas del diario que apuntaban al comentario descartado pasan al que se conserva
+		if err := tx.Exec("UPDATE diary_entries SET comment_id = ? WHERE comment_id = ?", keep, drop).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM comments WHERE id = ?", drop).Error; err != nil {
+			return err
+		}
+	}
+	report.CommentConflicts = len(conflicts)
+	if len(conflicts) > 0 {
+		report.Discarded["comments"] = int64(len(conflicts))
+	}
+
+	result := tx.Exec("UPDATE comments SET movie_id = ? WHERE movie_id = ?", targetID, sourceID)
+	if result.Error != nil {
+		return result.Error
+	}
+	report.Moved["comments"] = result.RowsAffected
+	return nil
+}
+
+// mergeLikes traslada los me gusta. El índice único incluye los me gusta retirados, así que si el
+// usuario tenía uno retirado en la película que se conserva y uno vigente en el duplicado, se reactiva.
+func mergeLikes(tx *gorm.DB, targetID, sourceID uint, report *MergeReport) error {
+	err := tx.Exec(`UPDATE movie_likes t SET deleted_at = NULL
+		FROM movie_likes s
+		WHERE t.movie_id = ? AND s.movie_id = ? AND s.user_id = t.user_id
+			AND s.deleted_at IS NULL AND t.deleted_at IS NOT NULL`, targetID, sourceID).Error
+	if err != nil {
+		return err
+	}
+	return moveMovieRows(tx, "movie_likes", []string{"user_id"}, targetID, sourceID, report)
+}
+
+// moveMovieRows cambia el movie_id de los registros de una tabla, descartando antes los que ya
+// existen en la película destino según las columnas de la clave única
+func moveMovieRows(tx *gorm.DB, table string, keys []string, targetID, sourceID uint, report *MergeReport) error {
+	if len(keys) > 0 {
+		conditions := make([]string, len(keys))
+		for i, key := range keys {
+			conditions[i] = fmt.Sprintf("t.%s = s.%s", key, key)
+		}
+		result := tx.Exec(fmt.Sprintf("DELETE FROM %s s USING %s t WHERE s.movie_id = ? AND t.movie_id = ? AND %s",
+			table, table, strings.Join(conditions, " AND ")), sourceID, targetID)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected > 0 {
+			report.Discarded[table] = result.RowsAffected
+		}
+	}
+
+	result := tx.Exec("UPDATE "+table+" SET movie_id = ? WHERE movie_id = ?", targetID, sourceID)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected > 0 {
+		report.Moved[table] = result.RowsAffected
+	}
+	return nil
+}
+
+// fillMissingFields completa los campos vacíos de la película que se conserva con los del duplicado
+// y devuelve las columnas modificadas
+func fillMissingFields(target *models.Movie, source models.Movie, report *MergeReport) []string {
+	var columns []string
+	fill := func(column string, empty bool, apply func()) {
+		if empty {
+			apply()
+			columns = append(columns, column)
+			report.FilledFields = append(report.FilledFields, column)
+		}
+	}
+
+	fill("description", target.Description == "" && source.Description != "", func() { target.Description = source.Description })
+	fill("director", target.Director == "" && source.Director != "", func() { target.Director = source.Director })
+	fill("release_date", target.ReleaseDate.IsZero() && !source.ReleaseDate.IsZero(), func() { target.ReleaseDate = source.ReleaseDate })
+	fill("imdb_id", target.ImdbID == "" && source.ImdbID != "", func() { target.ImdbID = source.ImdbID })
+	fill("tmdb_id", target.TmdbID == "" && source.TmdbID != "", func() { target.TmdbID = source.TmdbID })
+	fill("external_id", target.ExternalID == "" && source.ExternalID != "", func() { target.ExternalID = source.ExternalID })
+	fill("runtime_minutes", target.RuntimeMinutes == 0 && source.RuntimeMinutes != 0, func() { target.RuntimeMinutes = source.RuntimeMinutes })
+	fill("poster_url", target.PosterURL == "" && source.PosterURL != "", func() {
+		target.PosterURL = source.PosterURL
+		target.PosterVariants = source.PosterVariants
+		target.PosterBlurhash = source.PosterBlurhash
+		target.PosterColor = source.PosterColor
+		columns = append(columns, "poster_variants", "poster_blurhash", "poster_color")
+	})
+	return columns
+}
+
+// normalizeTitle simplifica un título para compararlo: sin acentos, signos ni artículos iniciales
+func normalizeTitle(title string) string {
+	words := strings.Fields(models.FoldText(title))
+	for len(words) > 1 {
+		switch words[0] {
+		case "the", "a", "an", "el", "la", "los", "las", "un", "una":
+			words = words[1:]
+			continue
+		}
+		break
+	}
+	return strings.Join(words, " ")
+}
+
+// titleSimilarity compara dos títulos normalizados (0-1) con la distancia de edición y las palabras en común
+func titleSimilarity(a, b string) float64 {
+	if a == "" || b == "" {
+		return 0
+	}
+	if a == b {
+		return 1
+	}
+
+	ra, rb := []rune(a), []rune(b)
+	longest := len(ra)
+	if len(rb) > longest {
+		longest = len(rb)
+	}
+	edit := 1 - float64(levenshtein(ra, rb))/float64(longest)
+
+	// Palabras en común: "Amelie" y "Amelie (2001)" comparten todas las palabras del título más corto
+	wordsA, wordsB := strings.Fields(a), strings.Fields(b)
+	set := make(map[string]bool, len(wordsA))
+	for _, word := range wordsA {
+		set[word] = true
+	}
+	common := 0
+	for _, word := range wordsB {
+		if set[word] {
+			common++
+			delete(set, word)
+		}
+	}
+	shortest := len(wordsA)
+	if len(wordsB) < shortest {
+		shortest = len(wordsB)
+	}
+	overlap := float64(common) / float64(shortest)
+	// La coincidencia de palabras se penaliza un poco para no igualar títulos de una sola palabra común
+	overlap *= 0.9
+
+	return math.Max(edit, overlap)
+}
+
+// levenshtein calcula la distancia de edición entre dos cadenas
+func levenshtein(a, b []rune) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
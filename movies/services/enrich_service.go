@@ -0,0 +1,344 @@
+package services
+
+import (
+	"cine_conecta_backend/auth/utils"
+	"cine_conecta_backend/config"
+	"cine_conecta_backend/movies/metadata"
+	"cine_conecta_backend/movies/models"
+	"cine_conecta_backend/movies/people"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrEnrichMovieNotFound indica que la película a enriquecer no existe
+var ErrEnrichMovieNotFound = errors.New("película no encontrada")
+
+// ErrMetadataProvider envuelve los errores devueltos por el proveedor de metadatos externo
+var ErrMetadataProvider = errors.New("error al consultar el proveedor de metadatos")
+
+// Campos que pueden completarse desde el proveedor de metadatos
+var enrichableFields = []string{"imdb_id", "tmdb_id", "description", "release_date", "runtime_minutes", "poster_url", "director", "genres", "cast"}
+
+// FieldChange describe la diferencia entre el valor actual y el propuesto por el proveedor
+type FieldChange struct {
+	Field    string      `json:"field"`
+	Current  interface{} `json:"current"`
+	Proposed interface{} `json:"proposed"`
+}
+
+// EnrichmentPreview contiene los metadatos obtenidos y los cambios que se aplicarían
+type EnrichmentPreview struct {
+	MovieID  uint                    `json:"movie_id"`
+	Provider string                  `json:"provider"`
+	Metadata *metadata.MovieMetadata `json:"metadata"`
+	Changes  []FieldChange           `json:"changes"`
+}
+
+// PreviewEnrichment consulta el proveedor de metadatos y calcula los cambios propuestos para una película
+func PreviewEnrichment(movieID uint, query metadata.Query) (*EnrichmentPreview, error) {
+	var movie models.Movie
+	if err := config.DB.Preload("Genres").Preload("Credits.Person").First(&movie, movieID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrEnrichMovieNotFound
+		}
+		return nil, err
+	}
+
+	provider, err := metadata.NewProviderFromEnv()
+	if err != nil {
+		return nil, err
+	}
+
+	// Completar la consulta con los datos de la película cuando no se indican
+	if query.ImdbID == "" && query.TmdbID == "" {
+		query.ImdbID = movie.ImdbID
+		query.TmdbID = movie.TmdbID
+	}
+	if query.Title == "" {
+		query.Title = movie.Title
+	}
+	if query.Year == 0 && !movie.ReleaseDate.IsZero() {
+		query.Year = movie.ReleaseDate.Year()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	meta, err := provider.Lookup(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrMetadataProvider, err)
+	}
+
+	changes, err := diffMovieMetadata(movie, meta)
+	if err != nil {
+		return nil, err
+	}
+
+	return &EnrichmentPreview{
+		MovieID:  movie.ID,
+		Provider: provider.Name(),
+		Metadata: meta,
+		Changes:  changes,
+	}, nil
+}
+
+// ApplyEnrichment aplica los cambios propuestos por el proveedor.
+// Si fields está vacío se aplican todos los cambios detectados.
+func ApplyEnrichment(movieID uint, query metadata.Query, fields []string, userID uint) (*EnrichmentPreview, error) {
+	preview, err := PreviewEnrichment(movieID, query)
+	if err != nil {
+		return nil, err
+	}
+
+	selected := make(map[string]bool)
+	for _, field := range fields {
+		selected[strings.ToLower(strings.TrimSpace(field))] = true
+	}
+
+	var applied []FieldChange
+	for _, change := range preview.Changes {
+		if len(selected) == 0 || selected[change.Field] {
+			applied = append(applied, change)
+		}
+	}
+	if len(applied) == 0 {
+		preview.Changes = []FieldChange{}
+		return preview, nil
+	}
+
+	var movie models.Movie
+	if err := config.DB.Preload("Genres").First(&movie, movieID).Error; err != nil {
+		return nil, err
+	}
+
+	meta := preview.Metadata
+	genreNames := movieGenreNames(movie)
+	applyCast := false
+
+	for _, change := range applied {
+		switch change.Field {
+		case "imdb_id":
+			movie.ImdbID = meta.ImdbID
+		case "tmdb_id":
+			movie.TmdbID = meta.TmdbID
+		case "description":
+			movie.Description = meta.Description
+		case "release_date":
+			if date, err := utils.ParseDate(meta.ReleaseDate); err == nil {
+				movie.ReleaseDate = date
+			}
+		case "runtime_minutes":
+			movie.RuntimeMinutes = meta.RuntimeMinutes
+		case "poster_url":
+			movie.SetExternalPoster(meta.PosterURL)
+		case "director":
+			movie.Director = strings.Join(metadataNamesByRole(meta, models.RoleDirector), ", ")
+		case "genres":
+			mapped, err := mapGenreNames(meta.Genres)
+			if err != nil {
+				return nil, err
+			}
+			genreNames = mergeNames(genreNames, mapped)
+		case "cast":
+			applyCast = true
+		}
+	}
+
+	// Los datos de la película y los créditos nuevos se guardan juntos y quedan en una sola revisión
+	tx := config.DB.Begin()
+
+	before, err := snapshotMovie(tx, movie.ID)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	movie.Genres = nil
+	if err := saveMovieWithGenres(tx, &movie, genreNames); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if applyCast {
+		if err := addMetadataCredits(tx, movie.ID, meta.Cast); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	if err := recordRevision(tx, movie.ID, RevisionInfo{UserID: userID, Source: models.RevisionSourceEnrich}, &before); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+
+	fmt.Printf("[DEBUG-ENRICH] Película %d enriquecida desde %s con %d cambios\n", movieID, preview.Provider, len(applied))
+
+	preview.Changes = applied
+	return preview, nil
+}
+
+// diffMovieMetadata compara la película con los metadatos y devuelve los campos que cambiarían
+func diffMovieMetadata(movie models.Movie, meta *metadata.MovieMetadata) ([]FieldChange, error) {
+	changes := []FieldChange{}
+	add := func(field string, current, proposed interface{}) {
+		changes = append(changes, FieldChange{Field: field, Current: current, Proposed: proposed})
+	}
+
+	if meta.ImdbID != "" && meta.ImdbID != movie.ImdbID {
+		add("imdb_id", movie.ImdbID, meta.ImdbID)
+	}
+	if meta.TmdbID != "" && meta.TmdbID != movie.TmdbID {
+		add("tmdb_id", movie.TmdbID, meta.TmdbID)
+	}
+	if meta.Description != "" && meta.Description != movie.Description {
+		add("description", movie.Description, meta.Description)
+	}
+	if date, err := utils.ParseDate(meta.ReleaseDate); err == nil && !date.Equal(movie.ReleaseDate) {
+		current := ""
+		if !movie.ReleaseDate.IsZero() {
+			current = movie.ReleaseDate.Format("2006-01-02")
+		}
+		add("release_date", current, meta.ReleaseDate)
+	}
+	if meta.RuntimeMinutes > 0 && meta.RuntimeMinutes != movie.RuntimeMinutes {
+		add("runtime_minutes", movie.RuntimeMinutes, meta.RuntimeMinutes)
+	}
+	if meta.PosterURL != "" && meta.PosterURL != movie.PosterURL {
+		add("poster_url", movie.PosterURL, meta.PosterURL)
+	}
+
+	if directors := metadataNamesByRole(meta, models.RoleDirector); len(directors) > 0 {
+		proposed := strings.Join(directors, ", ")
+		if !strings.EqualFold(proposed, movie.Director) {
+			add("director", movie.Director, proposed)
+		}
+	}
+
+	mapped, err := mapGenreNames(meta.Genres)
+	if err != nil {
+		return nil, err
+	}
+	currentGenres := movieGenreNames(movie)
+	if merged := mergeNames(currentGenres, mapped); len(merged) != len(currentGenres) {
+		add("genres", currentGenres, merged)
+	}
+
+	// Créditos que aún no existen en la película
+	existingCredits := make(map[string]bool)
+	for _, credit := range movie.Credits {
+		if credit.Person != nil {
+			existingCredits[creditKey(credit.Person.Name, string(credit.Role), credit.Character)] = true
+		}
+	}
+	var missing []metadata.CastMember
+	for _, member := range meta.Cast {
+		if member.Role == string(models.RoleDirector) {
+			continue // Los directores se gestionan con el campo director
+		}
+		if !existingCredits[creditKey(member.Name, member.Role, member.Character)] {
+			missing = append(missing, member)
+		}
+	}
+	if len(missing) > 0 {
+		add("cast", len(movie.Credits), missing)
+	}
+
+	return changes, nil
+}
+
+// addMetadataCredits registra en la película los créditos obtenidos del proveedor
+func addMetadataCredits(tx *gorm.DB, movieID uint, cast []metadata.CastMember) error {
+	for _, member := range cast {
+		role := models.CreditRole(member.Role)
+		if !models.IsValidCreditRole(role) || role == models.RoleDirector {
+			continue
+		}
+
+		person, err := people.FindOrCreate(tx, member.Name)
+		if err != nil {
+			return err
+		}
+
+		character := ""
+		if role == models.RoleActor {
+			character = member.Character
+		}
+
+		credit := models.MovieCredit{
+			MovieID:      movieID,
+			PersonID:     person.ID,
+			Role:         role,
+			Character:    character,
+			BillingOrder: member.Order,
+		}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&credit).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// metadataNamesByRole devuelve los nombres del reparto con un rol concreto, en orden
+func metadataNamesByRole(meta *metadata.MovieMetadata, role models.CreditRole) []string {
+	var names []string
+	for _, member := range meta.Cast {
+		if member.Role == string(role) {
+			names = append(names, member.Name)
+		}
+	}
+	return people.SplitNames(strings.Join(names, ","))
+}
+
+// movieGenreNames obtiene los nombres de los géneros de una película
+func movieGenreNames(movie models.Movie) []string {
+	var names []string
+	for _, genre := range movie.Genres {
+		names = append(names, genre.Name)
+	}
+	if len(names) == 0 && movie.Genre != "" {
+		names = models.ParseGenresString(movie.Genre)
+	}
+	return names
+}
+
+// mergeNames une dos listas de nombres sin duplicados (sin distinguir mayúsculas)
+func mergeNames(current, extra []string) []string {
+	merged := append([]string{}, current...)
+	seen := make(map[string]bool)
+	for _, name := range current {
+		seen[strings.ToLower(name)] = true
+	}
+	for _, name := range extra {
+		if !seen[strings.ToLower(name)] {
+			seen[strings.ToLower(name)] = true
+			merged = append(merged, name)
+		}
+	}
+	return merged
+}
+
+// creditKey identifica un crédito por persona, rol y personaje
+func creditKey(name, role, character string) string {
+	return strings.ToLower(strings.TrimSpace(name)) + "|" + role + "|" + strings.ToLower(strings.TrimSpace(character))
+}
+
+// IsEnrichableField indica si un campo puede solicitarse en el enriquecimiento
+func IsEnrichableField(field string) bool {
+	for _, f := range enrichableFields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,211 @@
+package services
+
+import (
+	"cine_conecta_backend/config"
+	"cine_conecta_backend/movies/factorization"
+	movieModels "cine_conecta_backend/movies/models"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// InteractionRow es una celda de la matriz usuario×película: la suma de la preferencia por sus me gusta
+// y la puntuación de su comentario
+type InteractionRow struct {
+	UserID  uint    `json:"user_id"`
+	MovieID uint    `json:"movie_id"`
+	Value   float64 `json:"value"`
+}
+
+// FactorTrainingReport resume un entrenamiento del modelo de factores latentes
+type FactorTrainingReport struct {
+	ModelID      uint                 `json:"model_id"`
+	Config       factorization.Config `json:"config"`
+	Users        int                  `json:"users"`
+	Movies       int                  `json:"movies"`
+	Interactions int                  `json:"interactions"`
+	Loss         []float64            `json:"loss"` // Coste tras cada iteración
+	Duration     string               `json:"duration"`
+}
+
+// factorRecommendation es una película recomendada por el modelo de factores latentes
+type factorRecommendation struct {
+	MovieID uint
+	Score   float64
+}
+
+// LoadInteractionMatrix obtiene las preferencias de todos los usuarios por las películas activas,
+// con la misma escala que el filtrado colaborativo
+func LoadInteractionMatrix() ([]InteractionRow, error) {
+	var rows []InteractionRow
+	err := config.DB.Raw(preferencesQuery+`
+		GROUP BY p.user_id, p.movie_id
+		HAVING SUM(p.value) <> 0
+		ORDER BY p.user_id, p.movie_id`,
+		map[string]interface{}{
+			"like":    likePreference,
+			"neutral": neutralCommentScore,
+			"gap":     commentPreferenceGap,
+		}).Scan(&rows).Error
+	return rows, err
+}
+
+// WriteInteractionsCSV escribe la matriz de interacciones en formato CSV (user_id, movie_id, value)
+func WriteInteractionsCSV(w io.Writer, rows []InteractionRow) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write([]string{"user_id", "movie_id", "value"}); err != nil {
+		return err
+	}
+	for _, row := range rows {
+		record := []string{
+			strconv.FormatUint(uint64(row.UserID), 10),
+			strconv.FormatUint(uint64(row.MovieID), 10),
+			strconv.FormatFloat(row.Value, 'f', -1, 64),
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// TrainFactorModel entrena el modelo de factores latentes con la matriz de interacciones y guarda los
+// vectores de usuarios y películas, sustituyendo los del entrenamiento anterior
+func TrainFactorModel(cfg factorization.Config) (*FactorTrainingReport, error) {
+	start := time.Now()
+
+	rows, err := LoadInteractionMatrix()
+	if err != nil {
+		return nil, err
+	}
+
+	// Asignar una fila a cada usuario y una columna a cada película
+	userIndex := make(map[uint]int)
+	movieIndex := make(map[uint]int)
+	var userIDs, movieIDs []uint
+	interactions := make([]factorization.Interaction, len(rows))
+	for i, row := range rows {
+		if _, ok := userIndex[row.UserID]; !ok {
+			userIndex[row.UserID] = len(userIDs)
+			userIDs = append(userIDs, row.UserID)
+		}
+		if _, ok := movieIndex[row.MovieID]; !ok {
+			movieIndex[row.MovieID] = len(movieIDs)
+			movieIDs = append(movieIDs, row.MovieID)
+		}
+		interactions[i] = factorization.Interaction{User: userIndex[row.UserID], Item: movieIndex[row.MovieID], Value: row.Value}
+	}
+
+	model, err := factorization.Train(len(userIDs), len(movieIDs), interactions, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &FactorTrainingReport{
+		Config:       cfg,
+		Users:        len(userIDs),
+		Movies:       len(movieIDs),
+		Interactions: len(interactions),
+		Loss:         model.Loss,
+	}
+
+	record := movieModels.FactorModel{
+		Factors:        cfg.Factors,
+		Iterations:     cfg.Iterations,
+		Regularization: cfg.Regularization,
+		Alpha:          cfg.Alpha,
+		Users:          report.Users,
+		Movies:         report.Movies,
+		Interactions:   report.Interactions,
+		Loss:           model.Loss[len(model.Loss)-1],
+	}
+
+	tx := config.DB.Begin()
+	if err := tx.Create(&record).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	for _, table := range []string{"user_factors", "movie_factors"} {
+		if err := tx.Exec("DELETE FROM " + table).Error; err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	userFactors := make([]movieModels.UserFactor, len(userIDs))
+	for i, id := range userIDs {
+		userFactors[i] = movieModels.UserFactor{UserID: id, ModelID: record.ID, Vector: model.UserFactors[i]}
+	}
+	if err := tx.CreateInBatches(userFactors, 500).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	movieFactors := make([]movieModels.MovieFactor, len(movieIDs))
+	for i, id := range movieIDs {
+		movieFactors[i] = movieModels.MovieFactor{MovieID: id, ModelID: record.ID, Vector: model.ItemFactors[i]}
+	}
+	if err := tx.CreateInBatches(movieFactors, 500).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+
+	report.ModelID = record.ID
+	report.Duration = time.Since(start).Round(time.Millisecond).String()
+	fmt.Printf("[DEBUG-RECOMMENDATION] Modelo de factores %d entrenado: %d usuarios, %d películas, %d interacciones en %s\n",
+		record.ID, report.Users, report.Movies, report.Interactions, report.Duration)
+	return report, nil
+}
+
+// getFactorRecommendations puntúa las películas por el producto escalar de su vector con el del usuario.
+// Devuelve nil si el usuario no estaba en el último entrenamiento.
+func getFactorRecommendations(userID uint, limit int, excludeIDs []uint, restrictions ContentRestrictions) ([]factorRecommendation, error) {
+	var user movieModels.UserFactor
+	if err := config.DB.First(&user, "user_id = ?", userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	// Las películas que ya le gustaron al usuario tampoco se recomiendan
+	var candidates []movieModels.MovieFactor
+	err := config.DB.
+		Where("movie_id IN (?)", config.DB.Model(&movieModels.Movie{}).Select("movies.id").Scopes(ContentFilter(restrictions))).
+		Where("movie_id NOT IN ?", append([]uint{0}, excludeIDs...)).
+		Where("movie_id NOT IN (SELECT movie_id FROM movie_likes WHERE user_id = ? AND deleted_at IS NULL)", userID).
+		Find(&candidates).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var scored []factorRecommendation
+	for _, candidate := range candidates {
+		if len(candidate.Vector) != len(user.Vector) {
+			continue
+		}
+		if score := factorization.Dot(user.Vector, candidate.Vector); score > 0 {
+			scored = append(scored, factorRecommendation{MovieID: candidate.MovieID, Score: score})
+		}
+	}
+
+	sort.Slice(scored, func(i, j int) bool {
+		if scored[i].Score != scored[j].Score {
+			return scored[i].Score > scored[j].Score
+		}
+		return scored[i].MovieID < scored[j].MovieID
+	})
+	if len(scored) > limit {
+		scored = scored[:limit]
+	}
+	return scored, nil
+}
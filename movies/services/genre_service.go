@@ -58,7 +58,7 @@ func GetGenreForMovie(movieID uint) (string, error) {
 }
 
 // AddGenreToMovie asigna un género a una película
-func AddGenreToMovie(movieID uint, genreName string) error {
+func AddGenreToMovie(movieID uint, genreName string, userID uint) error {
 	var movie models.Movie
 
 	if err := config.DB.First(&movie, movieID).Error; err != nil {
@@ -68,11 +68,11 @@ func AddGenreToMovie(movieID uint, genreName string) error {
 	// Asignar el género a la película
 	movie.Genre = genreName
 
-	return config.DB.Save(&movie).Error
+	return saveMovieGenre(&movie, userID)
 }
 
 // RemoveGenreFromMovie elimina el género de una película
-func RemoveGenreFromMovie(movieID uint, genreName string) error {
+func RemoveGenreFromMovie(movieID uint, genreName string, userID uint) error {
 	var movie models.Movie
 
 	if err := config.DB.First(&movie, movieID).Error; err != nil {
@@ -82,7 +82,7 @@ func RemoveGenreFromMovie(movieID uint, genreName string) error {
 	// Verificar que el género actual sea el que queremos eliminar
 	if movie.Genre == genreName {
 		movie.Genre = ""
-		return config.DB.Save(&movie).Error
+		return saveMovieGenre(&movie, userID)
 	}
 
 	return nil
@@ -106,7 +106,7 @@ func GetMoviesByGenre(genreName string) ([]models.Movie, error) {
 }
 
 // UpdateMovieGenre actualiza el género de una película
-func UpdateMovieGenre(movieID uint, genreName string) error {
+func UpdateMovieGenre(movieID uint, genreName string, userID uint) error {
 	var movie models.Movie
 	if err := config.DB.First(&movie, movieID).Error; err != nil {
 		return err
@@ -114,7 +114,28 @@ func UpdateMovieGenre(movieID uint, genreName string) error {
 
 	// Actualizar el género
 	movie.Genre = genreName
-	return config.DB.Save(&movie).Error
+	return saveMovieGenre(&movie, userID)
+}
+
+// saveMovieGenre guarda el género en texto de una película y registra el cambio en el historial
+func saveMovieGenre(movie *models.Movie, userID uint) error {
+	tx := config.DB.Begin()
+
+	before, err := snapshotMovie(tx, movie.ID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := tx.Model(movie).Update("genre", movie.Genre).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := recordRevision(tx, movie.ID, RevisionInfo{UserID: userID, Source: models.RevisionSourceManual}, &before); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
 }
 
 // GetGenreStats obtiene estadísticas de un género
@@ -2,6 +2,7 @@ package services
 
 import (
 	"cine_conecta_backend/config"
+	"cine_conecta_backend/movies/counters"
 	"cine_conecta_backend/movies/models"
 	"errors"
 	"time"
@@ -26,25 +27,44 @@ func CreateLike(userID, movieID uint) error {
 	var deletedLike models.Like
 	resultDeleted := config.DB.Unscoped().Where("user_id = ? AND movie_id = ? AND deleted_at IS NOT NULL", userID, movieID).First(&deletedLike)
 
-	// Si existe un registro eliminado, lo restauramos actualizando sus timestamps
+	tx := config.DB.Begin()
+
 	if resultDeleted.RowsAffected > 0 {
-		config.DB.Unscoped().Model(&deletedLike).Updates(map[string]interface{}{
+		// Si existe un registro eliminado, lo restauramos actualizando sus timestamps
+		// (solo si sigue eliminado, para no contarlo dos veces en peticiones simultáneas)
+		result := tx.Unscoped().Model(&deletedLike).Where("deleted_at IS NOT NULL").Updates(map[string]interface{}{
 			"deleted_at": nil,
 			"updated_at": time.Now(),
 		})
-		return nil
+		if result.Error != nil {
+			tx.Rollback()
+			return errors.New("error al dar me gusta a la película")
+		}
+		if result.RowsAffected == 0 {
+			tx.Rollback()
+			return errors.New("ya has dado me gusta a esta película")
+		}
+	} else {
+		// Crear el nuevo like
+		like := models.Like{
+			UserID:  userID,
+			MovieID: movieID,
+		}
+		if err := tx.Create(&like).Error; err != nil {
+			tx.Rollback()
+			return errors.New("error al dar me gusta a la película")
+		}
 	}
 
-	// Crear el nuevo like
-	like := models.Like{
-		UserID:  userID,
-		MovieID: movieID,
+	// Sumar el me gusta al contador de la película en la misma transacción
+	if err := counters.LikeAdded(tx, movieID); err != nil {
+		tx.Rollback()
+		return errors.New("error al dar me gusta a la película")
 	}
 
-	if err := config.DB.Create(&like).Error; err != nil {
+	if err := tx.Commit().Error; err != nil {
 		return errors.New("error al dar me gusta a la película")
 	}
-
 	return nil
 }
 
@@ -57,11 +77,25 @@ func DeleteLike(userID, movieID uint) error {
 		return errors.New("no has dado me gusta a esta película")
 	}
 
-	// Realizar soft delete
-	if err := config.DB.Delete(&like).Error; err != nil {
+	// Realizar soft delete y descontarlo del contador de la película
+	tx := config.DB.Begin()
+	result = tx.Delete(&like)
+	if result.Error != nil {
+		tx.Rollback()
+		return errors.New("error al quitar me gusta de la película")
+	}
+	if result.RowsAffected == 0 {
+		tx.Rollback()
+		return errors.New("no has dado me gusta a esta película")
+	}
+	if err := counters.LikeRemoved(tx, movieID); err != nil {
+		tx.Rollback()
 		return errors.New("error al quitar me gusta de la película")
 	}
 
+	if err := tx.Commit().Error; err != nil {
+		return errors.New("error al quitar me gusta de la película")
+	}
 	return nil
 }
 
@@ -91,10 +125,10 @@ func GetLikesByUser(userID uint) ([]models.Movie, error) {
 	return movies, nil
 }
 
-// GetLikesByMovie obtiene todos los usuarios que han dado me gusta a una película
+// GetLikesByMovie obtiene el número de me gusta de una película a partir de su contador
 func GetLikesByMovie(movieID uint) (int64, error) {
 	var count int64
-	err := config.DB.Model(&models.Like{}).Where("movie_id = ?", movieID).Count(&count).Error
+	err := config.DB.Model(&models.Movie{}).Select("like_count").Where("id = ?", movieID).Scan(&count).Error
 	if err != nil {
 		return 0, errors.New("error al obtener el conteo de me gusta")
 	}
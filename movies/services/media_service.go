@@ -0,0 +1,326 @@
+package services
+
+import (
+	"bytes"
+	"cine_conecta_backend/config"
+	"cine_conecta_backend/movies/imaging"
+	"cine_conecta_backend/movies/models"
+	"cine_conecta_backend/storage"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Tamaños máximos de los archivos multimedia subidos
+const (
+	maxMediaImageSize = 20 << 20  // 20 MB
+	maxMediaVideoSize = 200 << 20 // 200 MB
+)
+
+// Prefijo de las claves de la galería en el almacenamiento
+const mediaKeyPrefix = "media/"
+
+var (
+	youtubeIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{11}$`)
+	vimeoIDPattern   = regexp.MustCompile(`^[0-9]+$`)
+)
+
+// GetMovieMedia devuelve el contenido multimedia de una película, opcionalmente filtrado por tipo
+func GetMovieMedia(movieID uint, kind string) ([]models.MovieMedia, error) {
+	var media []models.MovieMedia
+	query := config.DB.Where("movie_id = ?", movieID)
+	if kind != "" {
+		query = query.Where("kind = ?", kind)
+	}
+	err := query.Order("kind, sort_order, id").Find(&media).Error
+	return media, err
+}
+
+// AddMovieMediaLink registra un tráiler de YouTube/Vimeo o un enlace externo en la película
+func AddMovieMediaLink(movieID uint, media *models.MovieMedia) error {
+	if err := config.DB.First(&models.Movie{}, movieID).Error; err != nil {
+		return errors.New("película no encontrada")
+	}
+	if !models.IsValidMediaKind(media.Kind) {
+		return errors.New("tipo de contenido no válido (trailer, backdrop o still)")
+	}
+
+	switch media.Provider {
+	case models.MediaProviderYouTube, models.MediaProviderVimeo:
+		if media.Kind != models.MediaTrailer {
+			return errors.New("solo los tráileres pueden incrustarse desde YouTube o Vimeo")
+		}
+		value := media.ExternalID
+		if value == "" {
+			value = media.URL
+		}
+		videoID, err := parseVideoID(media.Provider, value)
+		if err != nil {
+			return err
+		}
+		media.ExternalID = videoID
+		media.URL = embedURL(media.Provider, videoID)
+	case models.MediaProviderURL:
+		if !strings.HasPrefix(media.URL, "http://") && !strings.HasPrefix(media.URL, "https://") {
+			return errors.New("la URL debe comenzar por http:// o https://")
+		}
+		media.ExternalID = ""
+	default:
+		return errors.New("proveedor no válido (youtube, vimeo o url)")
+	}
+
+	media.ID = 0
+	media.MovieID = movieID
+	media.Variants = nil
+	if media.SortOrder == 0 {
+		media.SortOrder = nextMediaSortOrder(movieID, media.Kind)
+	}
+
+	return config.DB.Create(media).Error
+}
+
+// UploadMovieMedia sube una imagen (fondo o fotograma) o un vídeo (tráiler) al almacenamiento
+func UploadMovieMedia(movieID uint, kind models.MediaKind, fileHeader *multipart.FileHeader, caption string, sortOrder int) (*models.MovieMedia, error) {
+	if err := config.DB.First(&models.Movie{}, movieID).Error; err != nil {
+		return nil, errors.New("película no encontrada")
+	}
+	if !models.IsValidMediaKind(kind) {
+		return nil, errors.New("tipo de contenido no válido (trailer, backdrop o still)")
+	}
+
+	mime := fileHeader.Header.Get("Content-Type")
+	if kind == models.MediaTrailer {
+		if mime != "video/mp4" && mime != "video/webm" {
+			return nil, errors.New("formato de vídeo no permitido (solo MP4 o WEBM)")
+		}
+		if fileHeader.Size > maxMediaVideoSize {
+			return nil, errors.New("el vídeo supera los 200 MB")
+		}
+	} else {
+		if !isAllowedPosterMime(mime) {
+			return nil, errors.New("formato no permitido (solo JPEG, PNG o WEBP)")
+		}
+		if fileHeader.Size > maxMediaImageSize {
+			return nil, errors.New("la imagen supera los 20 MB")
+		}
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return nil, fmt.Errorf("error al abrir el archivo: %w", err)
+	}
+	defer file.Close()
+
+	suffix, err := randomKeySuffix()
+	if err != nil {
+		return nil, err
+	}
+	keyBase := fmt.Sprintf("%s%d/%d-%s", mediaKeyPrefix, movieID, time.Now().Unix(), suffix)
+
+	media := &models.MovieMedia{
+		MovieID:   movieID,
+		Kind:      kind,
+		Provider:  models.MediaProviderUpload,
+		Caption:   caption,
+		SortOrder: sortOrder,
+	}
+
+	if kind == models.MediaTrailer {
+		// Comprobar por el contenido que el vídeo es del tipo declarado
+		head := make([]byte, 512)
+		n, err := io.ReadFull(file, head)
+		if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, fmt.Errorf("error al leer el archivo: %w", err)
+		}
+		if http.DetectContentType(head[:n]) != mime {
+			return nil, errors.New("el contenido del archivo no coincide con el tipo declarado")
+		}
+		if _, err := file.Seek(0, io.SeekStart); err != nil {
+			return nil, fmt.Errorf("error al leer el archivo: %w", err)
+		}
+
+		ext := ".mp4"
+		if mime == "video/webm" {
+			ext = ".webm"
+		}
+		url, err := storage.PutLarge(keyBase+ext, file, mime, fileHeader.Size)
+		if err != nil {
+			return nil, fmt.Errorf("error al subir vídeo: %w", err)
+		}
+		media.URL = url
+	} else {
+		// Las imágenes se validan y se convierten a variantes WebP como los pósters
+		processed, err := imaging.ProcessImage(file, imaging.BackdropWidths)
+		if err != nil {
+			return nil, err
+		}
+		if imaging.MimeForFormat(processed.Format) != mime {
+			return nil, errors.New("el contenido del archivo no coincide con el tipo declarado")
+		}
+
+		media.Variants = make(map[string]string)
+		for _, variant := range processed.Variants {
+			url, err := storage.Put(fmt.Sprintf("%s-%s.webp", keyBase, variant.Name), bytes.NewReader(variant.Data), "image/webp")
+			if err != nil {
+				deleteReplacedObjects(mediaURLs(*media), nil)
+				return nil, fmt.Errorf("error al subir imagen: %w", err)
+			}
+			media.Variants[variant.Name] = url
+		}
+		media.URL = media.Variants["original"]
+		media.Blurhash = processed.Blurhash
+		media.Width = processed.Width
+		media.Height = processed.Height
+	}
+
+	if media.SortOrder == 0 {
+		media.SortOrder = nextMediaSortOrder(movieID, kind)
+	}
+
+	if err := config.DB.Create(media).Error; err != nil {
+		deleteReplacedObjects(mediaURLs(*media), nil)
+		return nil, fmt.Errorf("error al guardar en BD: %w", err)
+	}
+
+	fmt.Printf("[DEBUG-MEDIA] Película %d: %s subido (%s)\n", movieID, kind, media.URL)
+	return media, nil
+}
+
+// UpdateMovieMedia modifica el título y la posición de un contenido multimedia
+func UpdateMovieMedia(movieID, mediaID uint, caption *string, sortOrder *int) (*models.MovieMedia, error) {
+	var media models.MovieMedia
+	if err := config.DB.Where("id = ? AND movie_id = ?", mediaID, movieID).First(&media).Error; err != nil {
+		return nil, err
+	}
+
+	updates := make(map[string]interface{})
+	if caption != nil {
+		updates["caption"] = *caption
+	}
+	if sortOrder != nil {
+		updates["sort_order"] = *sortOrder
+	}
+	if len(updates) > 0 {
+		if err := config.DB.Model(&media).Updates(updates).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	return &media, nil
+}
+
+// ReorderMovieMedia asigna las posiciones según el orden de los identificadores recibidos
+func ReorderMovieMedia(movieID uint, mediaIDs []uint) error {
+	tx := config.DB.Begin()
+
+	for i, mediaID := range mediaIDs {
+		result := tx.Model(&models.MovieMedia{}).
+			Where("id = ? AND movie_id = ?", mediaID, movieID).
+			Update("sort_order", i+1)
+		if result.Error != nil {
+			tx.Rollback()
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			tx.Rollback()
+			return fmt.Errorf("el contenido %d no pertenece a la película", mediaID)
+		}
+	}
+
+	return tx.Commit().Error
+}
+
+// DeleteMovieMedia elimina un contenido multimedia y sus archivos del almacenamiento
+func DeleteMovieMedia(movieID, mediaID uint) error {
+	var media models.MovieMedia
+	if err := config.DB.Where("id = ? AND movie_id = ?", mediaID, movieID).First(&media).Error; err != nil {
+		return err
+	}
+
+	if err := config.DB.Delete(&media).Error; err != nil {
+		return err
+	}
+
+	deleteReplacedObjects(mediaURLs(media), nil)
+	return nil
+}
+
+// mediaURLs devuelve las URLs de los archivos subidos de un contenido (los enlaces externos no se incluyen)
+func mediaURLs(media models.MovieMedia) []string {
+	if media.Provider != models.MediaProviderUpload {
+		return nil
+	}
+
+	var urls []string
+	if media.URL != "" {
+		urls = append(urls, media.URL)
+	}
+	for _, url := range media.Variants {
+		if url != media.URL {
+			urls = append(urls, url)
+		}
+	}
+	return urls
+}
+
+// nextMediaSortOrder devuelve la siguiente posición libre para un tipo de contenido
+func nextMediaSortOrder(movieID uint, kind models.MediaKind) int {
+	var maxOrder int
+	config.DB.Model(&models.MovieMedia{}).
+		Where("movie_id = ? AND kind = ?", movieID, kind).
+		Select("COALESCE(MAX(sort_order), 0)").
+		Scan(&maxOrder)
+	return maxOrder + 1
+}
+
+// parseVideoID extrae el identificador del vídeo de una URL de YouTube/Vimeo o lo valida si ya es un ID
+func parseVideoID(provider, value string) (string, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return "", errors.New("se requiere la URL o el identificador del vídeo")
+	}
+
+	candidate := value
+	if parsed, err := url.Parse(value); err == nil && parsed.Host != "" {
+		host := strings.TrimPrefix(strings.ToLower(parsed.Host), "www.")
+		segments := strings.Split(strings.Trim(parsed.Path, "/"), "/")
+
+		switch {
+		case provider == models.MediaProviderYouTube && host == "youtu.be":
+			candidate = segments[0]
+		case provider == models.MediaProviderYouTube && strings.HasSuffix(host, "youtube.com"):
+			if v := parsed.Query().Get("v"); v != "" {
+				candidate = v
+			} else if len(segments) >= 2 && (segments[0] == "embed" || segments[0] == "shorts") {
+				candidate = segments[1]
+			}
+		case provider == models.MediaProviderVimeo && strings.HasSuffix(host, "vimeo.com"):
+			candidate = segments[len(segments)-1]
+		default:
+			return "", fmt.Errorf("la URL no corresponde a %s", provider)
+		}
+	}
+
+	pattern := youtubeIDPattern
+	if provider == models.MediaProviderVimeo {
+		pattern = vimeoIDPattern
+	}
+	if !pattern.MatchString(candidate) {
+		return "", fmt.Errorf("identificador de vídeo de %s no válido", provider)
+	}
+	return candidate, nil
+}
+
+// embedURL construye la URL para incrustar el vídeo en la web
+func embedURL(provider, videoID string) string {
+	if provider == models.MediaProviderVimeo {
+		return "https://player.vimeo.com/video/" + videoID
+	}
+	return "https://www.youtube.com/embed/" + videoID
+}
@@ -1,11 +1,11 @@
 package services
 
 import (
-	commentModels "cine_conecta_backend/comments/models"
 	"cine_conecta_backend/config"
 	"cine_conecta_backend/movies/models"
+	"cine_conecta_backend/movies/people"
+	"cine_conecta_backend/movies/slugs"
 	"errors"
-	"fmt"
 	"strings"
 
 	"gorm.io/gorm"
@@ -16,71 +16,24 @@ func CreateMovie(movie *models.Movie) error {
 	return config.DB.Create(movie).Error
 }
 
-// GetMovies obtiene todas las películas y actualiza sus ratings basados en los comentarios.
+// GetMovies obtiene todas las películas. La valoración se mantiene al escribir comentarios.
 func GetMovies() ([]models.Movie, error) {
 	var movies []models.Movie
 	if err := config.DB.Preload("Genres").Find(&movies).Error; err != nil {
 		return nil, err
 	}
 
-	// Actualizar los ratings de todas las películas basado en los comentarios
-	for i := range movies {
-		if err := updateMovieRating(&movies[i]); err != nil {
-			fmt.Printf("[DEBUG-SERVICE] Error al actualizar rating de película %d: %v\n", movies[i].ID, err)
-		}
-	}
-
 	return movies, nil
 }
 
-// updateMovieRating actualiza el rating de una película basado en los comentarios
-func updateMovieRating(movie *models.Movie) error {
-	// Obtener todos los comentarios de la película
-	var comments []commentModels.Comment
-	if err := config.DB.Where("movie_id = ?", movie.ID).Find(&comments).Error; err != nil {
-		return fmt.Errorf("error al obtener comentarios: %w", err)
-	}
-
-	// Calcular el nuevo rating basado en el promedio de las puntuaciones de sentimientos
-	var newRating float32
-	if len(comments) > 0 {
-		var totalScore float64
-		for _, comment := range comments {
-			totalScore += comment.SentimentScore
-		}
-		newRating = float32(totalScore / float64(len(comments)))
-	} else {
-		// Si no hay comentarios, dejar el rating en 0
-		newRating = 0
-	}
-
-	// Solo actualizar si el rating ha cambiado
-	if movie.Rating != newRating {
-		movie.Rating = newRating
-		// Guardar el nuevo rating en la base de datos
-		if err := config.DB.Model(&models.Movie{}).Where("id = ?", movie.ID).Update("rating", newRating).Error; err != nil {
-			return fmt.Errorf("error al actualizar rating: %w", err)
-		}
-	}
-
-	return nil
-}
-
 // GetRecentMovies obtiene las películas más recientes según su fecha de lanzamiento.
-func GetRecentMovies(limit int) ([]models.Movie, error) {
+func GetRecentMovies(limit int, restrictions ContentRestrictions) ([]models.Movie, error) {
 	var movies []models.Movie
-	err := config.DB.Order("release_date DESC").Limit(limit).Find(&movies).Error
+	err := config.DB.Scopes(ContentFilter(restrictions)).Order("release_date DESC").Limit(limit).Find(&movies).Error
 	if err != nil {
 		return nil, err
 	}
 
-	// Actualizar los ratings de las películas
-	for i := range movies {
-		if err := updateMovieRating(&movies[i]); err != nil {
-			fmt.Printf("[DEBUG-SERVICE] Error al actualizar rating de película %d: %v\n", movies[i].ID, err)
-		}
-	}
-
 	return movies, nil
 }
 
@@ -91,26 +44,50 @@ func GetMovieByID(id uint) (models.Movie, error) {
 		return movie, err
 	}
 
-	// Actualizar el rating de la película
-	if err := updateMovieRating(&movie); err != nil {
-		fmt.Printf("[DEBUG-SERVICE] Error al actualizar rating de película %d: %v\n", movie.ID, err)
-	}
-
 	return movie, nil
 }
 
 // UpdateMovie actualiza una película existente.
 func UpdateMovie(movie *models.Movie) error {
-	return config.DB.Save(movie).Error
+	return config.DB.Omit(models.MovieCounterColumns...).Save(movie).Error
 }
 
-// DeleteMovie elimina una película por su ID.
-func DeleteMovie(id uint) error {
-	return config.DB.Delete(&models.Movie{}, id).Error
+// DeleteMovie envía una película a la papelera. Sus comentarios, me gusta y demás registros
+// se ocultan, pero se conservan hasta que la película se elimina definitivamente.
+func DeleteMovie(id uint, userID uint) error {
+	if err := config.DB.Select("id").First(&models.Movie{}, id).Error; err != nil {
+		return err
+	}
+
+	// No se eliminan películas con entradas vendidas en alguna función
+	if err := checkMovieBookings(config.DB, id); err != nil {
+		return err
+	}
+
+	tx := config.DB.Begin()
+
+	before, err := snapshotMovie(tx, id)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Delete(&models.Movie{}, id).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	info := RevisionInfo{UserID: userID, Source: models.RevisionSourceManual}
+	if err := recordRevision(tx, id, info, &before); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
 }
 
 // CreateMovieWithGenres crea una película con su género asociado
-func CreateMovieWithGenres(movie *models.Movie, genreNames []string) error {
+func CreateMovieWithGenres(movie *models.Movie, genreNames []string, info RevisionInfo) error {
 	// Iniciar transacción
 	tx := config.DB.Begin()
 	defer func() {
@@ -154,12 +131,30 @@ func CreateMovieWithGenres(movie *models.Movie, genreNames []string) error {
 		}
 	}
 
+	// Sincronizar el director con la tabla de personas
+	if err := people.SyncDirectorCredits(tx, movie); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// Mantener el slug acorde al título y al año
+	if err := slugs.Sync(tx, movie); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// Registrar la creación en el historial de cambios
+	if err := recordRevision(tx, movie.ID, info, nil); err != nil {
+		tx.Rollback()
+		return err
+	}
+
 	// Commit de la transacción
 	return tx.Commit().Error
 }
 
 // UpdateMovieWithGenres actualiza una película y sus géneros
-func UpdateMovieWithGenres(movie *models.Movie, genreNames []string) error {
+func UpdateMovieWithGenres(movie *models.Movie, genreNames []string, info RevisionInfo) error {
 	// Iniciar transacción
 	tx := config.DB.Begin()
 	defer func() {
@@ -168,6 +163,31 @@ func UpdateMovieWithGenres(movie *models.Movie, genreNames []string) error {
 		}
 	}()
 
+	// Guardar el estado anterior para el historial de cambios
+	before, err := snapshotMovie(tx, movie.ID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := saveMovieWithGenres(tx, movie, genreNames); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// Registrar los cambios en el historial
+	if err := recordRevision(tx, movie.ID, info, &before); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// Commit de la transacción
+	return tx.Commit().Error
+}
+
+// saveMovieWithGenres guarda la película dentro de la transacción y reemplaza sus géneros,
+// su crédito de director y su slug. No registra la revisión: eso queda a cargo de quien llama.
+func saveMovieWithGenres(tx *gorm.DB, movie *models.Movie, genreNames []string) error {
 	// Si no hay géneros explícitos pero hay un género en el campo texto, usarlo
 	if len(genreNames) == 0 && movie.Genre != "" {
 		genreNames = models.ParseGenresString(movie.Genre)
@@ -181,38 +201,37 @@ func UpdateMovieWithGenres(movie *models.Movie, genreNames []string) error {
 	}
 
 	// Actualizar la película
-	if err := tx.Save(movie).Error; err != nil {
-		tx.Rollback()
+	if err := tx.Omit(models.MovieCounterColumns...).Save(movie).Error; err != nil {
 		return err
 	}
 
 	// Limpiar géneros existentes
 	if err := tx.Exec("DELETE FROM movie_genres WHERE movie_id = ?", movie.ID).Error; err != nil {
-		tx.Rollback()
 		return err
 	}
 
 	// Procesar géneros
-	if len(genreNames) > 0 {
-		for _, genreName := range genreNames {
-			// Buscar o crear género
-			genre, err := findOrCreateGenre(tx, genreName)
-			if err != nil {
-				tx.Rollback()
-				return err
-			}
+	for _, genreName := range genreNames {
+		// Buscar o crear género
+		genre, err := findOrCreateGenre(tx, genreName)
+		if err != nil {
+			return err
+		}
 
-			// Asociar género con película en la tabla movie_genres
-			if err := tx.Exec("INSERT INTO movie_genres (movie_id, genre_id) VALUES (?, ?) ON CONFLICT DO NOTHING",
-				movie.ID, genre.ID).Error; err != nil {
-				tx.Rollback()
-				return err
-			}
+		// Asociar género con película en la tabla movie_genres
+		if err := tx.Exec("INSERT INTO movie_genres (movie_id, genre_id) VALUES (?, ?) ON CONFLICT DO NOTHING",
+			movie.ID, genre.ID).Error; err != nil {
+			return err
 		}
 	}
 
-	// Commit de la transacción
-	return tx.Commit().Error
+	// Sincronizar el director con la tabla de personas
+	if err := people.SyncDirectorCredits(tx, movie); err != nil {
+		return err
+	}
+
+	// Mantener el slug acorde al título y al año
+	return slugs.Sync(tx, movie)
 }
 
 // findOrCreateGenre busca un género por nombre o lo crea si no existe
@@ -240,50 +259,52 @@ func findOrCreateGenre(tx *gorm.DB, name string) (*models.Genre, error) {
 	return &genre, nil
 }
 
+// allowedSortFields son las columnas por las que se pueden ordenar las películas.
+// rating ordena por la valoración ponderada y avg_score por la media simple de los comentarios.
 var allowedSortFields = map[string]bool{
-	"title":  true,
-	"rating": true,
+	"title":     true,
+	"rating":    true,
+	"avg_score": true,
 }
 
-func GetMoviesSorted(sortBy string, order string) ([]models.Movie, error) {
-	var movies []models.Movie
-
+// MovieSortClause valida la columna y el orden y devuelve la expresión ORDER BY correspondiente.
+// rating se ordena por la valoración ponderada con la media a priori indicada.
+func MovieSortClause(sortBy string, order string, prior RatingPrior) (string, error) {
 	// Validar columna
 	if !allowedSortFields[sortBy] {
-		return nil, errors.New("columna inválida para ordenar")
+		return "", errors.New("columna inválida para ordenar")
 	}
 
 	// Validar orden
 	if order != "asc" && order != "desc" {
-		return nil, errors.New("orden inválido")
+		return "", errors.New("orden inválido")
 	}
 
-	sortQuery := sortBy + " " + order
+	if sortBy == "rating" {
+		return prior.OrderExpr() + " " + order, nil
+	}
+	return sortBy + " " + order, nil
+}
 
-	result := config.DB.Order(sortQuery).Find(&movies)
+func GetMoviesSorted(sortBy string, order string, restrictions ContentRestrictions) ([]models.Movie, error) {
+	var movies []models.Movie
 
-	if result.Error != nil {
-		return nil, result.Error
+	prior, err := LoadRatingPrior()
+	if err != nil {
+		return nil, err
 	}
 
-	// Actualizar los ratings de las películas
-	for i := range movies {
-		if err := updateMovieRating(&movies[i]); err != nil {
-			fmt.Printf("[DEBUG-SERVICE] Error al actualizar rating de película %d: %v\n", movies[i].ID, err)
-		}
+	sortQuery, err := MovieSortClause(sortBy, order, prior)
+	if err != nil {
+		return nil, err
 	}
 
-	return movies, nil
-}
+	result := config.DB.Scopes(ContentFilter(restrictions)).Order(sortQuery).Order("id").Find(&movies)
 
-// UpdateMovieRating actualiza el rating de una película basado en los comentarios
-// Esta función puede ser llamada desde otros paquetes para actualizar el rating
-func UpdateMovieRating(movieID uint) error {
-	// Obtener la película primero
-	var movie models.Movie
-	if err := config.DB.First(&movie, movieID).Error; err != nil {
-		return err
+	if result.Error != nil {
+		return nil, result.Error
 	}
 
-	return updateMovieRating(&movie)
+	prior.ApplyWeightedRatings(movies)
+	return movies, nil
 }
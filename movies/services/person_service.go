@@ -0,0 +1,264 @@
+package services
+
+import (
+	"cine_conecta_backend/config"
+	"cine_conecta_backend/movies/models"
+	"cine_conecta_backend/movies/people"
+	"errors"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// Errores de validación al añadir un crédito a una película
+var (
+	ErrInvalidCreditRole    = errors.New("rol inválido")
+	ErrCreditMovieNotFound  = errors.New("película no encontrada")
+	ErrCreditPersonNotFound = errors.New("persona no encontrada")
+	ErrDuplicateCredit      = errors.New("la persona ya tiene este rol en la película")
+)
+
+// CreatePerson guarda una nueva persona en la base de datos
+func CreatePerson(person *models.Person) error {
+	person.Name = strings.TrimSpace(person.Name)
+	if person.Name == "" {
+		return errors.New("el nombre de la persona es obligatorio")
+	}
+	return config.DB.Create(person).Error
+}
+
+// GetPeople obtiene las personas registradas, opcionalmente filtradas por nombre
+func GetPeople(name string) ([]models.Person, error) {
+	var people []models.Person
+	query := config.DB.Order("name ASC")
+
+	if name != "" {
+		query = query.Where("LOWER(name) LIKE LOWER(?)", "%"+name+"%")
+	}
+
+	if err := query.Find(&people).Error; err != nil {
+		return nil, err
+	}
+
+	return people, nil
+}
+
+// GetPersonByID obtiene una persona por su ID
+func GetPersonByID(id uint) (models.Person, error) {
+	var person models.Person
+	err := config.DB.First(&person, id).Error
+	return person, err
+}
+
+// GetPersonWithFilmography obtiene una persona junto con las películas aptas para el usuario
+// en las que ha participado
+func GetPersonWithFilmography(id uint, restrictions ContentRestrictions) (models.Person, error) {
+	var person models.Person
+	err := config.DB.
+		Preload("Credits", func(db *gorm.DB) *gorm.DB {
+			return db.Select("movie_credits.*").
+				Joins("JOIN movies ON movies.id = movie_credits.movie_id AND movies.deleted_at IS NULL").
+				Scopes(ContentFilter(restrictions)).
+				Order("movies.release_date DESC, movie_credits.billing_order ASC")
+		}).
+		Preload("Credits.Movie").
+		First(&person, id).Error
+	return person, err
+}
+
+// UpdatePerson actualiza los datos de una persona existente
+func UpdatePerson(person *models.Person) error {
+	person.Name = strings.TrimSpace(person.Name)
+	if person.Name == "" {
+		return errors.New("el nombre de la persona es obligatorio")
+	}
+
+	tx := config.DB.Begin()
+	if err := tx.Save(person).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// Mantener sincronizado el campo de texto del director en las películas dirigidas
+	var movieIDs []uint
+	if err := tx.Model(&models.MovieCredit{}).
+		Where("person_id = ? AND role = ?", person.ID, models.RoleDirector).
+		Pluck("movie_id", &movieIDs).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	for _, movieID := range movieIDs {
+		if err := refreshDirectorField(tx, movieID); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit().Error
+}
+
+// DeletePerson elimina una persona y todos sus créditos
+func DeletePerson(id uint) error {
+	tx := config.DB.Begin()
+
+	var movieIDs []uint
+	if err := tx.Model(&models.MovieCredit{}).
+		Where("person_id = ? AND role = ?", id, models.RoleDirector).
+		Pluck("movie_id", &movieIDs).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Where("person_id = ?", id).Delete(&models.MovieCredit{}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	result := tx.Delete(&models.Person{}, id)
+	if result.Error != nil {
+		tx.Rollback()
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		tx.Rollback()
+		return gorm.ErrRecordNotFound
+	}
+
+	for _, movieID := range movieIDs {
+		if err := refreshDirectorField(tx, movieID); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit().Error
+}
+
+// GetMovieCredits obtiene el reparto y equipo técnico de una película
+func GetMovieCredits(movieID uint) ([]models.MovieCredit, error) {
+	var credits []models.MovieCredit
+	err := config.DB.Where("movie_id = ?", movieID).
+		Preload("Person").
+		Order("role ASC, billing_order ASC").
+		Find(&credits).Error
+	return credits, err
+}
+
+// AddCreditToMovie asocia una persona a una película con un rol determinado
+func AddCreditToMovie(credit *models.MovieCredit) error {
+	if !models.IsValidCreditRole(credit.Role) {
+		return fmt.Errorf("%w: %s", ErrInvalidCreditRole, credit.Role)
+	}
+	if credit.Role != models.RoleActor {
+		credit.Character = ""
+	}
+
+	var movie models.Movie
+	if err := config.DB.First(&movie, credit.MovieID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrCreditMovieNotFound
+		}
+		return err
+	}
+
+	var person models.Person
+	if err := config.DB.First(&person, credit.PersonID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrCreditPersonNotFound
+		}
+		return err
+	}
+
+	tx := config.DB.Begin()
+	if err := tx.Create(credit).Error; err != nil {
+		tx.Rollback()
+		if isUniqueViolation(err) {
+			return ErrDuplicateCredit
+		}
+		return err
+	}
+
+	if credit.Role == models.RoleDirector {
+		if err := refreshDirectorField(tx, credit.MovieID); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return err
+	}
+
+	credit.Person = &person
+	return nil
+}
+
+// RemoveCreditFromMovie elimina un crédito de una película
+func RemoveCreditFromMovie(movieID, creditID uint) error {
+	var credit models.MovieCredit
+	if err := config.DB.Where("id = ? AND movie_id = ?", creditID, movieID).First(&credit).Error; err != nil {
+		return err
+	}
+
+	tx := config.DB.Begin()
+	if err := tx.Delete(&credit).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if credit.Role == models.RoleDirector {
+		if err := refreshDirectorField(tx, movieID); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit().Error
+}
+
+// GetMoviesByPerson obtiene las películas aptas para el usuario en las que participa una persona,
+// opcionalmente filtradas por rol
+func GetMoviesByPerson(personID uint, role string, restrictions ContentRestrictions) ([]models.Movie, error) {
+	var movies []models.Movie
+	query := config.DB.Preload("Genres").
+		Scopes(ContentFilter(restrictions)).
+		Where("id IN (?)", personMovieIDsSubquery(config.DB, personID, role))
+
+	if err := query.Order("release_date DESC").Find(&movies).Error; err != nil {
+		return nil, err
+	}
+
+	return movies, nil
+}
+
+// personMovieIDsSubquery construye la subconsulta de IDs de películas de una persona
+func personMovieIDsSubquery(db *gorm.DB, personID uint, role string) *gorm.DB {
+	sub := db.Model(&models.MovieCredit{}).Select("movie_id").Where("person_id = ?", personID)
+	if role != "" {
+		sub = sub.Where("role = ?", role)
+	}
+	return sub
+}
+
+// MigrateDirectorsToPeople convierte el texto libre del campo director en personas con crédito de dirección.
+// Devuelve la cantidad de películas procesadas.
+func MigrateDirectorsToPeople() (int, error) {
+	return people.MigrateDirectors(config.DB)
+}
+
+// refreshDirectorField reconstruye el campo de texto director a partir de los créditos de dirección
+func refreshDirectorField(tx *gorm.DB, movieID uint) error {
+	var names []string
+	if err := tx.Table("movie_credits").
+		Select("people.name").
+		Joins("JOIN people ON people.id = movie_credits.person_id").
+		Where("movie_credits.movie_id = ? AND movie_credits.role = ?", movieID, models.RoleDirector).
+		Order("movie_credits.billing_order ASC, people.name ASC").
+		Pluck("people.name", &names).Error; err != nil {
+		return err
+	}
+
+	return tx.Model(&models.Movie{}).Where("id = ?", movieID).
+		Update("director", strings.Join(names, ", ")).Error
+}
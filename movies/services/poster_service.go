@@ -2,75 +2,275 @@
 package services
 
 import (
+	"bytes"
 	"cine_conecta_backend/config"
+	"cine_conecta_backend/movies/imaging"
 	"cine_conecta_backend/movies/models"
 	"cine_conecta_backend/storage"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"io"
 	"mime/multipart"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 )
 
-func UploadPoster(movieID uint, fileHeader *multipart.FileHeader) (string, error) {
+// Tamaño máximo de los pósters subidos
+const maxPosterSize = 50 << 20 // 50 MB
+
+// Tiempo de validez de las URLs de subida directa
+const posterUploadExpiry = 15 * time.Minute
+
+// UploadPoster valida la imagen, genera las variantes WebP y actualiza la película
+func UploadPoster(movieID uint, fileHeader *multipart.FileHeader, userID uint) (*models.Movie, error) {
 	// Verificar si la película existe
 	var movie models.Movie
 	result := config.DB.First(&movie, movieID)
 	if result.Error != nil {
-		return "", errors.New("película no encontrada")
+		return nil, errors.New("película no encontrada")
 	}
 
 	// Validaciones básicas
-	if fileHeader.Size > 50<<20 { // 50 MB
-		return "", errors.New("archivo supera los 50 MB")
+	if fileHeader.Size > maxPosterSize {
+		return nil, errors.New("archivo supera los 50 MB")
 	}
 
 	mime := fileHeader.Header.Get("Content-Type")
-	if mime != "image/jpeg" && mime != "image/png" && mime != "image/webp" {
-		return "", errors.New("formato no permitido (solo JPEG, PNG o WEBP)")
+	if !isAllowedPosterMime(mime) {
+		return nil, errors.New("formato no permitido (solo JPEG, PNG o WEBP)")
+	}
+
+	// Verificar que la extensión coincida con el tipo MIME
+	ext := strings.ToLower(filepath.Ext(fileHeader.Filename))
+	if !validateExtensionWithMime(ext, mime) {
+		return nil, errors.New("extensión de archivo no coincide con el tipo de contenido")
 	}
 
 	file, err := fileHeader.Open()
 	if err != nil {
-		return "", fmt.Errorf("error al abrir el archivo: %w", err)
+		return nil, fmt.Errorf("error al abrir el archivo: %w", err)
 	}
 	defer file.Close()
 
-	// Obtener extensión del nombre del archivo original
-	filename := fileHeader.Filename
-	ext := strings.ToLower(filepath.Ext(filename))
+	if err := applyPosterImage(&movie, file, mime, userID); err != nil {
+		return nil, err
+	}
+	return &movie, nil
+}
 
-	// Verificar que la extensión coincida con el tipo MIME
-	if !validateExtensionWithMime(ext, mime) {
-		return "", errors.New("extensión de archivo no coincide con el tipo de contenido")
+// PresignPosterUpload genera una URL para que el cliente suba el póster directamente al almacenamiento
+func PresignPosterUpload(movieID uint, mime string, size int64) (*storage.PresignedUpload, error) {
+	var movie models.Movie
+	if err := config.DB.First(&movie, movieID).Error; err != nil {
+		return nil, errors.New("película no encontrada")
 	}
 
-	// Si no tiene extensión, usar la del tipo MIME
-	if ext == "" {
-		ext = extensionFromMime(mime)
+	if size <= 0 || size > maxPosterSize {
+		return nil, errors.New("el tamaño del archivo debe estar entre 1 byte y 50 MB")
+	}
+	if !isAllowedPosterMime(mime) {
+		return nil, errors.New("formato no permitido (solo JPEG, PNG o WEBP)")
 	}
 
-	// Construir la clave del archivo
-	key := fmt.Sprintf("posters/%d%s", movieID, ext)
+	backend := storage.Current()
+	if backend == nil {
+		return nil, storage.ErrNotConfigured
+	}
 
-	// Borrar póster anterior si existe
-	if movie.PosterURL != "" {
-		// Podría implementarse la eliminación del archivo antiguo aquí
-		fmt.Printf("Reemplazando póster anterior: %s\n", movie.PosterURL)
+	suffix, err := randomKeySuffix()
+	if err != nil {
+		return nil, err
+	}
+	// La clave incluye el tamaño declarado para comprobarlo al completar la subida
+	key := fmt.Sprintf("%s%d-%d-%s%s", posterUploadPrefix(movieID), time.Now().Unix(), size, suffix, extensionFromMime(mime))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// El límite firmado es el tamaño declarado, así el cliente no puede subir un archivo mayor
+	return backend.Presign(ctx, key, mime, size, posterUploadExpiry)
+}
+
+// CompletePosterUpload verifica el archivo subido directamente, genera las variantes y actualiza la película
+func CompletePosterUpload(movieID uint, key string, userID uint) (*models.Movie, error) {
+	var movie models.Movie
+	if err := config.DB.First(&movie, movieID).Error; err != nil {
+		return nil, errors.New("película no encontrada")
+	}
+
+	// Solo se aceptan claves generadas por PresignPosterUpload para esta película
+	if !strings.HasPrefix(key, posterUploadPrefix(movieID)) || strings.Contains(key, "..") {
+		return nil, errors.New("clave de subida inválida para esta película")
+	}
+	mime := mimeFromExtension(strings.ToLower(filepath.Ext(key)))
+	declaredSize, ok := declaredUploadSize(strings.TrimPrefix(key, posterUploadPrefix(movieID)))
+	if mime == "" || !ok {
+		return nil, errors.New("clave de subida inválida para esta película")
+	}
+
+	backend := storage.Current()
+	if backend == nil {
+		return nil, storage.ErrNotConfigured
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	reader, info, err := backend.Open(ctx, key)
+	if err != nil {
+		return nil, err
 	}
+	defer reader.Close()
 
-	url, err := storage.UploadPoster(key, file, mime)
+	// El archivo original se elimina siempre: solo se conservan las variantes procesadas
+	defer func() {
+		if err := backend.Delete(context.Background(), key); err != nil {
+			fmt.Printf("[DEBUG-POSTER] No se pudo eliminar la subida %s: %v\n", key, err)
+		}
+	}()
+
+	if info.Size != declaredSize {
+		return nil, errors.New("el tamaño del archivo subido no coincide con el declarado")
+	}
+
+	if err := applyPosterImage(&movie, io.LimitReader(reader, maxPosterSize), mime, userID); err != nil {
+		return nil, err
+	}
+	return &movie, nil
+}
+
+// applyPosterImage procesa la imagen, sube sus variantes y actualiza los campos del póster
+// registrando el cambio en el historial de la película
+func applyPosterImage(movie *models.Movie, r io.Reader, mime string, userID uint) error {
+	// Decodificar la imagen para comprobar que realmente es una imagen válida
+	processed, err := imaging.ProcessPoster(r)
 	if err != nil {
-		return "", fmt.Errorf("error al subir póster: %w", err)
+		return err
+	}
+	if imaging.MimeForFormat(processed.Format) != mime {
+		return errors.New("el contenido del archivo no coincide con el tipo declarado")
+	}
+
+	// URLs del póster anterior para eliminarlas tras actualizar la película
+	previousURLs := posterURLs(*movie)
+
+	// Cada subida usa un prefijo distinto para evitar servir versiones en caché
+	version := time.Now().Unix()
+	variants := make(map[string]string)
+	for _, variant := range processed.Variants {
+		key := fmt.Sprintf("posters/%d/%d-%s.webp", movie.ID, version, variant.Name)
+		url, err := storage.Put(key, bytes.NewReader(variant.Data), "image/webp")
+		if err != nil {
+			return fmt.Errorf("error al subir póster: %w", err)
+		}
+		variants[variant.Name] = url
 	}
 
+	fmt.Printf("[DEBUG-POSTER] Película %d: %d variantes generadas (%dx%d, %s)\n",
+		movie.ID, len(variants), processed.Width, processed.Height, processed.Format)
+
 	// Actualizar BD
-	result = config.DB.Model(&movie).Update("poster_url", url)
+	movie.PosterURL = variants["original"]
+	movie.PosterVariants = variants
+	movie.PosterBlurhash = processed.Blurhash
+	movie.PosterColor = processed.DominantColor
+
+	tx := config.DB.Begin()
+
+	before, err := snapshotMovie(tx, movie.ID)
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("error al actualizar BD: %w", err)
+	}
+
+	result := tx.Model(movie).Select("poster_url", "poster_variants", "poster_blurhash", "poster_color").Updates(movie)
 	if result.Error != nil {
-		return "", fmt.Errorf("error al actualizar BD: %w", result.Error)
+		tx.Rollback()
+		return fmt.Errorf("error al actualizar BD: %w", result.Error)
+	}
+
+	info := RevisionInfo{UserID: userID, Source: models.RevisionSourceManual}
+	if err := recordRevision(tx, movie.ID, info, &before); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("error al actualizar BD: %w", err)
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return fmt.Errorf("error al actualizar BD: %w", err)
 	}
 
-	return url, nil
+	// Borrar póster anterior
+	deleteReplacedObjects(previousURLs, posterURLs(*movie))
+	return nil
+}
+
+// randomKeySuffix genera un sufijo aleatorio para que las claves de archivo no colisionen
+func randomKeySuffix() (string, error) {
+	suffix := make([]byte, 8)
+	if _, err := rand.Read(suffix); err != nil {
+		return "", fmt.Errorf("error al generar la clave: %w", err)
+	}
+	return hex.EncodeToString(suffix), nil
+}
+
+// declaredUploadSize obtiene el tamaño declarado al firmar la subida a partir del nombre que sigue
+// al prefijo de la clave (<timestamp>-<tamaño>-<sufijo>.<ext>)
+func declaredUploadSize(name string) (int64, bool) {
+	parts := strings.Split(name, "-")
+	if len(parts) != 3 {
+		return 0, false
+	}
+	size, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil || size <= 0 || size > maxPosterSize {
+		return 0, false
+	}
+	return size, true
+}
+
+// posterUploadPrefix devuelve el prefijo de las subidas directas de una película
+func posterUploadPrefix(movieID uint) string {
+	return fmt.Sprintf("posters/%d/upload-", movieID)
+}
+
+// posterURLs devuelve todas las URLs del póster de una película (principal y variantes)
+func posterURLs(movie models.Movie) []string {
+	var urls []string
+	if movie.PosterURL != "" {
+		urls = append(urls, movie.PosterURL)
+	}
+	for _, url := range movie.PosterVariants {
+		urls = append(urls, url)
+	}
+	return urls
+}
+
+// deleteReplacedObjects elimina del almacenamiento los archivos que ya no se usan.
+// Los errores solo se registran: el barrido de huérfanos los recogerá más tarde.
+func deleteReplacedObjects(previous, current []string) {
+	inUse := make(map[string]bool)
+	for _, url := range current {
+		inUse[url] = true
+	}
+
+	for _, url := range previous {
+		if inUse[url] {
+			continue
+		}
+		if err := storage.DeleteURL(url); err != nil {
+			fmt.Printf("[DEBUG-POSTER] No se pudo eliminar %s: %v\n", url, err)
+		} else {
+			fmt.Printf("[DEBUG-POSTER] Póster anterior eliminado: %s\n", url)
+		}
+	}
+}
+
+func isAllowedPosterMime(mime string) bool {
+	return mime == "image/jpeg" || mime == "image/png" || mime == "image/webp"
 }
 
 func extensionFromMime(m string) string {
@@ -84,6 +284,19 @@ func extensionFromMime(m string) string {
 	}
 }
 
+func mimeFromExtension(ext string) string {
+	switch ext {
+	case ".jpg", ".jpeg":
+		return "image/jpeg"
+	case ".png":
+		return "image/png"
+	case ".webp":
+		return "image/webp"
+	default:
+		return ""
+	}
+}
+
 func validateExtensionWithMime(ext, mime string) bool {
 	ext = strings.ToLower(ext)
 	switch ext {
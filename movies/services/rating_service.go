@@ -0,0 +1,144 @@
+package services
+
+import (
+	"cine_conecta_backend/config"
+	"cine_conecta_backend/movies/models"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// DefaultRatingMinVotes es el número de comentarios que aporta la media global a la valoración ponderada
+const DefaultRatingMinVotes = 10
+
+// Límites de la puntuación de los comentarios y número de tramos del histograma
+const (
+	minCommentScore     = 1.0
+	maxCommentScore     = 5.0
+	RatingHistogramBins = 10
+)
+
+// RatingPrior es la referencia de la valoración ponderada (media bayesiana, como la de IMDb):
+// cada película parte de MinVotes comentarios ficticios con la puntuación Mean, de modo que
+// una película con pocos comentarios no supera a otra con muchos comentarios buenos.
+type RatingPrior struct {
+	Mean     float64 `json:"mean"`
+	MinVotes float64 `json:"min_votes"`
+}
+
+// RatingHistogramBin es un tramo del histograma de puntuaciones de una película
+type RatingHistogramBin struct {
+	Bucket   int     `json:"bucket"` // 1..10
+	MinScore float64 `json:"min_score"`
+	MaxScore float64 `json:"max_score"`
+	Count    int64   `json:"count"`
+}
+
+// RatingHistogram resume la distribución de puntuaciones de los comentarios de una película
+type RatingHistogram struct {
+	MovieID        uint                 `json:"movie_id"`
+	CommentCount   int                  `json:"comment_count"`
+	AverageScore   float64              `json:"average_score"`
+	WeightedRating float64              `json:"weighted_rating"`
+	Bins           []RatingHistogramBin `json:"bins"`
+}
+
+// LoadRatingPrior obtiene la referencia de la valoración ponderada. RATING_MIN_VOTES fija el peso
+// de la media global y RATING_PRIOR_MEAN permite fijarla; si no se indica, es la media de todos
+// los comentarios de las películas activas.
+func LoadRatingPrior() (RatingPrior, error) {
+	prior := RatingPrior{MinVotes: DefaultRatingMinVotes}
+	if value, err := strconv.ParseFloat(strings.TrimSpace(os.Getenv("RATING_MIN_VOTES")), 64); err == nil && value >= 0 {
+		prior.MinVotes = value
+	}
+
+	if value, err := strconv.ParseFloat(strings.TrimSpace(os.Getenv("RATING_PRIOR_MEAN")), 64); err == nil &&
+		value >= minCommentScore && value <= maxCommentScore {
+		prior.Mean = value
+		return prior, nil
+	}
+
+	// Sin comentarios se toma el punto medio de la escala
+	err := config.DB.Raw(`SELECT COALESCE(SUM(score_sum) / NULLIF(SUM(comment_count), 0), ?)
+		FROM movies WHERE deleted_at IS NULL`, (minCommentScore+maxCommentScore)/2).
+		Scan(&prior.Mean).Error
+	return prior, err
+}
+
+// Weighted calcula la valoración ponderada a partir de la suma de puntuaciones y del número de comentarios.
+// Las películas sin comentarios no tienen valoración.
+func (p RatingPrior) Weighted(scoreSum float64, count int) float64 {
+	if count <= 0 {
+		return 0
+	}
+	weighted := (scoreSum + p.MinVotes*p.Mean) / (float64(count) + p.MinVotes)
+	return math.Round(weighted*100) / 100
+}
+
+// OrderExpr devuelve la expresión SQL de la valoración ponderada sobre las columnas de contadores de movies
+func (p RatingPrior) OrderExpr() string {
+	return "CASE WHEN comment_count > 0 THEN (score_sum + " + formatSQLFloat(p.MinVotes*p.Mean) +
+		") / (comment_count + " + formatSQLFloat(p.MinVotes) + ") ELSE 0 END"
+}
+
+// ApplyWeightedRatings completa la valoración ponderada de las películas
+func (p RatingPrior) ApplyWeightedRatings(movies []models.Movie) {
+	for i := range movies {
+		movies[i].WeightedRating = p.Weighted(movies[i].ScoreSum, movies[i].CommentCount)
+	}
+}
+
+// GetRatingHistogram obtiene la distribución de las puntuaciones de los comentarios de una película
+// en 10 tramos iguales de la escala 1..5
+func GetRatingHistogram(movieID uint) (RatingHistogram, error) {
+	var movie models.Movie
+	if err := config.DB.Select("id", "comment_count", "score_sum", "avg_score").First(&movie, movieID).Error; err != nil {
+		return RatingHistogram{}, err
+	}
+
+	prior, err := LoadRatingPrior()
+	if err != nil {
+		return RatingHistogram{}, err
+	}
+
+	histogram := RatingHistogram{
+		MovieID:        movie.ID,
+		CommentCount:   movie.CommentCount,
+		AverageScore:   movie.AvgScore,
+		WeightedRating: prior.Weighted(movie.ScoreSum, movie.CommentCount),
+		Bins:           make([]RatingHistogramBin, RatingHistogramBins),
+	}
+	width := (maxCommentScore - minCommentScore) / RatingHistogramBins
+	for i := range histogram.Bins {
+		histogram.Bins[i] = RatingHistogramBin{
+			Bucket:   i + 1,
+			MinScore: math.Round((minCommentScore+width*float64(i))*10) / 10,
+			MaxScore: math.Round((minCommentScore+width*float64(i+1))*10) / 10,
+		}
+	}
+
+	// Cada tramo incluye su límite inferior (el margen evita errores de redondeo en los límites);
+	// la puntuación máxima cae en el último
+	var rows []struct {
+		Bucket int
+		Total  int64
+	}
+	err = config.DB.Raw(`SELECT LEAST(GREATEST(FLOOR((sentiment_score - ?) / ? + 0.000001)::int + 1, 1), ?) AS bucket, COUNT(*) AS total
+		FROM comments WHERE movie_id = ?
+		GROUP BY bucket`, minCommentScore, width, RatingHistogramBins, movieID).
+		Scan(&rows).Error
+	if err != nil {
+		return histogram, err
+	}
+	for _, row := range rows {
+		histogram.Bins[row.Bucket-1].Count = row.Total
+	}
+
+	return histogram, nil
+}
+
+// formatSQLFloat escribe un número para incluirlo en una expresión SQL
+func formatSQLFloat(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
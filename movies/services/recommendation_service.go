@@ -48,26 +48,124 @@ func GetFavoriteGenres(userID uint) ([]string, error) {
 	return getFavoriteGenres(userID)
 }
 
-// GetRecommendedMovies devuelve películas recomendadas para un usuario basadas en el análisis de sentimientos
-func GetRecommendedMovies(userID uint) ([]movieModels.Movie, error) {
+// Origen de una recomendación personalizada
+const (
+	RecommendationSourceFactorization = "factorization" // Modelo de factores latentes entrenado sin conexión
+	RecommendationSourceCollaborative = "collaborative" // Filtrado colaborativo ítem-ítem
+	RecommendationSourceHeuristic     = "heuristic"     // Géneros favoritos y valoración (usuarios sin historial)
+)
+
+// recommendationsLimit es el número de recomendaciones personalizadas que se devuelven
+const recommendationsLimit = 5
+
+// Recommendation es una película recomendada a un usuario y el motivo
+type Recommendation struct {
+	Movie     movieModels.Movie
+	Source    string
+	BecauseOf string // Película valorada por el usuario que más aporta (filtrado colaborativo)
+}
+
+// GetRecommendedMovies devuelve películas recomendadas para un usuario. Se usa primero el modelo de
+// factores latentes y después las similitudes precalculadas entre películas a partir de los me gusta y
+// comentarios; si no bastan (usuarios nuevos o modelos sin calcular) se completa con las recomendaciones
+// por géneros favoritos y valoración.
+func GetRecommendedMovies(userID uint, restrictions ContentRestrictions) ([]Recommendation, error) {
+	seen, err := GetSeenMovieIDs(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 1. Producto escalar con los vectores del último modelo de factores
+	factors, err := getFactorRecommendations(userID, recommendationsLimit, seen, restrictions)
+	if err != nil {
+		return nil, err
+	}
+	var picks []Recommendation
+	exclude := append([]uint{}, seen...)
+	for _, item := range factors {
+		picks = append(picks, Recommendation{Movie: movieModels.Movie{ID: item.MovieID}, Source: RecommendationSourceFactorization})
+		exclude = append(exclude, item.MovieID)
+	}
+
+	// 2. Películas parecidas a las que valoró el usuario
+	if len(picks) < recommendationsLimit {
+		collaborative, err := getCollaborativeRecommendations(userID, recommendationsLimit-len(picks), exclude, restrictions)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range collaborative {
+			picks = append(picks, Recommendation{
+				Movie:     movieModels.Movie{ID: item.MovieID},
+				Source:    RecommendationSourceCollaborative,
+				BecauseOf: item.BecauseOfTitle,
+			})
+		}
+	}
+
+	var recommendations []Recommendation
+	included := make(map[uint]bool)
+	if len(picks) > 0 {
+		ids := make([]uint, len(picks))
+		for i, pick := range picks {
+			ids[i] = pick.Movie.ID
+		}
+		var movies []movieModels.Movie
+		if err := config.DB.Where("id IN ?", ids).Find(&movies).Error; err != nil {
+			return nil, err
+		}
+		moviesByID := make(map[uint]movieModels.Movie, len(movies))
+		for _, movie := range movies {
+			moviesByID[movie.ID] = movie
+		}
+
+		for _, pick := range picks {
+			if movie, ok := moviesByID[pick.Movie.ID]; ok {
+				pick.Movie = movie
+				recommendations = append(recommendations, pick)
+				included[movie.ID] = true
+			}
+		}
+	}
+	if len(recommendations) >= recommendationsLimit {
+		return recommendations, nil
+	}
+
+	// 3. Arranque en frío: completar con las recomendaciones por géneros y valoración
+	heuristic, err := getHeuristicRecommendations(userID, restrictions)
+	if err != nil {
+		return nil, err
+	}
+	for _, movie := range heuristic {
+		if len(recommendations) >= recommendationsLimit {
+			break
+		}
+		if !included[movie.ID] {
+			recommendations = append(recommendations, Recommendation{Movie: movie, Source: RecommendationSourceHeuristic})
+			included[movie.ID] = true
+		}
+	}
+
+	return recommendations, nil
+}
+
+// getHeuristicRecommendations recomienda películas según los géneros favoritos del usuario, la valoración
+// y el sentimiento de los comentarios
+func getHeuristicRecommendations(userID uint, restrictions ContentRestrictions) ([]movieModels.Movie, error) {
 	// 1. Obtener géneros de películas que al usuario le gustan (comentarios positivos)
 	favoriteGenres, err := getFavoriteGenres(userID)
 	if err != nil {
 		return nil, err
 	}
 
-	// 2. Obtener películas que el usuario ya ha visto/comentado
-	var userWatchedMovies []uint
-	if err := config.DB.Model(&models.Comment{}).
-		Where("user_id = ?", userID).
-		Distinct("movie_id").
-		Pluck("movie_id", &userWatchedMovies).Error; err != nil {
+	// 2. Obtener películas que el usuario ya ha visto (diario) o comentado
+	userWatchedMovies, err := GetSeenMovieIDs(userID)
+	if err != nil {
 		return nil, err
 	}
 
-	// 3. Obtener todas las películas disponibles
+	// 3. Obtener todas las películas disponibles y aptas para el usuario
 	var allMovies []movieModels.Movie
-	if err := config.DB.Find(&allMovies).Error; err != nil {
+	if err := config.DB.Scopes(ContentFilter(restrictions)).Find(&allMovies).Error; err != nil {
 		return nil, err
 	}
 
@@ -156,7 +254,7 @@ func GetRecommendedMovies(userID uint) ([]movieModels.Movie, error) {
 		}
 
 		// Obtener películas populares como respaldo
-		popularMovies, err := getTopRatedMovies(10, nil)
+		popularMovies, err := getTopRatedMovies(10, nil, restrictions)
 		if err == nil {
 			for _, movie := range popularMovies {
 				if !existingIDs[movie.ID] {
@@ -278,8 +376,13 @@ func getPopularMoviesByGenres(genres []string) ([]movieModels.Movie, error) {
 		values = append(values, "%"+genreName+"%")
 	}
 
-	// Obtener películas con los géneros especificados y rating alto
-	query := config.DB.Order("rating DESC")
+	prior, err := LoadRatingPrior()
+	if err != nil {
+		return nil, err
+	}
+
+	// Obtener películas con los géneros especificados y valoración ponderada alta
+	query := config.DB.Order(prior.OrderExpr() + " DESC")
 
 	if len(conditions) > 0 {
 		query = query.Where(strings.Join(conditions, " OR "), values...)
@@ -293,9 +396,14 @@ func getPopularMoviesByGenres(genres []string) ([]movieModels.Movie, error) {
 }
 
 // getTopRatedMovies obtiene las películas mejor valoradas
-func getTopRatedMovies(limit int, excludeIDs []uint) ([]movieModels.Movie, error) {
+func getTopRatedMovies(limit int, excludeIDs []uint, restrictions ContentRestrictions) ([]movieModels.Movie, error) {
+	prior, err := LoadRatingPrior()
+	if err != nil {
+		return nil, err
+	}
+
 	var movies []movieModels.Movie
-	query := config.DB.Order("rating DESC").Limit(limit)
+	query := config.DB.Scopes(ContentFilter(restrictions)).Order(prior.OrderExpr() + " DESC").Limit(limit)
 
 	// Excluir películas ya vistas
 	if len(excludeIDs) > 0 {
@@ -310,10 +418,10 @@ func getTopRatedMovies(limit int, excludeIDs []uint) ([]movieModels.Movie, error
 }
 
 // GetMoviesByPositiveSentiment obtiene películas con mayor porcentaje de comentarios positivos
-func GetMoviesByPositiveSentiment(limit int) ([]movieModels.Movie, error) {
-	// Obtener todas las películas
+func GetMoviesByPositiveSentiment(limit int, restrictions ContentRestrictions) ([]movieModels.Movie, error) {
+	// Obtener todas las películas aptas para el usuario
 	var allMovies []movieModels.Movie
-	if err := config.DB.Find(&allMovies).Error; err != nil {
+	if err := config.DB.Scopes(ContentFilter(restrictions)).Find(&allMovies).Error; err != nil {
 		return nil, err
 	}
 
@@ -333,8 +441,7 @@ func GetMoviesByPositiveSentiment(limit int) ([]movieModels.Movie, error) {
 		}
 
 		// Sólo agregar películas con al menos un comentario
-		var commentCount int64
-		config.DB.Model(&models.Comment{}).Where("movie_id = ?", movie.ID).Count(&commentCount)
+		commentCount := movie.CommentCount
 
 		if commentCount > 0 {
 			// Dar más peso a películas con más comentarios pero manteniendo
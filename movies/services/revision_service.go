@@ -0,0 +1,244 @@
+package services
+
+import (
+	"cine_conecta_backend/config"
+	"cine_conecta_backend/movies/models"
+	"cine_conecta_backend/movies/people"
+	"cine_conecta_backend/movies/slugs"
+
+	"gorm.io/gorm"
+)
+
+// RevisionInfo identifica al autor y el origen de un cambio en el catálogo
+type RevisionInfo struct {
+	UserID   uint   // 0 para los procesos del sistema
+	Source   string // Ver models.RevisionSource*
+	RevertOf *uint  // Revisión restaurada (solo al revertir)
+}
+
+// GetMovieRevisions obtiene el historial de cambios de una película, el más reciente primero
+func GetMovieRevisions(movieID uint) ([]models.MovieRevision, error) {
+	if err := config.DB.Select("id").First(&models.Movie{}, movieID).Error; err != nil {
+		return nil, err
+	}
+
+	var revisions []models.MovieRevision
+	err := config.DB.Model(&models.MovieRevision{}).
+		Select("movie_revisions.*, users.name AS user_name").
+		Joins("LEFT JOIN users ON users.id = movie_revisions.user_id").
+		Where("movie_revisions.movie_id = ?", movieID).
+		Order("movie_revisions.id DESC").
+		Find(&revisions).Error
+	return revisions, err
+}
+
+// RevertMovieRevision devuelve la película al estado que tenía tras una revisión, incluidos sus géneros,
+// avisos, clasificaciones y traducciones. La valoración se calcula a partir de los comentarios y no se
+// restaura, igual que la papelera. La restauración queda registrada como una revisión nueva.
+func RevertMovieRevision(movieID, revisionID, userID uint) (*models.Movie, error) {
+	var revision models.MovieRevision
+	if err := config.DB.Where("id = ? AND movie_id = ?", revisionID, movieID).First(&revision).Error; err != nil {
+		return nil, err
+	}
+
+	tx := config.DB.Begin()
+
+	var movie models.Movie
+	if err := tx.First(&movie, movieID).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	before, err := snapshotMovie(tx, movieID)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	snapshot := revision.Snapshot
+	movie.Title = snapshot.Title
+	movie.Description = snapshot.Description
+	movie.Director = snapshot.Director
+	movie.ReleaseDate = snapshot.ReleaseDate
+	movie.ImdbID = snapshot.ImdbID
+	movie.TmdbID = snapshot.TmdbID
+	movie.RuntimeMinutes = snapshot.RuntimeMinutes
+	movie.Genre = snapshot.Genre
+	if snapshot.PosterURL != movie.PosterURL {
+		movie.SetExternalPoster(snapshot.PosterURL)
+	}
+	// Las revisiones anteriores a que se registraran los avisos no los incluyen
+	if snapshot.ContentAdvisories != nil {
+		movie.ContentAdvisories = snapshot.ContentAdvisories
+	}
+
+	if err := tx.Omit(models.MovieCounterColumns...).Save(&movie).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	// Restaurar las asociaciones de géneros tal como estaban
+	if err := tx.Exec("DELETE FROM movie_genres WHERE movie_id = ?", movie.ID).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	for _, genreName := range snapshot.Genres {
+		genre, err := findOrCreateGenre(tx, genreName)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		if genre == nil {
+			continue
+		}
+		if err := tx.Exec("INSERT INTO movie_genres (movie_id, genre_id) VALUES (?, ?) ON CONFLICT DO NOTHING",
+			movie.ID, genre.ID).Error; err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	if snapshot.Certifications != nil {
+		if err := restoreCertifications(tx, movie.ID, snapshot.Certifications); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+	if snapshot.Translations != nil {
+		if err := restoreTranslations(tx, movie.ID, snapshot.Translations); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	if err := people.SyncDirectorCredits(tx, &movie); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := slugs.Sync(tx, &movie); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	info := RevisionInfo{UserID: userID, Source: models.RevisionSourceRevert, RevertOf: &revision.ID}
+	if err := recordRevision(tx, movie.ID, info, &before); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+	return &movie, nil
+}
+
+// restoreCertifications reemplaza las clasificaciones de una película por las de una revisión
+func restoreCertifications(tx *gorm.DB, movieID uint, certifications map[string]models.CertificationSnapshot) error {
+	if err := tx.Where("movie_id = ?", movieID).Delete(&models.MovieCertification{}).Error; err != nil {
+		return err
+	}
+	for country, certification := range certifications {
+		restored := models.MovieCertification{MovieID: movieID, Country: country, Rating: certification.Rating, MinAge: certification.MinAge}
+		if err := tx.Create(&restored).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// restoreTranslations reemplaza las traducciones de una película por las de una revisión
+func restoreTranslations(tx *gorm.DB, movieID uint, translations map[string]models.TranslationSnapshot) error {
+	if err := tx.Where("movie_id = ?", movieID).Delete(&models.MovieTranslation{}).Error; err != nil {
+		return err
+	}
+	for locale, translation := range translations {
+		restored := models.MovieTranslation{MovieID: movieID, Locale: locale, Title: translation.Title, Description: translation.Description}
+		if err := tx.Create(&restored).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// snapshotMovie lee dentro de la transacción el estado actual de los datos editables de una película,
+// también si está en la papelera
+func snapshotMovie(tx *gorm.DB, movieID uint) (models.MovieSnapshot, error) {
+	var movie models.Movie
+	if err := tx.Unscoped().Preload("Certifications").First(&movie, movieID).Error; err != nil {
+		return models.MovieSnapshot{}, err
+	}
+
+	var genres []string
+	err := tx.Table("genres").
+		Joins("JOIN movie_genres ON movie_genres.genre_id = genres.id").
+		Where("movie_genres.movie_id = ?", movieID).
+		Order("genres.name ASC").
+		Pluck("genres.name", &genres).Error
+	if err != nil {
+		return models.MovieSnapshot{}, err
+	}
+
+	var translations []models.MovieTranslation
+	if err := tx.Where("movie_id = ?", movieID).Find(&translations).Error; err != nil {
+		return models.MovieSnapshot{}, err
+	}
+
+	var credits []struct {
+		Role      string
+		Name      string
+		Character string
+	}
+	err = tx.Table("movie_credits").
+		Select("movie_credits.role, people.name, movie_credits.character").
+		Joins("JOIN people ON people.id = movie_credits.person_id").
+		Where("movie_credits.movie_id = ? AND movie_credits.role <> ?", movieID, models.RoleDirector).
+		Order("movie_credits.role ASC, movie_credits.billing_order ASC, people.name ASC").
+		Scan(&credits).Error
+	if err != nil {
+		return models.MovieSnapshot{}, err
+	}
+
+	snapshot := models.NewMovieSnapshot(movie, genres, translations)
+	snapshot.Credits = make([]string, 0, len(credits))
+	for _, credit := range credits {
+		entry := credit.Role + ": " + credit.Name
+		if credit.Character != "" {
+			entry += " (" + credit.Character + ")"
+		}
+		snapshot.Credits = append(snapshot.Credits, entry)
+	}
+	return snapshot, nil
+}
+
+// recordRevision guarda una revisión con los cambios respecto al estado anterior (nil al crear la película).
+// Si nada cambió no se registra nada, salvo que se trate de una restauración.
+func recordRevision(tx *gorm.DB, movieID uint, info RevisionInfo, before *models.MovieSnapshot) error {
+	after, err := snapshotMovie(tx, movieID)
+	if err != nil {
+		return err
+	}
+
+	previous := models.MovieSnapshot{Genres: []string{}}
+	if before != nil {
+		previous = *before
+	}
+	changes := after.Diff(previous)
+	if before != nil && len(changes) == 0 && info.RevertOf == nil {
+		return nil
+	}
+
+	revision := models.MovieRevision{
+		MovieID:  movieID,
+		Source:   info.Source,
+		RevertOf: info.RevertOf,
+		Changes:  changes,
+		Snapshot: after,
+	}
+	if revision.Source == "" {
+		revision.Source = models.RevisionSourceManual
+	}
+	if info.UserID != 0 {
+		userID := info.UserID
+		revision.UserID = &userID
+	}
+	return tx.Create(&revision).Error
+}
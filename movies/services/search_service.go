@@ -13,6 +13,11 @@ type SearchParams struct {
 	Title  string  `json:"title"`  // Búsqueda por título
 	Genre  string  `json:"genre"`  // Filtro por nombre de género
 	Rating float64 `json:"rating"` // Puntuación mínima
+	Person string  `json:"person"` // Filtro por nombre de persona del reparto o equipo
+	Role   string  `json:"role"`   // Rol de la persona (director, actor, writer, composer)
+
+	// Restricciones de edad y control parental del usuario que busca
+	Restrictions ContentRestrictions `json:"-"`
 }
 
 // GenreInfo contiene información sobre un género específico
@@ -33,8 +38,12 @@ func SearchMovies(params SearchParams) ([]models.Movie, error) {
 
 	// Filtro por título
 	if params.Title != "" {
-		// Usar LOWER para hacer la búsqueda case-insensitive de manera más compatible
-		query = query.Where("LOWER(title) LIKE LOWER(?)", "%"+params.Title+"%")
+		// Usar LOWER para hacer la búsqueda case-insensitive de manera más compatible.
+		// También se busca en los títulos traducidos a cualquier idioma.
+		translated := config.DB.Model(&models.MovieTranslation{}).
+			Select("movie_id").
+			Where("LOWER(title) LIKE LOWER(?)", "%"+params.Title+"%")
+		query = query.Where("(LOWER(title) LIKE LOWER(?) OR id IN (?))", "%"+params.Title+"%", translated)
 		fmt.Printf("[DEBUG-SEARCH] Aplicando filtro de título: LOWER(title) LIKE LOWER('%%%s%%')\n", params.Title)
 	}
 
@@ -50,6 +59,22 @@ func SearchMovies(params SearchParams) ([]models.Movie, error) {
 		fmt.Printf("[DEBUG-SEARCH] Aplicando filtro de puntuación: rating >= %.1f\n", params.Rating)
 	}
 
+	// Filtro por persona (reparto o equipo técnico)
+	if params.Person != "" {
+		sub := config.DB.Table("movie_credits").
+			Select("movie_credits.movie_id").
+			Joins("JOIN people ON people.id = movie_credits.person_id").
+			Where("LOWER(people.name) LIKE LOWER(?)", "%"+params.Person+"%")
+		if params.Role != "" {
+			sub = sub.Where("movie_credits.role = ?", params.Role)
+		}
+		query = query.Where("id IN (?)", sub)
+		fmt.Printf("[DEBUG-SEARCH] Aplicando filtro de persona: %s (rol=%s)\n", params.Person, params.Role)
+	}
+
+	// Ocultar las películas no aptas para el usuario
+	query = query.Scopes(ContentFilter(params.Restrictions))
+
 	// Ejecutar la consulta sin precargar para evitar problemas
 	if err := query.Find(&movies).Error; err != nil {
 		fmt.Printf("[DEBUG-SEARCH] Error en la consulta: %v\n", err)
@@ -0,0 +1,384 @@
+package services
+
+import (
+	"cine_conecta_backend/config"
+	"cine_conecta_backend/movies/models"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// Peso de cada señal en la similitud entre películas
+const (
+	similarGenreWeight       = 0.35
+	similarPeopleWeight      = 0.25
+	similarDescriptionWeight = 0.2
+	similarCoLikeWeight      = 0.2
+)
+
+// Peso de cada rol al comparar el reparto y el equipo técnico. Se considera que dos películas
+// comparten todo su equipo relevante cuando la suma llega a similarPeopleSaturation.
+var similarRoleWeights = map[models.CreditRole]float64{
+	models.RoleDirector: 1,
+	models.RoleActor:    0.5,
+	models.RoleWriter:   0.5,
+	models.RoleComposer: 0.25,
+}
+
+const similarPeopleSaturation = 2.0
+
+// Palabras vacías que no cuentan al comparar las descripciones
+var descriptionStopWords = map[string]bool{
+	"que": true, "con": true, "por": true, "para": true, "una": true, "los": true, "las": true,
+	"del": true, "sus": true, "como": true, "mas": true, "pero": true, "sobre": true, "entre": true,
+	"cuando": true, "donde": true, "esta": true, "este": true, "ese": true, "esa": true, "son": true,
+	"the": true, "and": true, "for": true, "with": true, "his": true, "her": true, "their": true,
+	"from": true, "that": true, "this": true, "who": true, "into": true, "when": true,
+}
+
+// SimilarityBreakdown es la aportación (0-1) de cada señal a la similitud
+type SimilarityBreakdown struct {
+	Genres      float64 `json:"genres"`
+	People      float64 `json:"people"`
+	Description float64 `json:"description"`
+	CoLikes     float64 `json:"co_likes"`
+}
+
+// SimilarMovie es una película parecida a otra, con la puntuación y los motivos
+type SimilarMovie struct {
+	Movie     models.Movie        `json:"movie"`
+	Score     float64             `json:"score"`
+	Breakdown SimilarityBreakdown `json:"breakdown"`
+	Reasons   []string            `json:"reasons"`
+}
+
+// sharedPerson es una persona que participa en las dos películas
+type sharedPerson struct {
+	name string
+	role models.CreditRole
+}
+
+// GetSimilarMovies devuelve las películas más parecidas a una dada según los géneros en común,
+// el director y el reparto compartidos, la descripción y los usuarios que dieron me gusta a ambas
+func GetSimilarMovies(movieID uint, limit int, restrictions ContentRestrictions) ([]SimilarMovie, error) {
+	var movie models.Movie
+	if err := config.DB.First(&movie, movieID).Error; err != nil {
+		return nil, err
+	}
+
+	var candidates []models.Movie
+	if err := config.DB.Scopes(ContentFilter(restrictions)).Where("id <> ?", movieID).Find(&candidates).Error; err != nil {
+		return nil, err
+	}
+	if len(candidates) == 0 {
+		return []SimilarMovie{}, nil
+	}
+
+	genres, err := genreNamesByMovie()
+	if err != nil {
+		return nil, err
+	}
+	shared, err := sharedCredits(movieID)
+	if err != nil {
+		return nil, err
+	}
+	coLikes, err := coLikedCounts(movieID)
+	if err != nil {
+		return nil, err
+	}
+	descriptions := descriptionSimilarities(movie, candidates)
+
+	var results []SimilarMovie
+	for _, candidate := range candidates {
+		var breakdown SimilarityBreakdown
+		var reasons []string
+
+		// Géneros en común (índice de Jaccard)
+		if common := intersectNames(genres[movieID], genres[candidate.ID]); len(common) > 0 {
+			union := len(genres[movieID]) + len(genres[candidate.ID]) - len(common)
+			breakdown.Genres = float64(len(common)) / float64(union)
+			reasons = append(reasons, "Comparte los géneros "+strings.Join(common, ", "))
+		}
+
+		// Director y reparto compartidos
+		people := shared[candidate.ID]
+		if len(people) == 0 && movie.Director != "" && strings.EqualFold(strings.TrimSpace(movie.Director), strings.TrimSpace(candidate.Director)) {
+			// Sin créditos registrados se compara el director de la ficha
+			people = []sharedPerson{{name: strings.TrimSpace(candidate.Director), role: models.RoleDirector}}
+		}
+		if len(people) > 0 {
+			var weight float64
+			for _, person := range people {
+				weight += similarRoleWeights[person.role]
+			}
+			breakdown.People = math.Min(1, weight/similarPeopleSaturation)
+			reasons = append(reasons, peopleReasons(people)...)
+		}
+
+		// Descripciones parecidas
+		if similarity, ok := descriptions[candidate.ID]; ok && similarity.score > 0 {
+			breakdown.Description = similarity.score
+			if len(similarity.terms) > 0 {
+				reasons = append(reasons, "Argumento parecido ("+strings.Join(similarity.terms, ", ")+")")
+			}
+		}
+
+		// Usuarios que dieron me gusta a las dos (similitud del coseno)
+		if count := coLikes[candidate.ID]; count > 0 && movie.LikeCount > 0 && candidate.LikeCount > 0 {
+			breakdown.CoLikes = math.Min(1, float64(count)/math.Sqrt(float64(movie.LikeCount)*float64(candidate.LikeCount)))
+			if count == 1 {
+				reasons = append(reasons, "A 1 usuario que le gustó "+movie.Title+" también le gustó esta")
+			} else {
+				reasons = append(reasons, fmt.Sprintf("A %d usuarios que les gustó %s también les gustó esta", count, movie.Title))
+			}
+		}
+
+		score := similarGenreWeight*breakdown.Genres + similarPeopleWeight*breakdown.People +
+			similarDescriptionWeight*breakdown.Description + similarCoLikeWeight*breakdown.CoLikes
+		if score <= 0 {
+			continue
+		}
+
+		breakdown.Genres = roundSimilarity(breakdown.Genres)
+		breakdown.People = roundSimilarity(breakdown.People)
+		breakdown.Description = roundSimilarity(breakdown.Description)
+		breakdown.CoLikes = roundSimilarity(breakdown.CoLikes)
+		results = append(results, SimilarMovie{
+			Movie:     candidate,
+			Score:     roundSimilarity(score),
+			Breakdown: breakdown,
+			Reasons:   reasons,
+		})
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].Movie.ID < results[j].Movie.ID
+	})
+	if len(results) > limit {
+		results = results[:limit]
+	}
+	if results == nil {
+		results = []SimilarMovie{}
+	}
+	return results, nil
+}
+
+// genreNamesByMovie obtiene los géneros de cada película desde movie_genres
+func genreNamesByMovie() (map[uint][]string, error) {
+	var rows []struct {
+		MovieID uint
+		Name    string
+	}
+	err := config.DB.Table("movie_genres").
+		Select("movie_genres.movie_id, genres.name").
+		Joins("JOIN genres ON genres.id = movie_genres.genre_id").
+		Order("genres.name").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	genres := make(map[uint][]string)
+	for _, row := range rows {
+		genres[row.MovieID] = append(genres[row.MovieID], row.Name)
+	}
+	return genres, nil
+}
+
+// sharedCredits obtiene, para cada película, las personas que también participan en la película dada
+func sharedCredits(movieID uint) (map[uint][]sharedPerson, error) {
+	var rows []struct {
+		MovieID uint
+		Name    string
+		Role    models.CreditRole
+	}
+	err := config.DB.Raw(`SELECT DISTINCT other.movie_id, people.name, other.role, other.billing_order
+		FROM movie_credits own
+		JOIN movie_credits other ON other.person_id = own.person_id AND other.role = own.role AND other.movie_id <> own.movie_id
+		JOIN people ON people.id = other.person_id
+		WHERE own.movie_id = ?
+		ORDER BY other.movie_id, other.billing_order, people.name`, movieID).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	shared := make(map[uint][]sharedPerson)
+	for _, row := range rows {
+		shared[row.MovieID] = append(shared[row.MovieID], sharedPerson{name: row.Name, role: row.Role})
+	}
+	return shared, nil
+}
+
+// coLikedCounts cuenta, para cada película, los usuarios que dieron me gusta a ella y a la película dada
+func coLikedCounts(movieID uint) (map[uint]int, error) {
+	var rows []struct {
+		MovieID uint
+		Total   int
+	}
+	err := config.DB.Raw(`SELECT other.movie_id, COUNT(*) AS total
+		FROM movie_likes own
+		JOIN movie_likes other ON other.user_id = own.user_id AND other.movie_id <> own.movie_id AND other.deleted_at IS NULL
+		WHERE own.movie_id = ? AND own.deleted_at IS NULL
+		GROUP BY other.movie_id`, movieID).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[uint]int, len(rows))
+	for _, row := range rows {
+		counts[row.MovieID] = row.Total
+	}
+	return counts, nil
+}
+
+// descriptionSimilarity es el parecido entre dos descripciones y los términos que más aportan
+type descriptionSimilarity struct {
+	score float64
+	terms []string
+}
+
+// descriptionSimilarities compara la descripción de la película con las de los candidatos
+// mediante la similitud del coseno de sus vectores TF-IDF
+func descriptionSimilarities(movie models.Movie, candidates []models.Movie) map[uint]descriptionSimilarity {
+	result := make(map[uint]descriptionSimilarity)
+	own := descriptionTerms(movie.Description)
+	if len(own) == 0 {
+		return result
+	}
+
+	// Frecuencia de documento de cada término en el catálogo
+	termsByMovie := make(map[uint]map[string]int, len(candidates))
+	documentFrequency := make(map[string]int)
+	for term := range own {
+		documentFrequency[term]++
+	}
+	for _, candidate := range candidates {
+		terms := descriptionTerms(candidate.Description)
+		termsByMovie[candidate.ID] = terms
+		for term := range terms {
+			documentFrequency[term]++
+		}
+	}
+	documents := float64(len(candidates) + 1)
+	weights := func(terms map[string]int) map[string]float64 {
+		vector := make(map[string]float64, len(terms))
+		for term, count := range terms {
+			vector[term] = float64(count) * math.Log(documents/float64(documentFrequency[term]))
+		}
+		return vector
+	}
+
+	ownVector := weights(own)
+	ownNorm := vectorNorm(ownVector)
+	if ownNorm == 0 {
+		return result
+	}
+
+	for _, candidate := range candidates {
+		vector := weights(termsByMovie[candidate.ID])
+		norm := vectorNorm(vector)
+		if norm == 0 {
+			continue
+		}
+
+		type contribution struct {
+			term  string
+			value float64
+		}
+		var dot float64
+		var contributions []contribution
+		for term, value := range ownVector {
+			if other, ok := vector[term]; ok && value*other > 0 {
+				dot += value * other
+				contributions = append(contributions, contribution{term, value * other})
+			}
+		}
+		if dot == 0 {
+			continue
+		}
+
+		// Los tres términos que más aportan explican el parecido
+		sort.Slice(contributions, func(i, j int) bool {
+			if contributions[i].value != contributions[j].value {
+				return contributions[i].value > contributions[j].value
+			}
+			return contributions[i].term < contributions[j].term
+		})
+		var terms []string
+		for i := 0; i < len(contributions) && i < 3; i++ {
+			terms = append(terms, contributions[i].term)
+		}
+		result[candidate.ID] = descriptionSimilarity{score: dot / (ownNorm * norm), terms: terms}
+	}
+	return result
+}
+
+// descriptionTerms cuenta las palabras significativas de una descripción
+func descriptionTerms(description string) map[string]int {
+	terms := make(map[string]int)
+	for _, word := range strings.Fields(models.FoldText(description)) {
+		if len(word) < 3 || descriptionStopWords[word] {
+			continue
+		}
+		terms[word]++
+	}
+	return terms
+}
+
+// vectorNorm calcula la norma euclídea de un vector disperso
+func vectorNorm(vector map[string]float64) float64 {
+	var sum float64
+	for _, value := range vector {
+		sum += value * value
+	}
+	return math.Sqrt(sum)
+}
+
+// intersectNames devuelve los nombres presentes en las dos listas, en el orden de la primera
+func intersectNames(a, b []string) []string {
+	set := make(map[string]bool, len(b))
+	for _, name := range b {
+		set[name] = true
+	}
+	var common []string
+	for _, name := range a {
+		if set[name] {
+			common = append(common, name)
+		}
+	}
+	return common
+}
+
+// peopleReasons describe las personas compartidas agrupadas por rol
+func peopleReasons(people []sharedPerson) []string {
+	byRole := make(map[models.CreditRole][]string)
+	for _, person := range people {
+		byRole[person.role] = append(byRole[person.role], person.name)
+	}
+
+	var reasons []string
+	if names := byRole[models.RoleDirector]; len(names) > 0 {
+		reasons = append(reasons, "Mismo director: "+strings.Join(names, ", "))
+	}
+	if names := byRole[models.RoleActor]; len(names) > 0 {
+		reasons = append(reasons, "Comparte reparto: "+strings.Join(names, ", "))
+	}
+	if names := byRole[models.RoleWriter]; len(names) > 0 {
+		reasons = append(reasons, "Mismo guionista: "+strings.Join(names, ", "))
+	}
+	if names := byRole[models.RoleComposer]; len(names) > 0 {
+		reasons = append(reasons, "Misma banda sonora: "+strings.Join(names, ", "))
+	}
+	return reasons
+}
+
+// roundSimilarity redondea una puntuación a tres decimales
+func roundSimilarity(value float64) float64 {
+	return math.Round(value*1000) / 1000
+}
@@ -0,0 +1,41 @@
+package services
+
+import (
+	"cine_conecta_backend/config"
+	"cine_conecta_backend/movies/models"
+	"cine_conecta_backend/movies/slugs"
+	"errors"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// ResolveMovieSlug busca una película por su slug. Si el slug es uno anterior de la película
+// devuelve la película con redirected a true para que se redirija al slug actual.
+func ResolveMovieSlug(slug string) (models.Movie, bool, error) {
+	slug = strings.ToLower(strings.TrimSpace(slug))
+
+	var movie models.Movie
+	err := config.DB.Where("slug = ?", slug).First(&movie).Error
+	if err == nil {
+		return movie, false, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return movie, false, err
+	}
+
+	var redirect models.MovieSlugRedirect
+	if err := config.DB.Where("slug = ?", slug).First(&redirect).Error; err != nil {
+		return movie, false, err
+	}
+	if err := config.DB.First(&movie, redirect.MovieID).Error; err != nil {
+		return movie, false, err
+	}
+	return movie, true, nil
+}
+
+// MigrateMovieSlugs asigna un slug a las películas que todavía no lo tienen.
+// Devuelve la cantidad de películas actualizadas.
+func MigrateMovieSlugs() (int, error) {
+	return slugs.Migrate(config.DB)
+}
@@ -0,0 +1,101 @@
+package services
+
+import (
+	"cine_conecta_backend/config"
+	"cine_conecta_backend/movies/models"
+	"cine_conecta_backend/storage"
+	"context"
+	"fmt"
+	"time"
+)
+
+// Prefijo de las claves de pósters en el almacenamiento
+const posterKeyPrefix = "posters/"
+
+// SweepReport resume el resultado de un barrido de archivos huérfanos
+type SweepReport struct {
+	Backend    string   `json:"backend"`
+	DryRun     bool     `json:"dry_run"`
+	Scanned    int      `json:"scanned"`
+	Referenced int      `json:"referenced"`
+	Recent     int      `json:"recent"` // Huérfanos demasiado recientes (posibles subidas en curso)
+	Deleted    int      `json:"deleted"`
+	Orphans    []string `json:"orphans"`
+	Errors     []string `json:"errors,omitempty"`
+}
+
+// SweepOrphanObjects elimina los pósters y archivos de la galería que ninguna película referencia.
+// Solo se borran los archivos con una antigüedad mayor que minAge.
+func SweepOrphanObjects(minAge time.Duration, dryRun bool) (*SweepReport, error) {
+	backend := storage.Current()
+	if backend == nil {
+		return nil, storage.ErrNotConfigured
+	}
+
+	// Reunir las claves referenciadas por las películas (también las de la papelera, que aún pueden restaurarse)
+	var movies []models.Movie
+	if err := config.DB.Unscoped().Select("id", "poster_url", "poster_variants").Find(&movies).Error; err != nil {
+		return nil, err
+	}
+	referenced := make(map[string]bool)
+	for _, movie := range movies {
+		for _, url := range posterURLs(movie) {
+			if key, ok := backend.KeyFromURL(url); ok {
+				referenced[key] = true
+			}
+		}
+	}
+
+	var media []models.MovieMedia
+	if err := config.DB.Where("provider = ?", models.MediaProviderUpload).Find(&media).Error; err != nil {
+		return nil, err
+	}
+	for _, item := range media {
+		for _, url := range mediaURLs(item) {
+			if key, ok := backend.KeyFromURL(url); ok {
+				referenced[key] = true
+			}
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	var objects []storage.ObjectInfo
+	for _, prefix := range []string{posterKeyPrefix, mediaKeyPrefix} {
+		listed, err := backend.List(ctx, prefix)
+		if err != nil {
+			return nil, err
+		}
+		objects = append(objects, listed...)
+	}
+
+	report := &SweepReport{Backend: backend.Name(), DryRun: dryRun, Scanned: len(objects), Orphans: []string{}}
+	cutoff := time.Now().Add(-minAge)
+
+	for _, obj := range objects {
+		if referenced[obj.Key] {
+			report.Referenced++
+			continue
+		}
+		if obj.LastModified.After(cutoff) {
+			report.Recent++
+			continue
+		}
+
+		report.Orphans = append(report.Orphans, obj.Key)
+		if dryRun {
+			continue
+		}
+		if err := backend.Delete(ctx, obj.Key); err != nil {
+			report.Errors = append(report.Errors, err.Error())
+			continue
+		}
+		report.Deleted++
+	}
+
+	fmt.Printf("[DEBUG-SWEEP] %d archivos revisados, %d huérfanos, %d eliminados\n",
+		report.Scanned, len(report.Orphans), report.Deleted)
+
+	return report, nil
+}
@@ -0,0 +1,255 @@
+package services
+
+import (
+	"cine_conecta_backend/config"
+	"cine_conecta_backend/movies/models"
+	"errors"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// Formato admitido para los idiomas: código de idioma con región opcional (es, en, pt-BR...)
+var localePattern = regexp.MustCompile(`^[a-zA-Z]{2,3}([-_][a-zA-Z]{2})?$`)
+
+// DefaultLocale devuelve el idioma en el que están guardados el título y la descripción de las películas
+func DefaultLocale() string {
+	if locale, err := NormalizeLocale(os.Getenv("DEFAULT_LOCALE")); err == nil {
+		return locale
+	}
+	return "es"
+}
+
+// NormalizeLocale valida un idioma y lo devuelve normalizado (idioma en minúsculas y región en mayúsculas)
+func NormalizeLocale(locale string) (string, error) {
+	locale = strings.TrimSpace(locale)
+	if !localePattern.MatchString(locale) {
+		return "", errors.New("idioma no válido (use códigos como es, en o pt-BR)")
+	}
+	parts := strings.FieldsFunc(locale, func(r rune) bool { return r == '-' || r == '_' })
+	if len(parts) == 2 {
+		return strings.ToLower(parts[0]) + "-" + strings.ToUpper(parts[1]), nil
+	}
+	return strings.ToLower(parts[0]), nil
+}
+
+// LocaleCandidates devuelve los idiomas preferidos en orden. El parámetro lang tiene prioridad
+// sobre la cabecera Accept-Language; cada variante regional va seguida de su idioma base.
+func LocaleCandidates(lang, acceptLanguage string) []string {
+	var tags []string
+	if locale, err := NormalizeLocale(lang); err == nil {
+		tags = []string{locale}
+	} else {
+		tags = parseAcceptLanguage(acceptLanguage)
+	}
+
+	candidates := make([]string, 0, len(tags)*2)
+	seen := make(map[string]bool)
+	add := func(locale string) {
+		if !seen[locale] {
+			seen[locale] = true
+			candidates = append(candidates, locale)
+		}
+	}
+	for _, tag := range tags {
+		add(tag)
+		if base, _, found := strings.Cut(tag, "-"); found {
+			add(base)
+		}
+	}
+	return candidates
+}
+
+// parseAcceptLanguage extrae los idiomas de la cabecera Accept-Language ordenados por su peso
+func parseAcceptLanguage(header string) []string {
+	type weighted struct {
+		locale string
+		q      float64
+	}
+
+	var entries []weighted
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(strings.TrimSpace(part), ";")
+		locale, err := NormalizeLocale(fields[0])
+		if err != nil {
+			continue // Se ignoran el comodín y las etiquetas no admitidas
+		}
+		q := 1.0
+		for _, param := range fields[1:] {
+			if value, found := strings.CutPrefix(strings.TrimSpace(param), "q="); found {
+				if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+					q = parsed
+				}
+			}
+		}
+		if q > 0 {
+			entries = append(entries, weighted{locale: locale, q: q})
+		}
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].q > entries[j].q
+	})
+
+	locales := make([]string, len(entries))
+	for i, entry := range entries {
+		locales[i] = entry.locale
+	}
+	return locales
+}
+
+// LocalizeMovies sustituye el título y la descripción por la traducción del primer idioma disponible.
+// Si ninguno de los idiomas pedidos tiene traducción se mantiene el texto original.
+func LocalizeMovies(candidates []string, movies []models.Movie) {
+	if len(movies) == 0 {
+		return
+	}
+
+	defaultLocale := DefaultLocale()
+	for i := range movies {
+		movies[i].Locale = defaultLocale
+	}
+	if len(candidates) == 0 {
+		return
+	}
+
+	ids := make([]uint, 0, len(movies))
+	for _, movie := range movies {
+		ids = append(ids, movie.ID)
+	}
+
+	var translations []models.MovieTranslation
+	config.DB.Where("movie_id IN ? AND locale IN ?", ids, candidates).Find(&translations)
+	if len(translations) == 0 {
+		return
+	}
+
+	byMovie := make(map[uint]map[string]models.MovieTranslation)
+	for _, translation := range translations {
+		if byMovie[translation.MovieID] == nil {
+			byMovie[translation.MovieID] = make(map[string]models.MovieTranslation)
+		}
+		byMovie[translation.MovieID][translation.Locale] = translation
+	}
+
+	for i := range movies {
+		available := byMovie[movies[i].ID]
+		for _, locale := range candidates {
+			// El idioma original ya está en la película
+			if locale == defaultLocale {
+				break
+			}
+			if translation, ok := available[locale]; ok {
+				movies[i].Title = translation.Title
+				if translation.Description != "" {
+					movies[i].Description = translation.Description
+				}
+				movies[i].Locale = translation.Locale
+				break
+			}
+		}
+	}
+}
+
+// GetMovieTranslations obtiene las traducciones de una película
+func GetMovieTranslations(movieID uint) ([]models.MovieTranslation, error) {
+	if err := config.DB.Select("id").First(&models.Movie{}, movieID).Error; err != nil {
+		return nil, err
+	}
+
+	var translations []models.MovieTranslation
+	err := config.DB.Where("movie_id = ?", movieID).Order("locale ASC").Find(&translations).Error
+	return translations, err
+}
+
+// SaveMovieTranslation crea o reemplaza la traducción de una película a un idioma
+func SaveMovieTranslation(movieID uint, locale, title, description string, userID uint) (*models.MovieTranslation, error) {
+	locale, err := NormalizeLocale(locale)
+	if err != nil {
+		return nil, err
+	}
+	if locale == DefaultLocale() {
+		return nil, errors.New("el idioma original se edita directamente en la película")
+	}
+	title = strings.TrimSpace(title)
+	if title == "" {
+		return nil, errors.New("el título traducido es obligatorio")
+	}
+
+	if err := config.DB.Select("id").First(&models.Movie{}, movieID).Error; err != nil {
+		return nil, err
+	}
+
+	tx := config.DB.Begin()
+
+	before, err := snapshotMovie(tx, movieID)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	var translation models.MovieTranslation
+	err = tx.Where("movie_id = ? AND locale = ?", movieID, locale).First(&translation).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		tx.Rollback()
+		return nil, err
+	}
+
+	translation.MovieID = movieID
+	translation.Locale = locale
+	translation.Title = title
+	translation.Description = strings.TrimSpace(description)
+	if err := tx.Save(&translation).Error; err != nil {
+		tx.Rollback()
+		return nil, errors.New("error al guardar la traducción")
+	}
+
+	info := RevisionInfo{UserID: userID, Source: models.RevisionSourceManual}
+	if err := recordRevision(tx, movieID, info, &before); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+	return &translation, nil
+}
+
+// DeleteMovieTranslation elimina la traducción de una película a un idioma
+func DeleteMovieTranslation(movieID uint, locale string, userID uint) error {
+	locale, err := NormalizeLocale(locale)
+	if err != nil {
+		return err
+	}
+
+	tx := config.DB.Begin()
+
+	before, err := snapshotMovie(tx, movieID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	result := tx.Where("movie_id = ? AND locale = ?", movieID, locale).Delete(&models.MovieTranslation{})
+	if result.Error != nil {
+		tx.Rollback()
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		tx.Rollback()
+		return gorm.ErrRecordNotFound
+	}
+
+	info := RevisionInfo{UserID: userID, Source: models.RevisionSourceManual}
+	if err := recordRevision(tx, movieID, info, &before); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
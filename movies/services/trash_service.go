@@ -0,0 +1,197 @@
+package services
+
+import (
+	"cine_conecta_backend/config"
+	"cine_conecta_backend/movies/models"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// DefaultTrashRetention es el tiempo que una película permanece en la papelera antes de purgarse
+const DefaultTrashRetention = 30 * 24 * time.Hour
+
+// ErrMovieHasBookings indica que la película tiene entradas vendidas y no se puede eliminar
+var ErrMovieHasBookings = errors.New("la película tiene entradas vendidas")
+
+// PurgeReport resume una purga de la papelera
+type PurgeReport struct {
+	DryRun    bool     `json:"dry_run"`
+	OlderThan string   `json:"older_than"`
+	Purged    []uint   `json:"purged"`
+	Skipped   []uint   `json:"skipped"` // Películas con entradas vendidas que no se pueden purgar
+	Errors    []string `json:"errors,omitempty"`
+}
+
+// GetTrashedMovies obtiene las películas de la papelera, las eliminadas más recientemente primero
+func GetTrashedMovies() ([]models.Movie, error) {
+	var movies []models.Movie
+	err := config.DB.Unscoped().
+		Where("deleted_at IS NOT NULL").
+		Order("deleted_at DESC").
+		Find(&movies).Error
+	return movies, err
+}
+
+// RestoreMovie saca una película de la papelera junto con sus comentarios, me gusta y listas
+func RestoreMovie(id uint, userID uint) (*models.Movie, error) {
+	tx := config.DB.Begin()
+
+	before, err := snapshotMovie(tx, id)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	result := tx.Unscoped().Model(&models.Movie{}).
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		Update("deleted_at", nil)
+	if result.Error != nil {
+		tx.Rollback()
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		tx.Rollback()
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	info := RevisionInfo{UserID: userID, Source: models.RevisionSourceManual}
+	if err := recordRevision(tx, id, info, &before); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+
+	var movie models.Movie
+	if err := config.DB.Preload("Genres").First(&movie, id).Error; err != nil {
+		return nil, err
+	}
+	return &movie, nil
+}
+
+// PurgeMovie elimina definitivamente una película de la papelera con todos los registros que dependen
+// de ella: comentarios, me gusta, funciones, listas y las entradas de los datasets de recomendaciones.
+func PurgeMovie(id uint) error {
+	var movie models.Movie
+	if err := config.DB.Unscoped().Preload("Media").
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		First(&movie).Error; err != nil {
+		return err
+	}
+
+	tx := config.DB.Begin()
+
+	if err := checkMovieBookings(tx, id); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// Eliminar primero los registros que dependen de la película
+	for _, table := range []string{"seat_reservations", "bookings"} {
+		if err := tx.Exec("DELETE FROM "+table+" WHERE showtime_id IN (SELECT id FROM showtimes WHERE movie_id = ?)", id).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	for _, table := range []string{"movie_genres", "movie_credits", "movie_media", "movie_translations", "movie_certifications", "movie_revisions", "movie_slug_redirects", "watchlist_items", "diary_entries", "movie_list_items", "collection_movies", "showtimes", "movie_likes", "comments", "movie_view_stats", "trending_snapshot_entries"} {
+		if err := tx.Exec("DELETE FROM "+table+" WHERE movie_id = ?", id).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	if err := tx.Exec("DELETE FROM movie_similarities WHERE movie_id = ? OR similar_movie_id = ?", id, id).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := tx.Exec("DELETE FROM movie_factors WHERE movie_id = ?", id).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// Quitar la película de los datasets de recomendaciones guardados
+	if err := removeFromRecommendationDatasets(tx, id); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Unscoped().Delete(&models.Movie{}, id).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := tx.Commit().Error; err != nil {
+		return err
+	}
+
+	// Eliminar los archivos del póster y de la galería que ya no se usan
+	deleteReplacedObjects(posterURLs(movie), nil)
+	for _, media := range movie.Media {
+		deleteReplacedObjects(mediaURLs(media), nil)
+	}
+	return nil
+}
+
+// PurgeExpiredTrash elimina definitivamente las películas que llevan en la papelera más de olderThan.
+// En modo dryRun solo informa de las películas que se purgarían.
+func PurgeExpiredTrash(olderThan time.Duration, dryRun bool) (*PurgeReport, error) {
+	report := &PurgeReport{DryRun: dryRun, OlderThan: olderThan.String(), Purged: []uint{}, Skipped: []uint{}}
+
+	var ids []uint
+	if err := config.DB.Unscoped().Model(&models.Movie{}).
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", time.Now().Add(-olderThan)).
+		Order("deleted_at ASC").
+		Pluck("id", &ids).Error; err != nil {
+		return nil, err
+	}
+
+	for _, id := range ids {
+		if dryRun {
+			report.Purged = append(report.Purged, id)
+			continue
+		}
+		if err := PurgeMovie(id); err != nil {
+			if errors.Is(err, ErrMovieHasBookings) {
+				report.Skipped = append(report.Skipped, id)
+				continue
+			}
+			report.Errors = append(report.Errors, fmt.Sprintf("película %d: %v", id, err))
+			continue
+		}
+		report.Purged = append(report.Purged, id)
+	}
+
+	fmt.Printf("[DEBUG-TRASH] Purga de la papelera (dry_run=%t): %d purgadas, %d omitidas, %d errores\n",
+		dryRun, len(report.Purged), len(report.Skipped), len(report.Errors))
+
+	return report, nil
+}
+
+// removeFromRecommendationDatasets quita una película de los datasets de recomendaciones guardados
+func removeFromRecommendationDatasets(tx *gorm.DB, movieID uint) error {
+	return tx.Exec(`UPDATE recommendation_datasets
+		SET recommendations_json = (
+			SELECT COALESCE(jsonb_agg(item), '[]'::jsonb)
+			FROM jsonb_array_elements(recommendations_json) AS item
+			WHERE (item->>'movie_id')::bigint <> ?
+		)
+		WHERE recommendations_json @> ?::jsonb`, movieID, fmt.Sprintf(`[{"movie_id": %d}]`, movieID)).Error
+}
+
+// checkMovieBookings comprueba que ninguna función de la película tenga entradas vendidas,
+// pendientes de cobro o con un reembolso por completar
+func checkMovieBookings(db *gorm.DB, movieID uint) error {
+	var sold int64
+	if err := db.Raw("SELECT COUNT(*) FROM bookings WHERE status IN ('pending', 'confirmed', 'refund_pending') AND showtime_id IN (SELECT id FROM showtimes WHERE movie_id = ?)", movieID).
+		Scan(&sold).Error; err != nil {
+		return err
+	}
+	if sold > 0 {
+		return ErrMovieHasBookings
+	}
+	return nil
+}
@@ -0,0 +1,265 @@
+package services
+
+import (
+	"cine_conecta_backend/config"
+	"cine_conecta_backend/movies/models"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+// Peso de cada interacción en la puntuación de tendencia
+const (
+	trendingViewWeight    = 1.0
+	trendingLikeWeight    = 3.0
+	trendingCommentWeight = 5.0
+)
+
+// trendingSnapshotSize es el número de películas que se guardan en cada instantánea
+const trendingSnapshotSize = 100
+
+// TrendingSnapshotRetention es el tiempo que se conservan las instantáneas de tendencias
+const TrendingSnapshotRetention = 90 * 24 * time.Hour
+
+// ErrInvalidTrendingWindow indica una ventana de tendencias no admitida
+var ErrInvalidTrendingWindow = errors.New("ventana no válida (use 24h, 7d o 30d)")
+
+// trendingWindow es el periodo que se tiene en cuenta y la vida media de las interacciones:
+// una interacción pierde la mitad de su peso cada halfLife
+type trendingWindow struct {
+	period   time.Duration
+	halfLife time.Duration
+}
+
+var trendingWindows = map[string]trendingWindow{
+	models.TrendingWindowDay:   {period: 24 * time.Hour, halfLife: 6 * time.Hour},
+	models.TrendingWindowWeek:  {period: 7 * 24 * time.Hour, halfLife: 48 * time.Hour},
+	models.TrendingWindowMonth: {period: 30 * 24 * time.Hour, halfLife: 7 * 24 * time.Hour},
+}
+
+// TrendingMovie es una película en tendencia con su posición y su movimiento respecto a la instantánea anterior
+type TrendingMovie struct {
+	Rank         int          `json:"rank"`
+	Movie        models.Movie `json:"movie"`
+	Score        float64      `json:"score"`
+	Likes        int          `json:"likes"`
+	Comments     int          `json:"comments"`
+	Views        int          `json:"views"`
+	PreviousRank *int         `json:"previous_rank"`
+	Movement     *int         `json:"movement"` // Puestos que ha subido (negativo si ha bajado)
+	New          bool         `json:"new"`      // No estaba en la instantánea anterior
+}
+
+// TrendingResult es el ranking de tendencias de una ventana
+type TrendingResult struct {
+	Window             string          `json:"window"`
+	SnapshotAt         *time.Time      `json:"snapshot_at"` // Nulo si se ha calculado al momento
+	PreviousSnapshotAt *time.Time      `json:"previous_snapshot_at"`
+	Movies             []TrendingMovie `json:"movies"`
+}
+
+// TrendingSnapshotReport resume las instantáneas creadas
+type TrendingSnapshotReport struct {
+	Snapshots map[string]int `json:"snapshots"` // Ventana -> películas guardadas
+	Pruned    int64          `json:"pruned"`    // Instantáneas antiguas eliminadas
+}
+
+// RecordMovieView suma una visita a la ficha de una película en la hora actual
+func RecordMovieView(movieID uint) {
+	hour := time.Now().UTC().Truncate(time.Hour)
+	err := config.DB.Exec(`INSERT INTO movie_view_stats (movie_id, hour, views) VALUES (?, ?, 1)
+		ON CONFLICT (movie_id, hour) DO UPDATE SET views = movie_view_stats.views + 1`, movieID, hour).Error
+	if err != nil {
+		fmt.Printf("[DEBUG-TRENDING] Error al registrar la visita a la película %d: %v\n", movieID, err)
+	}
+}
+
+// GetTrendingMovies devuelve las películas en tendencia de la ventana según la última instantánea,
+// con el movimiento respecto a la anterior. Si todavía no hay instantáneas se calcula al momento.
+func GetTrendingMovies(window string, limit int, restrictions ContentRestrictions) (*TrendingResult, error) {
+	if _, ok := trendingWindows[window]; !ok {
+		return nil, ErrInvalidTrendingWindow
+	}
+
+	var snapshots []models.TrendingSnapshot
+	if err := config.DB.Where("time_window = ?", window).Order("created_at DESC").Limit(2).Find(&snapshots).Error; err != nil {
+		return nil, err
+	}
+
+	result := &TrendingResult{Window: window, Movies: []TrendingMovie{}}
+	var entries []models.TrendingSnapshotEntry
+	if len(snapshots) == 0 {
+		computed, err := computeTrending(window, trendingSnapshotSize)
+		if err != nil {
+			return nil, err
+		}
+		entries = computed
+	} else {
+		result.SnapshotAt = &snapshots[0].CreatedAt
+		if err := config.DB.Where("snapshot_id = ?", snapshots[0].ID).Order("rank").Find(&entries).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	// Posiciones de la instantánea anterior
+	var previousRanks map[uint]int
+	if len(snapshots) == 2 {
+		result.PreviousSnapshotAt = &snapshots[1].CreatedAt
+		var previous []models.TrendingSnapshotEntry
+		if err := config.DB.Select("movie_id", "rank").Where("snapshot_id = ?", snapshots[1].ID).Find(&previous).Error; err != nil {
+			return nil, err
+		}
+		previousRanks = make(map[uint]int, len(previous))
+		for _, entry := range previous {
+			previousRanks[entry.MovieID] = entry.Rank
+		}
+	}
+
+	if len(entries) == 0 {
+		return result, nil
+	}
+
+	// Cargar las películas, sin las que están en la papelera o no son aptas para el usuario
+	ids := make([]uint, len(entries))
+	for i, entry := range entries {
+		ids[i] = entry.MovieID
+	}
+	var movies []models.Movie
+	if err := config.DB.Scopes(ContentFilter(restrictions)).Preload("Genres").Where("id IN ?", ids).Find(&movies).Error; err != nil {
+		return nil, err
+	}
+	moviesByID := make(map[uint]models.Movie, len(movies))
+	for _, movie := range movies {
+		moviesByID[movie.ID] = movie
+	}
+
+	for _, entry := range entries {
+		movie, ok := moviesByID[entry.MovieID]
+		if !ok {
+			continue
+		}
+
+		item := TrendingMovie{
+			Rank:     entry.Rank,
+			Movie:    movie,
+			Score:    entry.Score,
+			Likes:    entry.Likes,
+			Comments: entry.Comments,
+			Views:    entry.Views,
+		}
+		if previousRanks != nil {
+			if previousRank, ok := previousRanks[entry.MovieID]; ok {
+				movement := previousRank - entry.Rank
+				item.PreviousRank = &previousRank
+				item.Movement = &movement
+			} else {
+				item.New = true
+			}
+		}
+
+		result.Movies = append(result.Movies, item)
+		if len(result.Movies) == limit {
+			break
+		}
+	}
+
+	return result, nil
+}
+
+// CreateTrendingSnapshots calcula y guarda una instantánea de cada ventana y elimina las que superan
+// el tiempo de conservación
+func CreateTrendingSnapshots() (*TrendingSnapshotReport, error) {
+	report := &TrendingSnapshotReport{Snapshots: map[string]int{}}
+
+	for _, window := range models.TrendingWindows {
+		entries, err := computeTrending(window, trendingSnapshotSize)
+		if err != nil {
+			return report, fmt.Errorf("error al calcular las tendencias de %s: %w", window, err)
+		}
+
+		snapshot := models.TrendingSnapshot{Window: window, Entries: entries}
+		if err := config.DB.Create(&snapshot).Error; err != nil {
+			return report, fmt.Errorf("error al guardar la instantánea de %s: %w", window, err)
+		}
+		report.Snapshots[window] = len(entries)
+	}
+
+	// Las entradas de las instantáneas antiguas se eliminan con ellas
+	cutoff := time.Now().Add(-TrendingSnapshotRetention)
+	tx := config.DB.Begin()
+	if err := tx.Exec("DELETE FROM trending_snapshot_entries WHERE snapshot_id IN (SELECT id FROM trending_snapshots WHERE created_at < ?)", cutoff).Error; err != nil {
+		tx.Rollback()
+		return report, err
+	}
+	pruned := tx.Where("created_at < ?", cutoff).Delete(&models.TrendingSnapshot{})
+	if pruned.Error != nil {
+		tx.Rollback()
+		return report, pruned.Error
+	}
+	if err := tx.Commit().Error; err != nil {
+		return report, err
+	}
+	report.Pruned = pruned.RowsAffected
+
+	return report, nil
+}
+
+// computeTrending calcula las películas con más interacciones recientes de la ventana. Cada me gusta,
+// comentario y visita suma su peso reducido a la mitad por cada vida media transcurrida.
+func computeTrending(window string, limit int) ([]models.TrendingSnapshotEntry, error) {
+	settings := trendingWindows[window]
+	since := time.Now().Add(-settings.period)
+
+	var rows []struct {
+		MovieID  uint
+		Score    float64
+		Likes    int
+		Comments int
+		Views    int
+	}
+	err := config.DB.Raw(`WITH events AS (
+			SELECT movie_id, created_at AS at, 'like' AS kind, 1 AS amount
+				FROM movie_likes WHERE deleted_at IS NULL AND created_at >= @since
+			UNION ALL
+			SELECT movie_id, created_at, 'comment', 1 FROM comments WHERE created_at >= @since
+			UNION ALL
+			SELECT movie_id, hour, 'view', views FROM movie_view_stats WHERE hour >= @since
+		)
+		SELECT e.movie_id,
+			SUM(e.amount * CASE e.kind WHEN 'like' THEN CAST(@like AS double precision)
+					WHEN 'comment' THEN CAST(@comment AS double precision) ELSE CAST(@view AS double precision) END
+				* EXP(-LN(2) * GREATEST(EXTRACT(EPOCH FROM (NOW() - e.at))::double precision, 0) / CAST(@half_life AS double precision))) AS score,
+			SUM(CASE WHEN e.kind = 'like' THEN e.amount ELSE 0 END) AS likes,
+			SUM(CASE WHEN e.kind = 'comment' THEN e.amount ELSE 0 END) AS comments,
+			SUM(CASE WHEN e.kind = 'view' THEN e.amount ELSE 0 END) AS views
+		FROM events e
+		JOIN movies m ON m.id = e.movie_id AND m.deleted_at IS NULL
+		GROUP BY e.movie_id
+		ORDER BY score DESC, e.movie_id
+		LIMIT @limit`,
+		map[string]interface{}{
+			"since":     since,
+			"like":      trendingLikeWeight,
+			"comment":   trendingCommentWeight,
+			"view":      trendingViewWeight,
+			"half_life": settings.halfLife.Seconds(),
+			"limit":     limit,
+		}).Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]models.TrendingSnapshotEntry, len(rows))
+	for i, row := range rows {
+		entries[i] = models.TrendingSnapshotEntry{
+			MovieID:  row.MovieID,
+			Rank:     i + 1,
+			Score:    math.Round(row.Score*10000) / 10000,
+			Likes:    row.Likes,
+			Comments: row.Comments,
+			Views:    row.Views,
+		}
+	}
+	return entries, nil
+}
@@ -0,0 +1,218 @@
+package services
+
+import (
+	"cine_conecta_backend/config"
+	"cine_conecta_backend/movies/models"
+	"errors"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// WatchlistFilter define los filtros y el orden de la lista de pendientes
+type WatchlistFilter struct {
+	Genre    string // Nombre del género (coincidencia parcial)
+	Priority int    // Prioridad exacta (0 = todas)
+	Sort     string // position, priority, added, title o release_date
+	Order    string // asc o desc
+}
+
+// Columnas por las que se puede ordenar la lista de pendientes
+var watchlistSortColumns = map[string]string{
+	"position":     "watchlist_items.position",
+	"priority":     "watchlist_items.priority",
+	"added":        "watchlist_items.created_at",
+	"title":        "movies.title",
+	"release_date": "movies.release_date",
+}
+
+// GetWatchlist devuelve la lista de pendientes de un usuario con sus películas
+func GetWatchlist(userID uint, filter WatchlistFilter) ([]models.WatchlistItem, error) {
+	column, ok := watchlistSortColumns[filter.Sort]
+	if filter.Sort == "" {
+		column, ok = watchlistSortColumns["position"], true
+	}
+	if !ok {
+		return nil, errors.New("orden no válido (position, priority, added, title o release_date)")
+	}
+
+	direction := "ASC"
+	if strings.ToLower(filter.Order) == "desc" {
+		direction = "DESC"
+	} else if filter.Order == "" && filter.Sort == "priority" {
+		// Por defecto las de mayor prioridad primero
+		direction = "DESC"
+	}
+
+	query := config.DB.Model(&models.WatchlistItem{}).
+		Select("watchlist_items.*").
+		Joins("JOIN movies ON movies.id = watchlist_items.movie_id AND movies.deleted_at IS NULL").
+		Where("watchlist_items.user_id = ?", userID)
+
+	if filter.Priority != 0 {
+		query = query.Where("watchlist_items.priority = ?", filter.Priority)
+	}
+	if filter.Genre != "" {
+		query = query.Where("movies.genre ILIKE ?", "%"+filter.Genre+"%")
+	}
+
+	var items []models.WatchlistItem
+	err := query.
+		Preload("Movie.Genres").
+		Order(column + " " + direction).
+		Order("watchlist_items.id ASC").
+		Find(&items).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, item := range items {
+		if item.Movie != nil {
+			item.Movie.InWatchlist = true
+		}
+	}
+	return items, nil
+}
+
+// AddToWatchlist añade una película a la lista de pendientes del usuario al final de la lista
+func AddToWatchlist(userID, movieID uint, notes string, priority int) (*models.WatchlistItem, error) {
+	if err := config.DB.Select("id").First(&models.Movie{}, movieID).Error; err != nil {
+		return nil, errors.New("película no encontrada")
+	}
+
+	if priority == 0 {
+		priority = models.WatchlistPriorityMedium
+	}
+	if err := validateWatchlistPriority(priority); err != nil {
+		return nil, err
+	}
+
+	var existing int64
+	config.DB.Model(&models.WatchlistItem{}).Where("user_id = ? AND movie_id = ?", userID, movieID).Count(&existing)
+	if existing > 0 {
+		return nil, errors.New("la película ya está en tu lista de pendientes")
+	}
+
+	var maxPosition int
+	config.DB.Model(&models.WatchlistItem{}).
+		Where("user_id = ?", userID).
+		Select("COALESCE(MAX(position), 0)").
+		Scan(&maxPosition)
+
+	item := models.WatchlistItem{
+		UserID:   userID,
+		MovieID:  movieID,
+		Notes:    strings.TrimSpace(notes),
+		Priority: priority,
+		Position: maxPosition + 1,
+	}
+	if err := config.DB.Create(&item).Error; err != nil {
+		return nil, errors.New("error al añadir la película a la lista de pendientes")
+	}
+
+	return &item, nil
+}
+
+// UpdateWatchlistItem cambia las notas o la prioridad de una película de la lista
+func UpdateWatchlistItem(userID, movieID uint, notes *string, priority *int) (*models.WatchlistItem, error) {
+	var item models.WatchlistItem
+	if err := config.DB.Where("user_id = ? AND movie_id = ?", userID, movieID).First(&item).Error; err != nil {
+		return nil, err
+	}
+
+	if notes != nil {
+		item.Notes = strings.TrimSpace(*notes)
+	}
+	if priority != nil {
+		if err := validateWatchlistPriority(*priority); err != nil {
+			return nil, err
+		}
+		item.Priority = *priority
+	}
+
+	if err := config.DB.Save(&item).Error; err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+// RemoveFromWatchlist quita una película de la lista de pendientes
+func RemoveFromWatchlist(userID, movieID uint) error {
+	result := config.DB.Where("user_id = ? AND movie_id = ?", userID, movieID).Delete(&models.WatchlistItem{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// ReorderWatchlist asigna posiciones según el orden de los IDs de película recibidos.
+// Las películas no incluidas conservan su posición relativa detrás de las ordenadas.
+func ReorderWatchlist(userID uint, movieIDs []uint) error {
+	tx := config.DB.Begin()
+
+	for i, movieID := range movieIDs {
+		result := tx.Model(&models.WatchlistItem{}).
+			Where("user_id = ? AND movie_id = ?", userID, movieID).
+			Update("position", i+1)
+		if result.Error != nil {
+			tx.Rollback()
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			tx.Rollback()
+			return fmt.Errorf("la película %d no está en tu lista de pendientes", movieID)
+		}
+	}
+
+	if len(movieIDs) > 0 {
+		err := tx.Exec(`UPDATE watchlist_items SET position = ranked.new_position
+			FROM (
+				SELECT id, ? + ROW_NUMBER() OVER (ORDER BY position, id) AS new_position
+				FROM watchlist_items WHERE user_id = ? AND movie_id NOT IN ?
+			) AS ranked
+			WHERE watchlist_items.id = ranked.id`, len(movieIDs), userID, movieIDs).Error
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit().Error
+}
+
+// MarkWatchlisted marca las películas que están en la lista de pendientes del usuario
+func MarkWatchlisted(userID uint, movies []models.Movie) {
+	if userID == 0 || len(movies) == 0 {
+		return
+	}
+
+	ids := make([]uint, 0, len(movies))
+	for _, movie := range movies {
+		ids = append(ids, movie.ID)
+	}
+
+	var listed []uint
+	config.DB.Model(&models.WatchlistItem{}).
+		Where("user_id = ? AND movie_id IN ?", userID, ids).
+		Pluck("movie_id", &listed)
+
+	inList := make(map[uint]bool, len(listed))
+	for _, id := range listed {
+		inList[id] = true
+	}
+	for i := range movies {
+		movies[i].InWatchlist = inList[movies[i].ID]
+	}
+}
+
+// validateWatchlistPriority comprueba que la prioridad esté entre baja y alta
+func validateWatchlistPriority(priority int) error {
+	if priority < models.WatchlistPriorityLow || priority > models.WatchlistPriorityHigh {
+		return errors.New("prioridad no válida (1 = baja, 2 = media, 3 = alta)")
+	}
+	return nil
+}
@@ -0,0 +1,19 @@
+package movies
+
+import (
+	"cine_conecta_backend/movies/services"
+	"encoding/json"
+	"fmt"
+)
+
+// BuildSimilarities recalcula las similitudes entre películas del filtrado colaborativo y muestra el reporte
+func BuildSimilarities() error {
+	report, err := services.BuildItemSimilarities()
+	if err != nil {
+		return err
+	}
+
+	output, _ := json.MarshalIndent(report, "", "  ")
+	fmt.Println(string(output))
+	return nil
+}
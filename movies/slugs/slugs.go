@@ -0,0 +1,89 @@
+package slugs
+
+import (
+	"cine_conecta_backend/movies/models"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// Sync mantiene el slug de la película acorde a su título y año. Si cambia, el slug
+// anterior se guarda como redirección para que los enlaces antiguos sigan funcionando.
+func Sync(tx *gorm.DB, movie *models.Movie) error {
+	base := models.MovieSlugBase(movie.Title, movie.ReleaseDate)
+	if movie.Slug != "" && models.MatchesSlugBase(movie.Slug, base) {
+		return nil
+	}
+
+	slug, err := unique(tx, base, movie.ID)
+	if err != nil {
+		return err
+	}
+
+	if movie.Slug != "" {
+		err := tx.Exec(`INSERT INTO movie_slug_redirects (slug, movie_id, created_at) VALUES (?, ?, NOW())
+			ON CONFLICT (slug) DO UPDATE SET movie_id = EXCLUDED.movie_id`, movie.Slug, movie.ID).Error
+		if err != nil {
+			return err
+		}
+	}
+	// Si la película recupera un slug que ya tuvo, deja de ser una redirección
+	if err := tx.Where("slug = ?", slug).Delete(&models.MovieSlugRedirect{}).Error; err != nil {
+		return err
+	}
+
+	if err := tx.Model(&models.Movie{}).Unscoped().Where("id = ?", movie.ID).UpdateColumn("slug", slug).Error; err != nil {
+		return err
+	}
+	movie.Slug = slug
+	return nil
+}
+
+// Migrate asigna un slug a las películas que todavía no lo tienen, incluidas las de la papelera.
+// Devuelve la cantidad de películas actualizadas.
+func Migrate(db *gorm.DB) (int, error) {
+	var movies []models.Movie
+	if err := db.Unscoped().Where("slug = ''").Order("id ASC").Find(&movies).Error; err != nil {
+		return 0, err
+	}
+
+	migrated := 0
+	for i := range movies {
+		tx := db.Begin()
+		if err := Sync(tx, &movies[i]); err != nil {
+			tx.Rollback()
+			fmt.Printf("[DEBUG-SLUG] Error al generar el slug de la película %d: %v\n", movies[i].ID, err)
+			continue
+		}
+		if err := tx.Commit().Error; err != nil {
+			return migrated, err
+		}
+		migrated++
+	}
+
+	fmt.Printf("[DEBUG-SLUG] Slugs generados para %d películas\n", migrated)
+	return migrated, nil
+}
+
+// unique devuelve la base o la base con el primer sufijo numérico libre. Un slug está ocupado
+// si lo usa otra película (aunque esté en la papelera) o redirige a otra película.
+func unique(tx *gorm.DB, base string, movieID uint) (string, error) {
+	for n := 1; ; n++ {
+		slug := base
+		if n > 1 {
+			slug = fmt.Sprintf("%s-%d", base, n)
+		}
+
+		var taken int64
+		err := tx.Raw(`SELECT (SELECT COUNT(*) FROM movies WHERE slug = ? AND id <> ?)
+			+ (SELECT COUNT(*) FROM movie_slug_redirects WHERE slug = ? AND movie_id <> ?)`,
+			slug, movieID, slug, movieID).
+			Scan(&taken).Error
+		if err != nil {
+			return "", err
+		}
+		if taken == 0 {
+			return slug, nil
+		}
+	}
+}
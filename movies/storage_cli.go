@@ -0,0 +1,24 @@
+package movies
+
+import (
+	"cine_conecta_backend/movies/services"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// SweepStorage elimina los archivos huérfanos del almacenamiento y muestra el reporte
+func SweepStorage(minAge time.Duration, dryRun bool) error {
+	report, err := services.SweepOrphanObjects(minAge, dryRun)
+	if err != nil {
+		return err
+	}
+
+	output, _ := json.MarshalIndent(report, "", "  ")
+	fmt.Println(string(output))
+
+	if len(report.Errors) > 0 {
+		return fmt.Errorf("%d archivos no se pudieron eliminar", len(report.Errors))
+	}
+	return nil
+}
@@ -0,0 +1,24 @@
+package movies
+
+import (
+	"cine_conecta_backend/movies/services"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// PurgeTrash elimina definitivamente las películas antiguas de la papelera y muestra el reporte
+func PurgeTrash(olderThan time.Duration, dryRun bool) error {
+	report, err := services.PurgeExpiredTrash(olderThan, dryRun)
+	if err != nil {
+		return err
+	}
+
+	output, _ := json.MarshalIndent(report, "", "  ")
+	fmt.Println(string(output))
+
+	if len(report.Errors) > 0 {
+		return fmt.Errorf("%d películas no se pudieron purgar", len(report.Errors))
+	}
+	return nil
+}
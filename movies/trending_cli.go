@@ -0,0 +1,19 @@
+package movies
+
+import (
+	"cine_conecta_backend/movies/services"
+	"encoding/json"
+	"fmt"
+)
+
+// SnapshotTrending guarda una instantánea de las tendencias de cada ventana y muestra el reporte
+func SnapshotTrending() error {
+	report, err := services.CreateTrendingSnapshots()
+	if err != nil {
+		return err
+	}
+
+	output, _ := json.MarshalIndent(report, "", "  ")
+	fmt.Println(string(output))
+	return nil
+}
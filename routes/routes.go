@@ -2,8 +2,11 @@ package routes
 
 import (
 	routesAuth "cine_conecta_backend/auth/routes"
+	routesCinemas "cine_conecta_backend/cinemas/routes"
 	routesComment "cine_conecta_backend/comments/routes"
+	routesLists "cine_conecta_backend/lists/routes"
 	routesMovies "cine_conecta_backend/movies/routes"
+	"cine_conecta_backend/storage"
 
 	"github.com/gin-gonic/gin"
 )
@@ -12,4 +15,9 @@ func RegisterRoutes(r *gin.Engine) {
 	routesMovies.RegisterMovieRoutes(r)
 	routesAuth.RegisterAuthRoutes(r)
 	routesComment.RegisterCommentRoutes(r)
+	routesCinemas.RegisterCinemaRoutes(r)
+	routesLists.RegisterListRoutes(r)
+
+	// Archivos del almacenamiento local (solo en desarrollo)
+	storage.RegisterLocalRoutes(r)
 }
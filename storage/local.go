@@ -0,0 +1,281 @@
+package storage
+
+import (
+	"cine_conecta_backend/auth/utils"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultLocalDir     = "uploads"
+	defaultLocalBaseURL = "http://localhost:8080/uploads"
+)
+
+// LocalStorage guarda los archivos en disco; pensado para desarrollo
+type LocalStorage struct {
+	dir     string
+	baseURL string
+	secret  []byte
+}
+
+// NewLocalStorageFromEnv crea el backend local usando LOCAL_STORAGE_DIR y LOCAL_STORAGE_URL
+func NewLocalStorageFromEnv() *LocalStorage {
+	dir := strings.TrimSpace(os.Getenv("LOCAL_STORAGE_DIR"))
+	if dir == "" {
+		dir = defaultLocalDir
+	}
+	baseURL := strings.TrimRight(strings.TrimSpace(os.Getenv("LOCAL_STORAGE_URL")), "/")
+	if baseURL == "" {
+		baseURL = defaultLocalBaseURL
+	}
+
+	// Las subidas firmadas usan su propio secreto o, en su defecto, el de JWT
+	secret := os.Getenv("LOCAL_STORAGE_SECRET")
+	if secret == "" {
+		secret = os.Getenv("JWT_SECRET")
+	}
+
+	fmt.Printf("📦 [DEBUG] Almacenamiento local en %s (%s)\n", dir, baseURL)
+
+	return &LocalStorage{dir: dir, baseURL: baseURL, secret: []byte(secret)}
+}
+
+// Name devuelve el nombre del backend
+func (l *LocalStorage) Name() string {
+	return "local"
+}
+
+// Put escribe el archivo en disco y devuelve su URL pública
+func (l *LocalStorage) Put(ctx context.Context, key string, body io.Reader, contentType string) (string, error) {
+	fullPath, err := l.path(key)
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(fullPath), 0o755); err != nil {
+		return "", fmt.Errorf("error al crear directorio: %w", err)
+	}
+
+	// Escribir en un temporal y renombrar para no dejar archivos a medias
+	tmp, err := os.CreateTemp(filepath.Dir(fullPath), ".upload-*")
+	if err != nil {
+		return "", fmt.Errorf("error al crear archivo: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, body); err != nil {
+		tmp.Close()
+		return "", fmt.Errorf("error al escribir archivo: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return "", fmt.Errorf("error al escribir archivo: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), fullPath); err != nil {
+		return "", fmt.Errorf("error al guardar archivo: %w", err)
+	}
+
+	return l.URL(key), nil
+}
+
+// Open abre el archivo del disco
+func (l *LocalStorage) Open(ctx context.Context, key string) (io.ReadCloser, *ObjectInfo, error) {
+	fullPath, err := l.path(key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	file, err := os.Open(fullPath)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil, ErrObjectNotFound
+		}
+		return nil, nil, fmt.Errorf("error al leer archivo %s: %w", key, err)
+	}
+	stat, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, nil, fmt.Errorf("error al leer archivo %s: %w", key, err)
+	}
+
+	info := &ObjectInfo{
+		Key:          key,
+		Size:         stat.Size(),
+		ContentType:  mime.TypeByExtension(filepath.Ext(fullPath)),
+		LastModified: stat.ModTime(),
+	}
+	return file, info, nil
+}
+
+// Delete elimina el archivo del disco
+func (l *LocalStorage) Delete(ctx context.Context, key string) error {
+	fullPath, err := l.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(fullPath); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("error al eliminar archivo %s: %w", key, err)
+	}
+	return nil
+}
+
+// URL devuelve la URL pública de una clave
+func (l *LocalStorage) URL(key string) string {
+	return l.baseURL + "/" + key
+}
+
+// KeyFromURL obtiene la clave si la URL pertenece al almacenamiento local
+func (l *LocalStorage) KeyFromURL(rawURL string) (string, bool) {
+	prefix := l.baseURL + "/"
+	if !strings.HasPrefix(rawURL, prefix) || len(rawURL) == len(prefix) {
+		return "", false
+	}
+	return strings.TrimPrefix(rawURL, prefix), true
+}
+
+// Presign genera una URL de subida PUT firmada con HMAC que valida el propio servidor
+func (l *LocalStorage) Presign(ctx context.Context, key, contentType string, maxSize int64, expires time.Duration) (*PresignedUpload, error) {
+	if len(l.secret) == 0 {
+		return nil, errors.New("falta LOCAL_STORAGE_SECRET o JWT_SECRET para firmar subidas")
+	}
+	if _, err := l.path(key); err != nil {
+		return nil, err
+	}
+
+	expiresAt := time.Now().Add(expires)
+	params := url.Values{
+		"expires":   {strconv.FormatInt(expiresAt.Unix(), 10)},
+		"max_size":  {strconv.FormatInt(maxSize, 10)},
+		"signature": {l.sign(key, contentType, maxSize, expiresAt.Unix())},
+	}
+
+	return &PresignedUpload{
+		Method:    http.MethodPut,
+		URL:       l.URL(key) + "?" + params.Encode(),
+		Headers:   map[string]string{"Content-Type": contentType},
+		Key:       key,
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+// List recorre el directorio y devuelve los archivos con el prefijo indicado
+func (l *LocalStorage) List(ctx context.Context, prefix string) ([]ObjectInfo, error) {
+	var objects []ObjectInfo
+
+	err := filepath.WalkDir(l.dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() || strings.HasPrefix(d.Name(), ".upload-") {
+			return nil
+		}
+
+		rel, err := filepath.Rel(l.dir, p)
+		if err != nil {
+			return err
+		}
+		key := filepath.ToSlash(rel)
+		if !strings.HasPrefix(key, prefix) {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		objects = append(objects, ObjectInfo{Key: key, Size: info.Size(), LastModified: info.ModTime()})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error al listar archivos: %w", err)
+	}
+
+	return objects, nil
+}
+
+// path convierte la clave en una ruta del disco, rechazando rutas fuera del directorio
+func (l *LocalStorage) path(key string) (string, error) {
+	clean := path.Clean("/" + key)
+	if clean == "/" || clean != "/"+key {
+		return "", fmt.Errorf("clave de archivo inválida: %s", key)
+	}
+	return filepath.Join(l.dir, filepath.FromSlash(clean[1:])), nil
+}
+
+// sign calcula la firma HMAC de una subida
+func (l *LocalStorage) sign(key, contentType string, maxSize, expires int64) string {
+	mac := hmac.New(sha256.New, l.secret)
+	fmt.Fprintf(mac, "%s\n%s\n%d\n%d", key, contentType, maxSize, expires)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// RegisterLocalRoutes sirve los archivos locales y acepta las subidas firmadas.
+// No hace nada si el backend activo no es el almacenamiento local.
+func RegisterLocalRoutes(r *gin.Engine) {
+	local, ok := current.(*LocalStorage)
+	if !ok {
+		return
+	}
+
+	mount := "/uploads"
+	if parsed, err := url.Parse(local.baseURL); err == nil && parsed.Path != "" {
+		mount = parsed.Path
+	}
+
+	r.Static(mount, local.dir)
+	r.PUT(mount+"/*filepath", local.handleSignedUpload)
+}
+
+// handleSignedUpload guarda un archivo subido con una URL generada por Presign
+func (l *LocalStorage) handleSignedUpload(c *gin.Context) {
+	key := strings.TrimPrefix(c.Param("filepath"), "/")
+	contentType := c.GetHeader("Content-Type")
+
+	expires, errExp := strconv.ParseInt(c.Query("expires"), 10, 64)
+	maxSize, errSize := strconv.ParseInt(c.Query("max_size"), 10, 64)
+	if errExp != nil || errSize != nil || len(l.secret) == 0 {
+		utils.ErrorResponse(c, http.StatusForbidden, "Firma inválida")
+		return
+	}
+
+	expected := l.sign(key, contentType, maxSize, expires)
+	if !hmac.Equal([]byte(expected), []byte(c.Query("signature"))) {
+		utils.ErrorResponse(c, http.StatusForbidden, "Firma inválida")
+		return
+	}
+	if time.Now().Unix() > expires {
+		utils.ErrorResponse(c, http.StatusForbidden, "La URL de subida ha expirado")
+		return
+	}
+	if c.Request.ContentLength > maxSize {
+		utils.ErrorResponse(c, http.StatusRequestEntityTooLarge, "El archivo supera el tamaño permitido")
+		return
+	}
+
+	body := http.MaxBytesReader(c.Writer, c.Request.Body, maxSize)
+	if _, err := l.Put(c.Request.Context(), key, body, contentType); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
@@ -3,10 +3,11 @@ package storage
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"log"
-	"mime/multipart"
+	"io"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 
@@ -17,19 +18,20 @@ import (
 	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/aws/aws-sdk-go-v2/service/s3/types"
-	"github.com/joho/godotenv"
 )
 
-var (
+// S3Storage guarda los archivos en un bucket compatible con S3 (AWS o Supabase)
+type S3Storage struct {
 	bucket     string
 	baseURL    string
+	client     *s3.Client
 	uploader   *manager.Uploader
+	presigner  *s3.PresignClient
 	isSupabase bool
-)
-
-func init() {
-	_ = godotenv.Load()
+}
 
+// NewS3StorageFromEnv crea el backend S3 a partir de las variables S3_*
+func NewS3StorageFromEnv() (*S3Storage, error) {
 	endpointURL := strings.TrimSpace(os.Getenv("S3_ENDPOINT"))
 	region := strings.TrimSpace(os.Getenv("S3_REGION"))
 	bucketEnv := strings.TrimSpace(os.Getenv("S3_BUCKET"))
@@ -43,25 +45,34 @@ func init() {
 
 	if endpointURL == "" || region == "" || bucketEnv == "" ||
 		accessEnv == "" || secretEnv == "" {
-		log.Println("⚠️  Variables S3 incompletas; Storage deshabilitado")
-		return
+		return nil, fmt.Errorf("variables S3 incompletas")
 	}
 
-	bucket = bucketEnv
-	isSupabase = strings.Contains(endpointURL, "supabase")
+	storage := &S3Storage{
+		bucket:     bucketEnv,
+		isSupabase: strings.Contains(endpointURL, "supabase"),
+	}
 
-	if isSupabase {
+	if storage.isSupabase {
 		// URL pública para Supabase
 		baseEndpoint := strings.Replace(endpointURL, "/storage/v1/s3", "", 1)
-		baseURL = fmt.Sprintf("%s/storage/v1/object/public/%s", baseEndpoint, bucket)
+		storage.baseURL = fmt.Sprintf("%s/storage/v1/object/public/%s", baseEndpoint, storage.bucket)
 	} else {
-		baseURL = fmt.Sprintf("%s/%s", endpointURL, bucket)
+		storage.baseURL = fmt.Sprintf("%s/%s", endpointURL, storage.bucket)
 	}
 
 	// ---------- cliente S3 ----------
-	var s3Client *s3.Client
+	cfg, err := config.LoadDefaultConfig(
+		context.TODO(),
+		config.WithRegion(region),
+		config.WithCredentialsProvider(
+			credentials.NewStaticCredentialsProvider(accessEnv, secretEnv, "")),
+	)
+	if err != nil {
+		panic(fmt.Sprintf("❌ cfg S3: %v", err))
+	}
 
-	if isSupabase {
+	if storage.isSupabase {
 		resolver := s3.EndpointResolverFunc(
 			func(region string, _ s3.EndpointResolverOptions) (aws.Endpoint, error) {
 				return aws.Endpoint{
@@ -72,17 +83,7 @@ func init() {
 				}, nil
 			})
 
-		cfg, err := config.LoadDefaultConfig(
-			context.TODO(),
-			config.WithRegion(region),
-			config.WithCredentialsProvider(
-				credentials.NewStaticCredentialsProvider(accessEnv, secretEnv, "")),
-		)
-		if err != nil {
-			panic(fmt.Sprintf("❌ cfg S3: %v", err))
-		}
-
-		s3Client = s3.NewFromConfig(
+		storage.client = s3.NewFromConfig(
 			cfg,
 			s3.WithEndpointResolver(resolver),
 			func(o *s3.Options) {
@@ -92,50 +93,158 @@ func init() {
 					v4.SwapComputePayloadSHA256ForUnsignedPayloadMiddleware)
 			})
 	} else {
-		cfg, err := config.LoadDefaultConfig(
-			context.TODO(),
-			config.WithRegion(region),
-			config.WithCredentialsProvider(
-				credentials.NewStaticCredentialsProvider(accessEnv, secretEnv, "")),
-		)
-		if err != nil {
-			panic(fmt.Sprintf("❌ cfg S3: %v", err))
-		}
-		s3Client = s3.NewFromConfig(cfg, func(o *s3.Options) { o.UsePathStyle = true })
+		storage.client = s3.NewFromConfig(cfg, func(o *s3.Options) { o.UsePathStyle = true })
 	}
 
 	// ---------- uploader ----------
-	uploader = manager.NewUploader(s3Client, func(u *manager.Uploader) {
+	storage.uploader = manager.NewUploader(storage.client, func(u *manager.Uploader) {
 		u.Concurrency = 1
 		u.PartSize = 5 * 1024 * 1024 // 5 MB
 		u.LeavePartsOnError = false  // 👈 evita la cabecera Content-MD5
 	})
+	storage.presigner = s3.NewPresignClient(storage.client)
+
+	return storage, nil
+}
+
+// Name devuelve el nombre del backend
+func (s *S3Storage) Name() string {
+	return "s3"
+}
+
+// Put sube el contenido indicado con la clave dada y devuelve la URL pública.
+func (s *S3Storage) Put(ctx context.Context, key string, body io.Reader, contentType string) (string, error) {
+	input := &s3.PutObjectInput{
+		Bucket:      aws.String(s.bucket),
+		Key:         aws.String(key),
+		Body:        body,
+		ContentType: aws.String(contentType),
+	}
+	if !s.isSupabase {
+		input.ACL = types.ObjectCannedACLPublicRead
+	}
+
+	if _, err := s.uploader.Upload(ctx, input); err != nil {
+		return "", fmt.Errorf("error al subir archivo: %w", err)
+	}
+
+	return s.URL(key), nil
+}
+
+// Open descarga el objeto del bucket
+func (s *S3Storage) Open(ctx context.Context, key string) (io.ReadCloser, *ObjectInfo, error) {
+	output, err := s.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		var notFound *types.NoSuchKey
+		if errors.As(err, &notFound) {
+			return nil, nil, ErrObjectNotFound
+		}
+		return nil, nil, fmt.Errorf("error al leer archivo %s: %w", key, err)
+	}
+
+	info := &ObjectInfo{
+		Key:         key,
+		Size:        aws.ToInt64(output.ContentLength),
+		ContentType: aws.ToString(output.ContentType),
+	}
+	if output.LastModified != nil {
+		info.LastModified = *output.LastModified
+	}
+	return output.Body, info, nil
 }
 
-// UploadPoster sube el archivo y devuelve la URL pública.
-func UploadPoster(key string, file multipart.File, mime string) (string, error) {
-	if uploader == nil {
-		return "", fmt.Errorf("almacenamiento no configurado")
+// Delete elimina el objeto del bucket
+func (s *S3Storage) Delete(ctx context.Context, key string) error {
+	_, err := s.client.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return fmt.Errorf("error al eliminar archivo %s: %w", key, err)
 	}
+	return nil
+}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
+// URL devuelve la URL pública de una clave
+func (s *S3Storage) URL(key string) string {
+	return fmt.Sprintf("%s/%s", s.baseURL, key)
+}
+
+// KeyFromURL obtiene la clave si la URL pertenece al bucket
+func (s *S3Storage) KeyFromURL(url string) (string, bool) {
+	prefix := s.baseURL + "/"
+	if !strings.HasPrefix(url, prefix) || len(url) == len(prefix) {
+		return "", false
+	}
+	return strings.TrimPrefix(url, prefix), true
+}
 
+// Presign genera una subida directa. En S3 se usa un formulario POST cuya política limita
+// tipo y tamaño; Supabase no admite políticas POST, así que se firma un PUT con el tipo de contenido
+// y con maxSize como longitud exacta del cuerpo.
+func (s *S3Storage) Presign(ctx context.Context, key, contentType string, maxSize int64, expires time.Duration) (*PresignedUpload, error) {
 	input := &s3.PutObjectInput{
-		Bucket:      aws.String(bucket),
+		Bucket:      aws.String(s.bucket),
 		Key:         aws.String(key),
-		Body:        file,
-		ContentType: aws.String(mime),
+		ContentType: aws.String(contentType),
 	}
-	if !isSupabase {
-		input.ACL = types.ObjectCannedACLPublicRead
+	expiresAt := time.Now().Add(expires)
+
+	if s.isSupabase {
+		input.ContentLength = aws.Int64(maxSize)
+		req, err := s.presigner.PresignPutObject(ctx, input, s3.WithPresignExpires(expires))
+		if err != nil {
+			return nil, fmt.Errorf("error al firmar subida: %w", err)
+		}
+		headers := map[string]string{"Content-Type": contentType, "Content-Length": strconv.FormatInt(maxSize, 10)}
+		return &PresignedUpload{Method: req.Method, URL: req.URL, Headers: headers, Key: key, ExpiresAt: expiresAt}, nil
 	}
 
-	if _, err := uploader.Upload(ctx, input); err != nil {
-		return "", fmt.Errorf("error al subir póster: %w", err)
+	input.ACL = types.ObjectCannedACLPublicRead
+	req, err := s.presigner.PresignPostObject(ctx, input, func(o *s3.PresignPostOptions) {
+		o.Expires = expires
+		o.Conditions = []interface{}{
+			[]interface{}{"content-length-range", 1, maxSize},
+			map[string]string{"Content-Type": contentType},
+			map[string]string{"acl": string(types.ObjectCannedACLPublicRead)},
+		}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error al firmar subida: %w", err)
+	}
+
+	fields := req.Values
+	fields["Content-Type"] = contentType
+	fields["acl"] = string(types.ObjectCannedACLPublicRead)
+	return &PresignedUpload{Method: "POST", URL: req.URL, Fields: fields, Key: key, ExpiresAt: expiresAt}, nil
+}
+
+// List devuelve los objetos del bucket con el prefijo indicado
+func (s *S3Storage) List(ctx context.Context, prefix string) ([]ObjectInfo, error) {
+	var objects []ObjectInfo
+
+	paginator := s3.NewListObjectsV2Paginator(s.client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(s.bucket),
+		Prefix: aws.String(prefix),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error al listar archivos: %w", err)
+		}
+		for _, obj := range page.Contents {
+			info := ObjectInfo{Key: aws.ToString(obj.Key), Size: aws.ToInt64(obj.Size)}
+			if obj.LastModified != nil {
+				info.LastModified = *obj.LastModified
+			}
+			objects = append(objects, info)
+		}
 	}
 
-	return fmt.Sprintf("%s/%s", baseURL, key), nil
+	return objects, nil
 }
 
 // maskSecret ofusca la clave al imprimir
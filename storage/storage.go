@@ -0,0 +1,161 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+)
+
+// ErrObjectNotFound indica que el objeto solicitado no existe
+var ErrObjectNotFound = errors.New("archivo no encontrado en el almacenamiento")
+
+// ErrNotConfigured indica que no hay ningún backend de almacenamiento disponible
+var ErrNotConfigured = errors.New("almacenamiento no configurado")
+
+// Tiempo máximo de las operaciones de almacenamiento
+const operationTimeout = 30 * time.Second
+
+// Velocidad mínima de subida supuesta al calcular el tiempo máximo de los archivos grandes
+const minUploadBytesPerSecond = 512 << 10 // 512 KB/s
+
+// ObjectInfo describe un objeto almacenado
+type ObjectInfo struct {
+	Key          string    `json:"key"`
+	Size         int64     `json:"size"`
+	ContentType  string    `json:"content_type,omitempty"`
+	LastModified time.Time `json:"last_modified"`
+}
+
+// PresignedUpload contiene los datos para que el cliente suba un archivo directamente al almacenamiento
+type PresignedUpload struct {
+	Method    string            `json:"method"` // PUT o POST
+	URL       string            `json:"url"`
+	Headers   map[string]string `json:"headers,omitempty"`
+	Fields    map[string]string `json:"fields,omitempty"` // Campos del formulario (solo POST)
+	Key       string            `json:"key"`
+	ExpiresAt time.Time         `json:"expires_at"`
+}
+
+// Storage es la interfaz que deben implementar los backends de almacenamiento de archivos
+type Storage interface {
+	// Name devuelve el nombre del backend (por ejemplo "s3" o "local")
+	Name() string
+	// Put guarda el contenido con la clave indicada y devuelve su URL pública
+	Put(ctx context.Context, key string, body io.Reader, contentType string) (string, error)
+	// Open abre un objeto para leerlo junto con su información
+	Open(ctx context.Context, key string) (io.ReadCloser, *ObjectInfo, error)
+	// Delete elimina el objeto; no devuelve error si no existe
+	Delete(ctx context.Context, key string) error
+	// URL devuelve la URL pública de una clave
+	URL(key string) string
+	// KeyFromURL obtiene la clave a partir de una URL pública de este backend
+	KeyFromURL(url string) (string, bool)
+	// Presign genera los datos para subir un archivo directamente, limitado en tipo y tamaño
+	Presign(ctx context.Context, key, contentType string, maxSize int64, expires time.Duration) (*PresignedUpload, error)
+	// List devuelve los objetos cuya clave empieza por el prefijo indicado
+	List(ctx context.Context, prefix string) ([]ObjectInfo, error)
+}
+
+var current Storage
+
+func init() {
+	current = newStorageFromEnv()
+}
+
+// newStorageFromEnv crea el backend configurado en STORAGE_DRIVER.
+// Si no se indica, se usa S3 cuando sus variables están completas y el disco local
+// en desarrollo (fuera de Vercel) en caso contrario.
+func newStorageFromEnv() Storage {
+	_ = godotenv.Load()
+
+	driver := strings.ToLower(strings.TrimSpace(os.Getenv("STORAGE_DRIVER")))
+
+	if driver == "" || driver == "s3" {
+		s3Storage, err := NewS3StorageFromEnv()
+		if err == nil {
+			return s3Storage
+		}
+		if driver == "s3" || os.Getenv("VERCEL") != "" {
+			log.Printf("⚠️  %v; Storage deshabilitado", err)
+			return nil
+		}
+		log.Printf("⚠️  %v; usando almacenamiento local", err)
+	}
+
+	if driver != "" && driver != "s3" && driver != "local" {
+		log.Printf("⚠️  STORAGE_DRIVER desconocido: %s; Storage deshabilitado", driver)
+		return nil
+	}
+
+	return NewLocalStorageFromEnv()
+}
+
+// Current devuelve el backend de almacenamiento activo (nil si está deshabilitado)
+func Current() Storage {
+	return current
+}
+
+// Put guarda el contenido en el backend activo y devuelve la URL pública
+func Put(key string, body io.Reader, contentType string) (string, error) {
+	if current == nil {
+		return "", ErrNotConfigured
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), operationTimeout)
+	defer cancel()
+
+	return current.Put(ctx, key, body, contentType)
+}
+
+// PutLarge guarda un archivo grande de tamaño conocido. El tiempo máximo crece con el tamaño
+// para que los vídeos no se corten a los 30 segundos con conexiones lentas.
+func PutLarge(key string, body io.Reader, contentType string, size int64) (string, error) {
+	if current == nil {
+		return "", ErrNotConfigured
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), uploadTimeout(size))
+	defer cancel()
+
+	return current.Put(ctx, key, body, contentType)
+}
+
+// uploadTimeout devuelve el tiempo máximo de una subida según su tamaño
+func uploadTimeout(size int64) time.Duration {
+	if size <= 0 {
+		return operationTimeout
+	}
+	return operationTimeout + time.Duration(size/minUploadBytesPerSecond)*time.Second
+}
+
+// Delete elimina un objeto del backend activo
+func Delete(key string) error {
+	if current == nil {
+		return ErrNotConfigured
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), operationTimeout)
+	defer cancel()
+
+	return current.Delete(ctx, key)
+}
+
+// DeleteURL elimina el objeto de una URL pública si pertenece al backend activo.
+// Las URLs externas (por ejemplo pósters de TMDB) se ignoran.
+func DeleteURL(url string) error {
+	if current == nil || url == "" {
+		return nil
+	}
+
+	key, ok := current.KeyFromURL(url)
+	if !ok {
+		return nil
+	}
+	return Delete(key)
+}